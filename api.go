@@ -1,5 +1,7 @@
 package svm
 
+import "context"
+
 // Classifier - интерфейс для классификатора.
 type Classifier interface {
 	// Fit обучает модель на обучающей выборке.
@@ -11,3 +13,73 @@ type Classifier interface {
 	// Clone возвращает копию текущего классификатора.
 	Clone() (Classifier, error)
 }
+
+// ContextClassifier - интерфейс для классификатора, обучение которого можно прервать через контекст.
+type ContextClassifier interface {
+	Classifier
+
+	// FitContext обучает модель на обучающей выборке.
+	// Если контекст отменен или истек его срок, то обучение прерывается и возвращается ошибка контекста.
+	FitContext(ctx context.Context, x [][]float64, y []int) error
+}
+
+// Scorer - интерфейс для классификатора, который умеет вычислять значения решающей функции.
+type Scorer interface {
+	Classifier
+
+	// DecisionFunction возвращает значения решающей функции для входных данных.
+	// Каждая строка результата соответствует одному объекту.
+	DecisionFunction(x [][]float64) [][]float64
+}
+
+// ProbabilisticClassifier - интерфейс для классификатора, который умеет оценивать вероятности классов.
+type ProbabilisticClassifier interface {
+	Classifier
+
+	// PredictProba возвращает вероятности принадлежности входных данных к каждому из классов.
+	// Каждая строка результата соответствует одному объекту, сумма вероятностей в строке равна 1.
+	PredictProba(x [][]float64) [][]float64
+}
+
+// PairwiseClassifier - интерфейс для классификатора, который может работать с предвычисленным ядром.
+// Если Pairwise возвращает true, то вместо матрицы признаков Fit принимает матрицу Грама обучающей выборки,
+// а Predict - матрицу значений ядра между новыми объектами и объектами обучающей выборки.
+type PairwiseClassifier interface {
+	Classifier
+
+	// Pairwise сообщает, что классификатор работает с предвычисленным ядром.
+	Pairwise() bool
+}
+
+// Regressor - интерфейс для регрессора.
+type Regressor interface {
+	// Fit обучает модель на обучающей выборке.
+	Fit(x [][]float64, y []float64) error
+
+	// Predict предсказывает значения целевой переменной для входных данных на основе обученной модели.
+	Predict(x [][]float64) []float64
+
+	// Clone возвращает копию текущего регрессора.
+	Clone() (Regressor, error)
+}
+
+// Transformer - интерфейс для преобразования признаков.
+type Transformer interface {
+	// Fit обучает преобразование на обучающей выборке.
+	Fit(x [][]float64) error
+
+	// Transform преобразует входные данные на основе обученного преобразования.
+	Transform(x [][]float64) [][]float64
+
+	// Clone возвращает копию текущего преобразования.
+	Clone() (Transformer, error)
+}
+
+// Logger - интерфейс для структурированного логгера в стиле log/slog: сообщение сопровождается
+// чередующимися ключами и значениями. *slog.Logger удовлетворяет этому интерфейсу.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
@@ -1,24 +1,49 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/xuri/excelize/v2"
 	"github.com/ziyadovea/svm"
 	"github.com/ziyadovea/svm/pkg/classification_metrics"
-	"github.com/ziyadovea/svm/pkg/classification_metrics/multiclass_metrics"
 	"github.com/ziyadovea/svm/pkg/cross_validation"
 	"github.com/ziyadovea/svm/pkg/vector_operations"
 	"github.com/ziyadovea/svm/svc"
 )
 
 func main() {
+	kernelNames := make([]string, 0)
+	for _, name := range svc.KernelNames() {
+		kernelNames = append(kernelNames, string(name))
+	}
+	kernelName := flag.String("kernel", "",
+		fmt.Sprintf("kernel name for a single test (%s); by default the predefined tests are run",
+			strings.Join(kernelNames, ", ")))
+	c := flag.Float64("c", 1.0, "regularization parameter C")
+	gamma := flag.String("gamma", "1.0", "kernel parameter gamma: a number, scale (1/(nFeatures*Var(X))) or auto (1/nFeatures)")
+	degree := flag.Int("degree", 3, "degree of the poly kernel")
+	coef0 := flag.Float64("coef0", 0.0, "free term of the poly and sigmoid kernels")
+	alpha := flag.Float64("alpha", 1.0, "alpha of the rational_quadratic kernel")
+	format := flag.String("format", string(classification_metrics.TextFormat),
+		"report format: text, markdown, json or csv (csv contains only the test set metrics)")
+	flag.Parse()
+
+	ext, ok := reportExtensions[classification_metrics.ReportFormat(*format)]
+	if !ok {
+		log.Fatalf("unknown report format: %s", *format)
+	}
+
 	currDur, err := os.Getwd()
 	if err != nil {
 		log.Fatal(err)
@@ -26,7 +51,7 @@ func main() {
 	pathToTrainDataTmpl := filepath.Join(currDur, "datasets", "%d_train_data.xlsx")
 	pathToTestDataTmpl := filepath.Join(currDur, "datasets", "%d_test_data.xlsx")
 
-	reportFile, err := os.Create("report.txt")
+	reportFile, err := os.Create("report." + ext)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -35,10 +60,9 @@ func main() {
 			log.Fatal(err)
 		}
 	}()
+	reports := newReportWriter(reportFile, classification_metrics.ReportFormat(*format))
 
 	for _, wellID := range []int{25, 336, 2697} {
-		reportFile.WriteString(fmt.Sprintf("REPORT FOR WELL %d\n\n", wellID))
-
 		pathToTrainData := fmt.Sprintf(pathToTrainDataTmpl, wellID)
 		pathToTestData := fmt.Sprintf(pathToTestDataTmpl, wellID)
 
@@ -52,12 +76,37 @@ func main() {
 			log.Fatal(err)
 		}
 
+		// Если ядро задано в командной строке - запускаем единственный тест с ним.
+		if *kernelName != "" {
+			cls := svc.NewMultiSVC()
+			cls.C = *c
+			switch svc.GammaMode(*gamma) {
+			case svc.GammaScale, svc.GammaAuto:
+				cls.GammaMode = svc.GammaMode(*gamma)
+			default:
+				if cls.Gamma, err = strconv.ParseFloat(*gamma, 64); err != nil {
+					log.Fatalf("invalid gamma: %v", err)
+				}
+			}
+			cls.Degree = *degree
+			cls.Coef0 = *coef0
+			cls.KernelAlpha = *alpha
+			if err = cls.SetKernelByName(*kernelName); err != nil {
+				log.Fatal(err)
+			}
+			name := fmt.Sprintf("%s C=%g gamma=%s", *kernelName, *c, *gamma)
+			if err = runTest(reports, wellID, name, cls, xTrain, xTest, yTrain, yTest); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+
 		// test 1
 		cls := svc.NewMultiSVC()
 		if err = cls.SetKernelByName("linear"); err != nil {
 			log.Fatal(err)
 		}
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "linear", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -66,7 +115,7 @@ func main() {
 		if err = cls.SetKernelByName("poly"); err != nil {
 			log.Fatal(err)
 		}
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "poly", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -77,7 +126,7 @@ func main() {
 		}
 		cls.C = 0.1
 		cls.Degree = 5
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "poly C=0.1 degree=5", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -88,7 +137,7 @@ func main() {
 		}
 		cls.C = 10
 		cls.Degree = 5
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "poly C=10 degree=5", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -97,7 +146,7 @@ func main() {
 		if err = cls.SetKernelByName("rbf"); err != nil {
 			log.Fatal(err)
 		}
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "rbf", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -108,7 +157,7 @@ func main() {
 		}
 		cls.C = 0.1
 		cls.Gamma = 0.1
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "rbf C=0.1 gamma=0.1", cls, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 
@@ -119,10 +168,20 @@ func main() {
 		}
 		cls.C = 10
 		cls.Gamma = 10
-		if err = testCls(cls, xTrain, xTest, yTrain, yTest, reportFile); err != nil {
+		if err = runTest(reports, wellID, "rbf C=10 gamma=10", cls, xTrain, xTest, yTrain, yTest); err != nil {
+			log.Fatal(err)
+		}
+
+		// test 8: веса ядер linear, poly и rbf подбираются при обучении
+		mkl := svc.NewMKL(&svc.LinearKernel{}, &svc.PolyKernel{Degree: 3}, &svc.RbfKernel{Gamma: 1})
+		if err = runTest(reports, wellID, "mkl linear+poly+rbf", mkl, xTrain, xTest, yTrain, yTest); err != nil {
 			log.Fatal(err)
 		}
 	}
+
+	if err = reports.Close(); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func readData(fileName string) ([][]float64, []int, error) {
@@ -177,39 +236,197 @@ func readData(fileName string) ([][]float64, []int, error) {
 	return x, y, nil
 }
 
-func testCls(cls svm.Classifier, xTrain, xTest [][]float64, yTrain, yTest []int, sw io.StringWriter) error {
-	sw.WriteString("NEW CLASSIFIER REPORT\n\n")
+// reportExtensions - расширения файла отчета для каждого формата.
+var reportExtensions = map[classification_metrics.ReportFormat]string{
+	classification_metrics.JSONFormat:     "json",
+	classification_metrics.TextFormat:     "txt",
+	classification_metrics.MarkdownFormat: "md",
+	classification_metrics.CSVFormat:      "csv",
+}
+
+// cvScore описывает значения метрики на кросс-валидации.
+type cvScore struct {
+	Metric  classification_metrics.ClassificationMetric `json:"metric"`
+	Scores  []float64                                   `json:"scores"`
+	Average float64                                     `json:"average"`
+}
+
+// classifierReport описывает результаты одного классификатора на данных одной скважины.
+type classifierReport struct {
+	Well          int                                          `json:"well"`
+	Classifier    string                                       `json:"classifier"`
+	CVDuration    string                                       `json:"cv_duration"`
+	CV            []cvScore                                    `json:"cv"`
+	FitDuration   string                                       `json:"fit_duration"`
+	Gamma         float64                                      `json:"gamma,omitempty"`
+	KernelWeights []float64                                    `json:"kernel_weights,omitempty"`
+	Test          *classification_metrics.ClassificationReport `json:"test"`
+}
+
+// runTest тестирует классификатор cls и сразу записывает его отчет для скважины wellID в reports.
+func runTest(reports *reportWriter, wellID int, name string, cls svm.Classifier,
+	xTrain, xTest [][]float64, yTrain, yTest []int) error {
+	report, err := testCls(name, cls, xTrain, xTest, yTrain, yTest)
+	if err != nil {
+		return err
+	}
+	report.Well = wellID
+	return reports.Write(report)
+}
+
+// testCls проводит кросс-валидацию классификатора cls, обучает его и вычисляет метрики на тестовой выборке.
+func testCls(name string, cls svm.Classifier, xTrain, xTest [][]float64, yTrain, yTest []int) (classifierReport, error) {
+	report := classifierReport{Classifier: name}
 
 	// CV
 	now := time.Now()
 	scores, err := cross_validation.KFoldCVScore(cls, xTrain, yTrain, 5, classification_metrics.Accuracy, classification_metrics.F1)
 	if err != nil {
-		return err
+		return report, err
+	}
+	report.CVDuration = time.Since(now).String()
+	// Выведем метрики в одном и том же порядке, чтобы отчеты можно было сравнивать между запусками.
+	metrics := make([]classification_metrics.ClassificationMetric, 0, len(scores))
+	for k := range scores {
+		metrics = append(metrics, k)
 	}
-	sw.WriteString(fmt.Sprintf("CV duration: %s\n\n", time.Since(now).String()))
-	for k, v := range scores {
-		sw.WriteString(fmt.Sprintf("Metric %s:\n", string(k)))
-		sw.WriteString(fmt.Sprintf("Scores %+v:\n", v))
-		sw.WriteString(fmt.Sprintf("Avg scores: %f\n", vector_operations.Average(v)))
-		sw.WriteString("\n")
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i] < metrics[j]
+	})
+	for _, k := range metrics {
+		report.CV = append(report.CV, cvScore{
+			Metric:  k,
+			Scores:  scores[k],
+			Average: vector_operations.Average(scores[k]),
+		})
 	}
-	sw.WriteString("---\n")
 
 	// FIT
 	now = time.Now()
 	if err = cls.Fit(xTrain, yTrain); err != nil {
-		return err
+		return report, err
+	}
+	report.FitDuration = time.Since(now).String()
+	if g, ok := cls.(interface{ FittedGamma() float64 }); ok {
+		report.Gamma = g.FittedGamma()
+	}
+	if w, ok := cls.(interface{ KernelWeights() []float64 }); ok {
+		report.KernelWeights = w.KernelWeights()
 	}
-	sw.WriteString(fmt.Sprintf("Fit duration: %s\n", time.Since(now).String()))
-	sw.WriteString("---\n")
 
 	// PREDICT
-	yPred := cls.Predict(xTest)
-	sw.WriteString(fmt.Sprintf("Accuracy: %f\n", multiclass_metrics.Accuracy(yTest, yPred)))
-	sw.WriteString(fmt.Sprintf("F1 micro: %f\n", multiclass_metrics.FScore(yTest, yPred, multiclass_metrics.Micro)))
-	sw.WriteString(fmt.Sprintf("F1 macro: %f\n", multiclass_metrics.FScore(yTest, yPred, multiclass_metrics.Macro)))
-	sw.WriteString(fmt.Sprintf("F1 weighted: %f\n", multiclass_metrics.FScore(yTest, yPred, multiclass_metrics.Weighted)))
-	sw.WriteString("---\n")
-
-	return nil
+	report.Test, err = classification_metrics.NewClassificationReport(yTest, cls.Predict(xTest), nil)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// reportWriter записывает отчеты классификаторов по мере их получения, чтобы при ошибке
+// в одном из тестов отчеты предыдущих тестов уже были сохранены.
+// В формате JSON это массив отчетов, в формате CSV - таблица метрик на тестовой выборке
+// со столбцами скважины и классификатора, в текстовых форматах - отчеты друг за другом.
+type reportWriter struct {
+	w      io.Writer
+	format classification_metrics.ReportFormat
+	csv    *csv.Writer
+	n      int // число записанных отчетов
+	well   int // скважина последнего записанного отчета
+}
+
+// newReportWriter создает reportWriter, записывающий отчеты в w в формате format.
+func newReportWriter(w io.Writer, format classification_metrics.ReportFormat) *reportWriter {
+	rw := &reportWriter{w: w, format: format}
+	if format == classification_metrics.CSVFormat {
+		rw.csv = csv.NewWriter(w)
+	}
+	return rw
+}
+
+// Write записывает отчет report.
+func (rw *reportWriter) Write(report classifierReport) error {
+	defer func() { rw.n++ }()
+	switch rw.format {
+	case classification_metrics.JSONFormat:
+		sep := ",\n"
+		if rw.n == 0 {
+			sep = "[\n"
+		}
+		data, err := json.MarshalIndent(report, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(rw.w, sep+"  "+string(data))
+		return err
+	case classification_metrics.CSVFormat:
+		for i, record := range report.Test.Records() {
+			if i == 0 && rw.n > 0 {
+				continue
+			}
+			prefix := []string{strconv.Itoa(report.Well), report.Classifier}
+			if i == 0 {
+				prefix = []string{"well", "classifier"}
+			}
+			if err := rw.csv.Write(append(prefix, record...)); err != nil {
+				return err
+			}
+		}
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+
+	wellHeading, clsHeading := "%s\n\n", "%s\n\n"
+	if rw.format == classification_metrics.MarkdownFormat {
+		wellHeading, clsHeading = "## %s\n\n", "### %s\n\n"
+	}
+	if report.Well != rw.well {
+		rw.well = report.Well
+		if _, err := fmt.Fprintf(rw.w, wellHeading, fmt.Sprintf("REPORT FOR WELL %d", rw.well)); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(rw.w, clsHeading, "CLASSIFIER "+report.Classifier); err != nil {
+		return err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("CV duration: %s\n\n", report.CVDuration))
+	for _, score := range report.CV {
+		sb.WriteString(fmt.Sprintf("Metric %s:\n", string(score.Metric)))
+		sb.WriteString(fmt.Sprintf("Scores %+v:\n", score.Scores))
+		sb.WriteString(fmt.Sprintf("Avg scores: %f\n", score.Average))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("Fit duration: %s\n", report.FitDuration))
+	if report.Gamma != 0 {
+		sb.WriteString(fmt.Sprintf("Gamma: %f\n", report.Gamma))
+	}
+	if report.KernelWeights != nil {
+		sb.WriteString(fmt.Sprintf("Kernel weights: %v\n", report.KernelWeights))
+	}
+	sb.WriteString("---\n\n")
+	if _, err := io.WriteString(rw.w, sb.String()); err != nil {
+		return err
+	}
+
+	if err := report.Test.Write(rw.w, rw.format); err != nil {
+		return err
+	}
+	_, err := io.WriteString(rw.w, "\n")
+	return err
+}
+
+// Close завершает вывод отчетов: в формате JSON закрывает массив.
+func (rw *reportWriter) Close() error {
+	if rw.format != classification_metrics.JSONFormat {
+		return nil
+	}
+	end := "\n]\n"
+	if rw.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(rw.w, end)
+	return err
 }
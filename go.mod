@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/jinzhu/copier v0.3.5
+	github.com/xuri/excelize/v2 v2.6.0
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 )
 
@@ -12,7 +13,6 @@ require (
 	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.1 // indirect
 	github.com/xuri/efp v0.0.0-20220407160117-ad0f7a785be8 // indirect
-	github.com/xuri/excelize/v2 v2.6.0 // indirect
 	github.com/xuri/nfp v0.0.0-20220409054826-5e722a1d9e22 // indirect
 	golang.org/x/crypto v0.0.0-20220408190544-5352b0902921 // indirect
 	golang.org/x/net v0.0.0-20220407224826-aac1ed45d8e3 // indirect
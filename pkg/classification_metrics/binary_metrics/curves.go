@@ -0,0 +1,148 @@
+package binary_metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// curvePoint описывает точку кривой: число верно и ошибочно предсказанных положительных объектов,
+// если положительными считаются объекты со значением не меньше порога threshold.
+type curvePoint struct {
+	threshold float64
+	tp        int
+	fp        int
+}
+
+// binaryCurve возвращает точки кривой для всех различных значений scores в порядке убывания порога,
+// а также общее число положительных и отрицательных объектов.
+// Положительный класс задается опцией PosLabel.
+// Объекты с одинаковыми значениями scores попадают в одну точку, поэтому кривая не зависит от их порядка.
+func binaryCurve(yTrue []int, scores []float64, opts []Option) (points []curvePoint, nPositive, nNegative int, err error) {
+	if len(yTrue) != len(scores) {
+		return nil, 0, 0, fmt.Errorf("yTrue and scores must have the same length, actual: %d and %d", len(yTrue), len(scores))
+	}
+	if len(yTrue) == 0 {
+		return nil, 0, 0, fmt.Errorf("yTrue is empty")
+	}
+	pos, err := positiveLabel(opts, yTrue)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return scores[idx[i]] > scores[idx[j]]
+	})
+
+	tp, fp := 0, 0
+	for k, i := range idx {
+		if yTrue[i] == pos {
+			tp++
+		} else {
+			fp++
+		}
+		// Точка добавляется только после последнего объекта с данным значением.
+		if k == len(idx)-1 || scores[idx[k+1]] != scores[i] {
+			points = append(points, curvePoint{threshold: scores[i], tp: tp, fp: fp})
+		}
+	}
+
+	return points, tp, fp, nil
+}
+
+// ROCCurve вычисляет ROC-кривую по значениям решающей функции scores.
+// Положительный класс задается опцией PosLabel.
+// Возвращает доли ложноположительных fpr и истинно положительных tpr объектов для каждого порога thresholds:
+// объект считается положительным, если его значение не меньше порога. Пороги - все различные значения scores
+// в порядке убывания, перед ними добавлен порог +Inf, соответствующий точке (0, 0).
+// Возвращает ошибку, если в yTrue больше двух различных меток или нет объектов одного из классов.
+func ROCCurve(yTrue []int, scores []float64, opts ...Option) (fpr, tpr, thresholds []float64, err error) {
+	points, nPositive, nNegative, err := binaryCurve(yTrue, scores, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if nPositive == 0 || nNegative == 0 {
+		return nil, nil, nil, fmt.Errorf("ROC curve is undefined when yTrue contains only one class")
+	}
+
+	fpr = make([]float64, 0, len(points)+1)
+	tpr = make([]float64, 0, len(points)+1)
+	thresholds = make([]float64, 0, len(points)+1)
+	fpr = append(fpr, 0)
+	tpr = append(tpr, 0)
+	thresholds = append(thresholds, math.Inf(1))
+	for _, point := range points {
+		fpr = append(fpr, float64(point.fp)/float64(nNegative))
+		tpr = append(tpr, float64(point.tp)/float64(nPositive))
+		thresholds = append(thresholds, point.threshold)
+	}
+
+	return fpr, tpr, thresholds, nil
+}
+
+// PrecisionRecallCurve вычисляет кривую точность-полнота по значениям решающей функции scores.
+// Положительный класс задается опцией PosLabel.
+// Возвращает точность precision и полноту recall для каждого порога thresholds в том же порядке, что и ROCCurve:
+// первым идет порог +Inf с точностью 1 и полнотой 0.
+// Возвращает ошибку, если в yTrue больше двух различных меток или нет положительных объектов.
+func PrecisionRecallCurve(yTrue []int, scores []float64, opts ...Option) (precision, recall, thresholds []float64, err error) {
+	points, nPositive, _, err := binaryCurve(yTrue, scores, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if nPositive == 0 {
+		return nil, nil, nil, fmt.Errorf("precision-recall curve is undefined when yTrue contains no positive objects")
+	}
+
+	precision = make([]float64, 0, len(points)+1)
+	recall = make([]float64, 0, len(points)+1)
+	thresholds = make([]float64, 0, len(points)+1)
+	precision = append(precision, 1)
+	recall = append(recall, 0)
+	thresholds = append(thresholds, math.Inf(1))
+	for _, point := range points {
+		precision = append(precision, float64(point.tp)/float64(point.tp+point.fp))
+		recall = append(recall, float64(point.tp)/float64(nPositive))
+		thresholds = append(thresholds, point.threshold)
+	}
+
+	return precision, recall, thresholds, nil
+}
+
+// ROCAUC вычисляет площадь под ROC-кривой методом трапеций.
+// Равна вероятности того, что случайный положительный объект получит большее значение решающей функции,
+// чем случайный отрицательный; при равных значениях засчитывается половина.
+// Возвращает ошибку в тех же случаях, что и ROCCurve.
+func ROCAUC(yTrue []int, scores []float64, opts ...Option) (float64, error) {
+	fpr, tpr, _, err := ROCCurve(yTrue, scores, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for k := 1; k < len(fpr); k++ {
+		res += (fpr[k] - fpr[k-1]) * (tpr[k] + tpr[k-1]) / 2
+	}
+	return res, nil
+}
+
+// AveragePrecision вычисляет среднюю точность: сумму точностей на каждом пороге,
+// взвешенных приростом полноты, AP = sum((R_k - R_(k-1)) * P_k).
+// В отличие от площади под кривой точность-полнота, вычисленной методом трапеций, не завышает оценку.
+// Возвращает ошибку в тех же случаях, что и PrecisionRecallCurve.
+func AveragePrecision(yTrue []int, scores []float64, opts ...Option) (float64, error) {
+	precision, recall, _, err := PrecisionRecallCurve(yTrue, scores, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for k := 1; k < len(recall); k++ {
+		res += (recall[k] - recall[k-1]) * precision[k]
+	}
+	return res, nil
+}
@@ -0,0 +1,230 @@
+package binary_metrics
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestROCCurve(t *testing.T) {
+	type args struct {
+		yTrue  []int
+		scores []float64
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantFpr        []float64
+		wantTpr        []float64
+		wantThresholds []float64
+		wantErr        bool
+	}{
+		{
+			name: "Test distinct scores",
+			args: args{
+				yTrue:  []int{-1, -1, 1, 1},
+				scores: []float64{0.1, 0.4, 0.35, 0.8},
+			},
+			wantFpr:        []float64{0, 0, 0.5, 0.5, 1},
+			wantTpr:        []float64{0, 0.5, 0.5, 1, 1},
+			wantThresholds: []float64{math.Inf(1), 0.8, 0.4, 0.35, 0.1},
+		},
+		{
+			name: "Test tied scores",
+			args: args{
+				yTrue:  []int{1, -1, 1, -1},
+				scores: []float64{0.5, 0.5, 0.9, 0.1},
+			},
+			wantFpr:        []float64{0, 0, 0.5, 1},
+			wantTpr:        []float64{0, 0.5, 1, 1},
+			wantThresholds: []float64{math.Inf(1), 0.9, 0.5, 0.1},
+		},
+		{
+			name: "Test one class",
+			args: args{
+				yTrue:  []int{1, 1},
+				scores: []float64{0.1, 0.2},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test different lengths",
+			args: args{
+				yTrue:  []int{1, -1},
+				scores: []float64{0.1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fpr, tpr, thresholds, err := ROCCurve(tt.args.yTrue, tt.args.scores)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ROCCurve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(fpr, tt.wantFpr) {
+				t.Errorf("ROCCurve() fpr = %v, want %v", fpr, tt.wantFpr)
+			}
+			if !reflect.DeepEqual(tpr, tt.wantTpr) {
+				t.Errorf("ROCCurve() tpr = %v, want %v", tpr, tt.wantTpr)
+			}
+			if !reflect.DeepEqual(thresholds, tt.wantThresholds) {
+				t.Errorf("ROCCurve() thresholds = %v, want %v", thresholds, tt.wantThresholds)
+			}
+		})
+	}
+}
+
+func TestPrecisionRecallCurve(t *testing.T) {
+	yTrue := []int{-1, -1, 1, 1}
+	scores := []float64{0.1, 0.4, 0.35, 0.8}
+
+	precision, recall, thresholds, err := PrecisionRecallCurve(yTrue, scores)
+	if err != nil {
+		t.Fatalf("PrecisionRecallCurve() error = %v", err)
+	}
+	wantPrecision := []string{"1.000", "1.000", "0.500", "0.667", "0.500"}
+	wantRecall := []float64{0, 0.5, 0.5, 1, 1}
+	wantThresholds := []float64{math.Inf(1), 0.8, 0.4, 0.35, 0.1}
+	for k := range precision {
+		if fmt.Sprintf("%.3f", precision[k]) != wantPrecision[k] {
+			t.Errorf("PrecisionRecallCurve() precision[%d] = %v, want %v", k, precision[k], wantPrecision[k])
+		}
+	}
+	if len(precision) != len(wantPrecision) {
+		t.Errorf("PrecisionRecallCurve() len(precision) = %d, want %d", len(precision), len(wantPrecision))
+	}
+	if !reflect.DeepEqual(recall, wantRecall) {
+		t.Errorf("PrecisionRecallCurve() recall = %v, want %v", recall, wantRecall)
+	}
+	if !reflect.DeepEqual(thresholds, wantThresholds) {
+		t.Errorf("PrecisionRecallCurve() thresholds = %v, want %v", thresholds, wantThresholds)
+	}
+
+	if _, _, _, err := PrecisionRecallCurve([]int{-1, -1}, []float64{0.1, 0.2}); err == nil {
+		t.Errorf("PrecisionRecallCurve() expected error for no positive objects")
+	}
+}
+
+func TestROCAUC(t *testing.T) {
+	type args struct {
+		yTrue  []int
+		scores []float64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test distinct scores",
+			args: args{
+				yTrue:  []int{-1, -1, 1, 1},
+				scores: []float64{0.1, 0.4, 0.35, 0.8},
+			},
+			want: "0.750",
+		},
+		{
+			name: "Test tied scores",
+			args: args{
+				yTrue:  []int{1, 1, -1, -1, 1},
+				scores: []float64{0.8, 0.5, 0.5, 0.2, 0.5},
+			},
+			want: "0.833",
+		},
+		{
+			name: "Test all scores equal",
+			args: args{
+				yTrue:  []int{-1, 1, -1, 1},
+				scores: []float64{0.5, 0.5, 0.5, 0.5},
+			},
+			want: "0.500",
+		},
+		{
+			name: "Test perfectly wrong ranking",
+			args: args{
+				yTrue:  []int{1, 1, -1, -1},
+				scores: []float64{-2, -1, 1, 2},
+			},
+			want: "0.000",
+		},
+		{
+			name: "Test one class",
+			args: args{
+				yTrue:  []int{-1, -1},
+				scores: []float64{0.1, 0.2},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ROCAUC(tt.args.yTrue, tt.args.scores)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ROCAUC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", got) != tt.want {
+				t.Errorf("ROCAUC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAveragePrecision(t *testing.T) {
+	type args struct {
+		yTrue  []int
+		scores []float64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test distinct scores",
+			args: args{
+				yTrue:  []int{-1, -1, 1, 1},
+				scores: []float64{0.1, 0.4, 0.35, 0.8},
+			},
+			want: "0.833",
+		},
+		{
+			name: "Test tied scores",
+			args: args{
+				yTrue:  []int{1, 1, -1, -1, 1},
+				scores: []float64{0.8, 0.5, 0.5, 0.2, 0.5},
+			},
+			want: "0.833",
+		},
+		{
+			name: "Test all scores equal",
+			args: args{
+				yTrue:  []int{-1, 1, -1, 1},
+				scores: []float64{0.5, 0.5, 0.5, 0.5},
+			},
+			want: "0.500",
+		},
+		{
+			name: "Test no positive objects",
+			args: args{
+				yTrue:  []int{-1, -1},
+				scores: []float64{0.1, 0.2},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AveragePrecision(tt.args.yTrue, tt.args.scores)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AveragePrecision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", got) != tt.want {
+				t.Errorf("AveragePrecision() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package binary_metrics
+
+import "math"
+
+// ratio возвращает num / den или 0, если знаменатель равен нулю.
+func ratio(num, den int) float64 {
+	if den == 0 {
+		return 0.0
+	}
+	return float64(num) / float64(den)
+}
+
+// MatthewsCorrCoef вычисляет коэффициент корреляции Мэтьюса (MCC):
+// (TP*TN - FP*FN) / sqrt((TP+FP)(TP+FN)(TN+FP)(TN+FN)).
+// Принимает значения от -1 до 1 и, в отличие от Accuracy, не завышает оценку на несбалансированных классах.
+// Если один из множителей знаменателя равен нулю, возвращает 0.
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func MatthewsCorrCoef(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	tn, fp, fn, tp := float64(cm[0][0]), float64(cm[0][1]), float64(cm[1][0]), float64(cm[1][1])
+	den := math.Sqrt((tp + fp) * (tp + fn) * (tn + fp) * (tn + fn))
+	if den == 0 {
+		return 0.0, nil
+	}
+	return (tp*tn - fp*fn) / den, nil
+}
+
+// CohenKappa вычисляет коэффициент каппа Коэна - согласованность предсказаний с настоящими метками
+// с поправкой на случайное совпадение: (p0 - pe) / (1 - pe), где p0 - доля совпадений (Accuracy),
+// pe - ожидаемая доля совпадений при независимых yTrue и yPred.
+// Для двух классов взвешенная каппа (с линейными или квадратичными весами) совпадает с обычной.
+// Если pe = 1, возвращает 0.
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func CohenKappa(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	tn, fp, fn, tp := float64(cm[0][0]), float64(cm[0][1]), float64(cm[1][0]), float64(cm[1][1])
+	n := tn + fp + fn + tp
+	if n == 0 {
+		return 0.0, nil
+	}
+	p0 := (tp + tn) / n
+	pe := ((tp+fp)*(tp+fn) + (tn+fn)*(tn+fp)) / (n * n)
+	if pe == 1 {
+		return 0.0, nil
+	}
+	return (p0 - pe) / (1 - pe), nil
+}
+
+// BalancedAccuracy вычисляет сбалансированную точность - среднее полноты положительного класса (Recall)
+// и полноты отрицательного класса (Specificity). Класс, которого нет в yTrue, в среднем не участвует.
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func BalancedAccuracy(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	tn, fp, fn, tp := cm[0][0], cm[0][1], cm[1][0], cm[1][1]
+
+	res, count := 0.0, 0
+	if tp+fn > 0 {
+		res += ratio(tp, tp+fn)
+		count++
+	}
+	if tn+fp > 0 {
+		res += ratio(tn, tn+fp)
+		count++
+	}
+	if count == 0 {
+		return 0.0, nil
+	}
+	return res / float64(count), nil
+}
+
+// Specificity вычисляет специфичность - полноту отрицательного класса: TN / (TN + FP).
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func Specificity(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(cm[0][0], cm[0][0]+cm[0][1]), nil
+}
+
+// NegativePredictiveValue вычисляет прогностическую ценность отрицательного результата (NPV) -
+// точность отрицательного класса: TN / (TN + FN).
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func NegativePredictiveValue(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(cm[0][0], cm[0][0]+cm[1][0]), nil
+}
+
+// Jaccard вычисляет индекс Жаккара для положительного класса: TP / (TP + FP + FN).
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func Jaccard(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(cm[1][1], cm[1][1]+cm[0][1]+cm[1][0]), nil
+}
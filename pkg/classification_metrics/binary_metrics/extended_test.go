@@ -0,0 +1,171 @@
+package binary_metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtendedMetrics(t *testing.T) {
+	// TP = 2, FN = 1, FP = 2, TN = 3.
+	yTrue := []int{1, 1, 1, 0, 0, 0, 0, 0}
+	yPred := []int{1, 1, 0, 1, 0, 0, 0, 1}
+
+	metrics := map[string]func(yTrue []int, yPred []int, opts ...Option) (float64, error){
+		"MatthewsCorrCoef":        MatthewsCorrCoef,
+		"CohenKappa":              CohenKappa,
+		"BalancedAccuracy":        BalancedAccuracy,
+		"Specificity":             Specificity,
+		"NegativePredictiveValue": NegativePredictiveValue,
+		"Jaccard":                 Jaccard,
+	}
+
+	type args struct {
+		yTrue []int
+		yPred []int
+		opts  []Option
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "Test 0/1 labels",
+			args: args{yTrue: yTrue, yPred: yPred},
+			want: map[string]string{
+				"MatthewsCorrCoef":        "0.258",
+				"CohenKappa":              "0.250",
+				"BalancedAccuracy":        "0.633",
+				"Specificity":             "0.600",
+				"NegativePredictiveValue": "0.750",
+				"Jaccard":                 "0.400",
+			},
+		},
+		{
+			name: "Test explicit positive label",
+			args: args{
+				yTrue: []int{-1, -1, -1, 1, 1, 1, 1, 1},
+				yPred: []int{-1, -1, 1, -1, 1, 1, 1, -1},
+				opts:  []Option{PosLabel(-1)},
+			},
+			want: map[string]string{
+				"MatthewsCorrCoef":        "0.258",
+				"CohenKappa":              "0.250",
+				"BalancedAccuracy":        "0.633",
+				"Specificity":             "0.600",
+				"NegativePredictiveValue": "0.750",
+				"Jaccard":                 "0.400",
+			},
+		},
+		{
+			name: "Test only negative objects",
+			args: args{yTrue: []int{0, 0, 0}, yPred: []int{0, 0, 0}},
+			want: map[string]string{
+				"MatthewsCorrCoef":        "0.000",
+				"CohenKappa":              "0.000",
+				"BalancedAccuracy":        "1.000",
+				"Specificity":             "1.000",
+				"NegativePredictiveValue": "1.000",
+				"Jaccard":                 "0.000",
+			},
+		},
+		{
+			name:    "Test more than two labels",
+			args:    args{yTrue: []int{0, 1, 2}, yPred: []int{0, 1, 1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		for name, metric := range metrics {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				got, err := metric(tt.args.yTrue, tt.args.yPred, tt.args.opts...)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("%s() error = %v, wantErr %v", name, err, tt.wantErr)
+				}
+				if !tt.wantErr && fmt.Sprintf("%.3f", got) != tt.want[name] {
+					t.Errorf("%s() = %v, want %v", name, got, tt.want[name])
+				}
+			})
+		}
+	}
+}
+
+func TestProbabilisticMetrics(t *testing.T) {
+	type args struct {
+		yTrue []int
+		proba []float64
+		opts  []Option
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantLogLoss    string
+		wantBrierScore string
+		wantErr        bool
+	}{
+		{
+			name: "Test 0/1 labels",
+			args: args{
+				yTrue: []int{1, 0, 1, 1, 0},
+				proba: []float64{0.9, 0.2, 0.6, 0.8, 0.1},
+			},
+			wantLogLoss:    "0.234",
+			wantBrierScore: "0.052",
+		},
+		{
+			name: "Test explicit positive label",
+			args: args{
+				yTrue: []int{3, 7, 3, 3, 7},
+				proba: []float64{0.9, 0.2, 0.6, 0.8, 0.1},
+				opts:  []Option{PosLabel(3)},
+			},
+			wantLogLoss:    "0.234",
+			wantBrierScore: "0.052",
+		},
+		{
+			name: "Test confident mistake is finite",
+			args: args{
+				yTrue: []int{1, 0},
+				proba: []float64{0, 0},
+			},
+			wantLogLoss:    "17.269",
+			wantBrierScore: "0.500",
+		},
+		{
+			name: "Test probability out of range",
+			args: args{
+				yTrue: []int{1, 0},
+				proba: []float64{1.5, 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test different lengths",
+			args: args{
+				yTrue: []int{1, 0},
+				proba: []float64{0.5},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logLoss, err := LogLoss(tt.args.yTrue, tt.args.proba, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogLoss() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", logLoss) != tt.wantLogLoss {
+				t.Errorf("LogLoss() = %v, want %v", logLoss, tt.wantLogLoss)
+			}
+
+			brierScore, err := BrierScore(tt.args.yTrue, tt.args.proba, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BrierScore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", brierScore) != tt.wantBrierScore {
+				t.Errorf("BrierScore() = %v, want %v", brierScore, tt.wantBrierScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package binary_metrics
+
+import (
+	"fmt"
+
+	cls_metrics "github.com/ziyadovea/svm/pkg/classification_metrics"
+)
+
+// Option задает необязательный параметр бинарной метрики.
+type Option func(o *options)
+
+// options описывает необязательные параметры бинарных метрик.
+type options struct {
+	// Метка положительного класса и признак того, что она задана явно.
+	posLabel    int
+	hasPosLabel bool
+}
+
+// PosLabel задает метку положительного класса. Остальные метки относятся к отрицательному классу.
+// По умолчанию положительным считается класс 1 для меток из {-1, 1} или {0, 1}, иначе - класс с большей меткой.
+func PosLabel(label int) Option {
+	return func(o *options) {
+		o.posLabel = label
+		o.hasPosLabel = true
+	}
+}
+
+// DefaultPosLabel возвращает метку положительного класса по умолчанию для меток из слайсов ys.
+// Возвращает ошибку, если в ys больше двух различных меток.
+// Полезна, когда метрика вычисляется по частям выборки, в которых может встречаться только один класс.
+func DefaultPosLabel(ys ...[]int) (int, error) {
+	return positiveLabel(nil, ys...)
+}
+
+// positiveLabel проверяет, что в слайсах ys не больше двух различных меток, и возвращает метку положительного класса,
+// заданную опцией PosLabel, или метку по умолчанию. Явно заданная метка должна встречаться в ys, если в них уже две метки.
+func positiveLabel(opts []Option, ys ...[]int) (int, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	labels := cls_metrics.UniqueLabels(ys...)
+	if cls_metrics.TypeOfTarget(ys...) != cls_metrics.Binary {
+		return 0, fmt.Errorf("binary metrics require at most two labels, actual: %v", labels)
+	}
+
+	if o.hasPosLabel {
+		if len(labels) == 2 && labels[0] != o.posLabel && labels[1] != o.posLabel {
+			return 0, fmt.Errorf("positive label %d is not present in labels %v", o.posLabel, labels)
+		}
+		return o.posLabel, nil
+	}
+
+	signs, bits := true, true
+	for _, label := range labels {
+		signs = signs && (label == -1 || label == 1)
+		bits = bits && (label == 0 || label == 1)
+	}
+	if signs || bits {
+		return 1, nil
+	}
+	return labels[len(labels)-1], nil
+}
+
+// checkLengths проверяет, что слайсы настоящих и предсказанных меток имеют одинаковую длину.
+func checkLengths(yTrue, yPred []int) error {
+	if len(yTrue) != len(yPred) {
+		return fmt.Errorf("yTrue and yPred must have the same length, actual: %d and %d", len(yTrue), len(yPred))
+	}
+	return nil
+}
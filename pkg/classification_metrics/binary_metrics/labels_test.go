@@ -0,0 +1,158 @@
+package binary_metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPosLabel(t *testing.T) {
+	// Одна и та же задача с разными метками: у всех вариантов одинаковые метрики.
+	signs := []int{1, 1, -1, -1, 1, -1, 1, 1, -1, -1, 1, 1, 1, 1, 1, -1, -1, -1}
+	signsPred := []int{1, -1, 1, 1, 1, -1, 1, 1, 1, -1, 1, -1, -1, 1, 1, 1, -1, -1}
+	relabel := func(y []int, pos, neg int) []int {
+		res := make([]int, len(y))
+		for i := range y {
+			if y[i] == 1 {
+				res[i] = pos
+			} else {
+				res[i] = neg
+			}
+		}
+		return res
+	}
+
+	type args struct {
+		yTrue []int
+		yPred []int
+		opts  []Option
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantPrecision string
+		wantRecall    string
+		wantErr       bool
+	}{
+		{
+			name:          "Test -1/+1 labels",
+			args:          args{yTrue: signs, yPred: signsPred},
+			wantPrecision: "0.636",
+			wantRecall:    "0.700",
+		},
+		{
+			name:          "Test 0/1 labels",
+			args:          args{yTrue: relabel(signs, 1, 0), yPred: relabel(signsPred, 1, 0)},
+			wantPrecision: "0.636",
+			wantRecall:    "0.700",
+		},
+		{
+			name:          "Test arbitrary labels, larger label is positive by default",
+			args:          args{yTrue: relabel(signs, 7, 3), yPred: relabel(signsPred, 7, 3)},
+			wantPrecision: "0.636",
+			wantRecall:    "0.700",
+		},
+		{
+			name: "Test explicit positive label",
+			args: args{
+				yTrue: relabel(signs, 3, 7),
+				yPred: relabel(signsPred, 3, 7),
+				opts:  []Option{PosLabel(3)},
+			},
+			wantPrecision: "0.636",
+			wantRecall:    "0.700",
+		},
+		{
+			name: "Test explicit negative class as positive",
+			args: args{
+				yTrue: signs,
+				yPred: signsPred,
+				opts:  []Option{PosLabel(-1)},
+			},
+			wantPrecision: "0.571",
+			wantRecall:    "0.500",
+		},
+		{
+			name: "Test only negative labels",
+			args: args{
+				yTrue: []int{0, 0, 0},
+				yPred: []int{0, 0, 0},
+			},
+			wantPrecision: "0.000",
+			wantRecall:    "0.000",
+		},
+		{
+			name: "Test more than two labels",
+			args: args{
+				yTrue: []int{0, 1, 2},
+				yPred: []int{0, 1, 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test absent positive label",
+			args: args{
+				yTrue: []int{0, 1, 1},
+				yPred: []int{0, 1, 0},
+				opts:  []Option{PosLabel(5)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test different lengths",
+			args: args{
+				yTrue: []int{0, 1, 1},
+				yPred: []int{0, 1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precision, err := Precision(tt.args.yTrue, tt.args.yPred, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Precision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			recall, err := Recall(tt.args.yTrue, tt.args.yPred, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Recall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprintf("%.3f", precision) != tt.wantPrecision {
+				t.Errorf("Precision() = %v, want %v", precision, tt.wantPrecision)
+			}
+			if fmt.Sprintf("%.3f", recall) != tt.wantRecall {
+				t.Errorf("Recall() = %v, want %v", recall, tt.wantRecall)
+			}
+		})
+	}
+}
+
+func TestROCAUC_PosLabel(t *testing.T) {
+	scores := []float64{0.1, 0.4, 0.35, 0.8}
+
+	// Для меток 0/1 положительный класс - 1, для меток 3/7 - 7.
+	for _, yTrue := range [][]int{{0, 0, 1, 1}, {3, 3, 7, 7}} {
+		got, err := ROCAUC(yTrue, scores)
+		if err != nil {
+			t.Fatalf("ROCAUC() error = %v", err)
+		}
+		if fmt.Sprintf("%.3f", got) != "0.750" {
+			t.Errorf("ROCAUC(%v) = %v, want 0.750", yTrue, got)
+		}
+	}
+
+	// Если положительный класс - 3, то ранжирование обратное.
+	got, err := ROCAUC([]int{3, 3, 7, 7}, scores, PosLabel(3))
+	if err != nil {
+		t.Fatalf("ROCAUC() error = %v", err)
+	}
+	if fmt.Sprintf("%.3f", got) != "0.250" {
+		t.Errorf("ROCAUC() = %v, want 0.250", got)
+	}
+
+	if _, err := ROCAUC([]int{0, 1, 2, 2}, scores); err == nil {
+		t.Errorf("ROCAUC() expected error for more than two labels")
+	}
+}
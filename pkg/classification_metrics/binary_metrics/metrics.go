@@ -8,19 +8,27 @@ import (
 // GetConfusionMatrix вычисляет матрицу ошибок.
 // Возвращает ее в 2 видах: в виде матрицы 2 на 2
 // и в виде строки для красивого вывода.
-func GetConfusionMatrix(yTrue []int, yPred []int) ([2][2]int, string) {
+// Метки классов могут быть любыми, положительный класс задается опцией PosLabel.
+// Возвращает ошибку, если длины yTrue и yPred различны или в них больше двух различных меток.
+func GetConfusionMatrix(yTrue []int, yPred []int, opts ...Option) ([2][2]int, string, error) {
+	if err := checkLengths(yTrue, yPred); err != nil {
+		return [2][2]int{}, "", err
+	}
+	pos, err := positiveLabel(opts, yTrue, yPred)
+	if err != nil {
+		return [2][2]int{}, "", err
+	}
+
 	tp, fp, fn, tn := 0, 0, 0, 0
 	for i := range yTrue {
-		if yTrue[i] == +1 && yPred[i] == +1 {
+		switch {
+		case yTrue[i] == pos && yPred[i] == pos:
 			tp++
-		}
-		if yTrue[i] == -1 && yPred[i] == +1 {
+		case yTrue[i] != pos && yPred[i] == pos:
 			fp++
-		}
-		if yTrue[i] == +1 && yPred[i] == -1 {
+		case yTrue[i] == pos && yPred[i] != pos:
 			fn++
-		}
-		if yTrue[i] == -1 && yPred[i] == -1 {
+		default:
 			tn++
 		}
 	}
@@ -37,7 +45,7 @@ Confusion matrix
 	return [2][2]int{
 		{tn, fp},
 		{fn, tp},
-	}, confusionMatrixStr
+	}, confusionMatrixStr, nil
 }
 
 // Accuracy вычисляет метрику классификации Accuracy.
@@ -52,54 +60,71 @@ func Accuracy(yTrue []int, yPred []int) float64 {
 }
 
 // Precision вычисляет метрику классификации Precision.
-func Precision(yTrue []int, yPred []int) float64 {
-	cm, _ := GetConfusionMatrix(yTrue, yPred)
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func Precision(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
 	res := float64(cm[1][1]) / float64(cm[1][1]+cm[0][1])
 	if math.IsNaN(res) {
-		return 0.0
+		return 0.0, nil
 	}
-	return res
+	return res, nil
 }
 
 // Recall вычисляет метрику классификации Recall.
-func Recall(yTrue []int, yPred []int) float64 {
-	cm, _ := GetConfusionMatrix(yTrue, yPred)
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func Recall(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	cm, _, err := GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
 	res := float64(cm[1][1]) / float64(cm[1][1]+cm[1][0])
 	if math.IsNaN(res) {
-		return 0.0
+		return 0.0, nil
 	}
-	return res
+	return res, nil
 }
 
 // FScore вычисляет метрику классификации F-мера.
-func FScore(yTrue []int, yPred []int) float64 {
-	precision := Precision(yTrue, yPred)
-	recall := Recall(yTrue, yPred)
-	res := 2 * precision * recall / (precision + recall)
-	if math.IsNaN(res) {
-		return 0.0
-	}
-	return res
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func FScore(yTrue []int, yPred []int, opts ...Option) (float64, error) {
+	return FBetaScore(yTrue, yPred, 1, opts...)
 }
 
 // FBetaScore вычисляет метрику классификации расширенная F-мера.
-func FBetaScore(yTrue []int, yPred []int, beta float64) float64 {
-	precision := Precision(yTrue, yPred)
-	recall := Recall(yTrue, yPred)
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func FBetaScore(yTrue []int, yPred []int, beta float64, opts ...Option) (float64, error) {
+	precision, err := Precision(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
+	recall, err := Recall(yTrue, yPred, opts...)
+	if err != nil {
+		return 0, err
+	}
 	res := (1 + beta*beta) * precision * recall / (beta*beta*precision + recall)
 	if math.IsNaN(res) {
-		return 0.0
+		return 0.0, nil
 	}
-	return res
+	return res, nil
 }
 
 // BinaryClassificationReport вовзращает отчет по метрикам бинарной классификации.
-func BinaryClassificationReport(yTrue []int, yPred []int) (cm [2][2]int, accuracy, precision, recall, f1 float64, reportString string) {
-	cm, reportString = GetConfusionMatrix(yTrue, yPred)
+// Для отчета по каждому классу с выводом в текст, Markdown, JSON или CSV
+// используйте classification_metrics.NewClassificationReport.
+// Возвращает ошибку в тех же случаях, что и GetConfusionMatrix.
+func BinaryClassificationReport(yTrue []int, yPred []int, opts ...Option) (cm [2][2]int, accuracy, precision, recall, f1 float64, reportString string, err error) {
+	cm, reportString, err = GetConfusionMatrix(yTrue, yPred, opts...)
+	if err != nil {
+		return
+	}
 	accuracy = Accuracy(yTrue, yPred)
-	precision = Precision(yTrue, yPred)
-	recall = Recall(yTrue, yPred)
-	f1 = FScore(yTrue, yPred)
+	// Матрица ошибок уже проверила метки, поэтому следующие метрики ошибок не возвращают.
+	precision, _ = Precision(yTrue, yPred, opts...)
+	recall, _ = Recall(yTrue, yPred, opts...)
+	f1, _ = FScore(yTrue, yPred, opts...)
 	reportString += fmt.Sprintf(`
 Accuracy  = %.3f
 Precision = %.3f
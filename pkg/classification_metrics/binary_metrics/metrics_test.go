@@ -39,7 +39,10 @@ Confusion matrix
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := GetConfusionMatrix(tt.args.yTrue, tt.args.yPred)
+			got, got1, err := GetConfusionMatrix(tt.args.yTrue, tt.args.yPred)
+			if err != nil {
+				t.Fatalf("GetConfusionMatrix() error = %v", err)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetConfusionMatrix() got = %v, want %v", got, tt.want)
 			}
@@ -99,7 +102,11 @@ func TestPrecision(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Precision(tt.args.yTrue, tt.args.yPred); fmt.Sprintf("%.3f", got) != tt.want {
+			got, err := Precision(tt.args.yTrue, tt.args.yPred)
+			if err != nil {
+				t.Fatalf("Precision() error = %v", err)
+			}
+			if fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("Precision() = %v, want %v", got, tt.want)
 			}
 		})
@@ -127,7 +134,11 @@ func TestRecall(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Recall(tt.args.yTrue, tt.args.yPred); fmt.Sprintf("%.3f", got) != tt.want {
+			got, err := Recall(tt.args.yTrue, tt.args.yPred)
+			if err != nil {
+				t.Fatalf("Recall() error = %v", err)
+			}
+			if fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("Recall() = %v, want %v", got, tt.want)
 			}
 		})
@@ -155,7 +166,11 @@ func TestFScore(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := FScore(tt.args.yTrue, tt.args.yPred); fmt.Sprintf("%.3f", got) != tt.want {
+			got, err := FScore(tt.args.yTrue, tt.args.yPred)
+			if err != nil {
+				t.Fatalf("FScore() error = %v", err)
+			}
+			if fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("FScore() = %v, want %v", got, tt.want)
 			}
 		})
@@ -194,7 +209,11 @@ func TestFBetaScore(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := FBetaScore(tt.args.yTrue, tt.args.yPred, tt.args.beta); fmt.Sprintf("%.3f", got) != tt.want {
+			got, err := FBetaScore(tt.args.yTrue, tt.args.yPred, tt.args.beta)
+			if err != nil {
+				t.Fatalf("FBetaScore() error = %v", err)
+			}
+			if fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("ExtendedFScore() = %v, want %v", got, tt.want)
 			}
 		})
@@ -247,7 +266,10 @@ F-score   = 0.667
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotCm, gotAccuracy, gotPrecision, gotRecall, gotF1, gotReportString := BinaryClassificationReport(tt.args.yTrue, tt.args.yPred)
+			gotCm, gotAccuracy, gotPrecision, gotRecall, gotF1, gotReportString, err := BinaryClassificationReport(tt.args.yTrue, tt.args.yPred)
+			if err != nil {
+				t.Fatalf("BinaryClassificationReport() error = %v", err)
+			}
 			if !reflect.DeepEqual(gotCm, tt.wantCm) {
 				t.Errorf("BinaryClassificationReport() gotCm = %v, want %v", gotCm, tt.wantCm)
 			}
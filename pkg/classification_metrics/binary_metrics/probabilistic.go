@@ -0,0 +1,71 @@
+package binary_metrics
+
+import (
+	"fmt"
+	"math"
+)
+
+// Eps - величина, до которой ограничиваются вероятности в LogLoss, чтобы логарифм был конечен.
+const Eps = 1e-15
+
+// checkProba проверяет, что вероятности proba согласованы с метками yTrue, и возвращает метку положительного класса.
+func checkProba(yTrue []int, proba []float64, opts []Option) (int, error) {
+	if len(yTrue) != len(proba) {
+		return 0, fmt.Errorf("yTrue and proba must have the same length, actual: %d and %d", len(yTrue), len(proba))
+	}
+	if len(yTrue) == 0 {
+		return 0, fmt.Errorf("yTrue is empty")
+	}
+	for i, p := range proba {
+		if p < 0 || p > 1 || math.IsNaN(p) {
+			return 0, fmt.Errorf("probability must be in [0, 1], proba[%d] = %v", i, p)
+		}
+	}
+	return positiveLabel(opts, yTrue)
+}
+
+// LogLoss вычисляет логистическую функцию потерь (кросс-энтропию) -
+// среднее -log(p) по объектам, где p - предсказанная вероятность настоящего класса объекта.
+// proba - вероятности положительного класса, который задается опцией PosLabel.
+// Вероятности ограничиваются отрезком [Eps, 1 - Eps]. Чем меньше значение, тем лучше.
+// Возвращает ошибку, если длины yTrue и proba различны, вероятности вне отрезка [0, 1]
+// или в yTrue больше двух различных меток.
+func LogLoss(yTrue []int, proba []float64, opts ...Option) (float64, error) {
+	pos, err := checkProba(yTrue, proba, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for i := range yTrue {
+		p := math.Min(math.Max(proba[i], Eps), 1-Eps)
+		if yTrue[i] == pos {
+			res -= math.Log(p)
+		} else {
+			res -= math.Log(1 - p)
+		}
+	}
+	return res / float64(len(yTrue)), nil
+}
+
+// BrierScore вычисляет оценку Брайера - средний квадрат разности вероятности положительного класса
+// и индикатора того, что объект принадлежит положительному классу.
+// proba - вероятности положительного класса, который задается опцией PosLabel.
+// Принимает значения от 0 до 1, чем меньше значение, тем лучше.
+// Возвращает ошибку в тех же случаях, что и LogLoss.
+func BrierScore(yTrue []int, proba []float64, opts ...Option) (float64, error) {
+	pos, err := checkProba(yTrue, proba, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for i := range yTrue {
+		target := 0.0
+		if yTrue[i] == pos {
+			target = 1
+		}
+		res += (proba[i] - target) * (proba[i] - target)
+	}
+	return res / float64(len(yTrue)), nil
+}
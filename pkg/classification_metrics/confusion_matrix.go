@@ -0,0 +1,258 @@
+package classification_metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normalization тип для способа нормировки матрицы ошибок.
+type Normalization string
+
+const (
+	// NormalizeTrue - каждая строка делится на число объектов настоящего класса,
+	// на диагонали получается полнота классов.
+	NormalizeTrue Normalization = "true"
+	// NormalizePred - каждый столбец делится на число объектов, предсказанных как этот класс,
+	// на диагонали получается точность классов.
+	NormalizePred Normalization = "pred"
+	// NormalizeAll - все элементы делятся на общее число объектов.
+	NormalizeAll Normalization = "all"
+)
+
+// ConfusionMatrixOption задает необязательный параметр матрицы ошибок.
+type ConfusionMatrixOption func(o *confusionMatrixOptions)
+
+// confusionMatrixOptions описывает необязательные параметры матрицы ошибок.
+type confusionMatrixOptions struct {
+	labels []int
+}
+
+// Labels задает метки классов матрицы ошибок и их порядок.
+// Классы, которых нет в данных, получают нулевые строку и столбец, поэтому матрицы ошибок разных частей выборки
+// с одинаковыми метками имеют одинаковый размер и их можно складывать методом Merge.
+// По умолчанию метки - все различные метки из yTrue и yPred в порядке возрастания.
+func Labels(labels ...int) ConfusionMatrixOption {
+	return func(o *confusionMatrixOptions) {
+		o.labels = labels
+	}
+}
+
+// ConfusionMatrix описывает матрицу ошибок многоклассовой (в том числе бинарной) классификации:
+// элемент [i][j] - число объектов класса Labels()[i], предсказанных как класс Labels()[j].
+type ConfusionMatrix struct {
+	labels []int
+	index  map[int]int
+	counts [][]int
+}
+
+// NewConfusionMatrix вычисляет матрицу ошибок по настоящим меткам yTrue и предсказанным меткам yPred.
+// Метки классов задаются опцией Labels.
+// Возвращает ошибку, если длины yTrue и yPred различны, метки в опции Labels повторяются
+// или в yTrue или yPred встречается метка, которой нет среди заданных.
+func NewConfusionMatrix(yTrue []int, yPred []int, opts ...ConfusionMatrixOption) (*ConfusionMatrix, error) {
+	if len(yTrue) != len(yPred) {
+		return nil, fmt.Errorf("yTrue and yPred must have the same length, actual: %d and %d", len(yTrue), len(yPred))
+	}
+	o := confusionMatrixOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	labels := o.labels
+	if labels == nil {
+		labels = UniqueLabels(yTrue, yPred)
+	}
+
+	cm := &ConfusionMatrix{
+		labels: append([]int(nil), labels...),
+		index:  make(map[int]int, len(labels)),
+		counts: make([][]int, len(labels)),
+	}
+	for k, label := range cm.labels {
+		if _, ok := cm.index[label]; ok {
+			return nil, fmt.Errorf("label %d is repeated in labels %v", label, labels)
+		}
+		cm.index[label] = k
+		cm.counts[k] = make([]int, len(labels))
+	}
+
+	for i := range yTrue {
+		trueIdx, ok := cm.index[yTrue[i]]
+		if !ok {
+			return nil, fmt.Errorf("label %d is not present in labels %v", yTrue[i], labels)
+		}
+		predIdx, ok := cm.index[yPred[i]]
+		if !ok {
+			return nil, fmt.Errorf("label %d is not present in labels %v", yPred[i], labels)
+		}
+		cm.counts[trueIdx][predIdx]++
+	}
+
+	return cm, nil
+}
+
+// Labels возвращает метки классов в порядке строк и столбцов матрицы.
+func (cm *ConfusionMatrix) Labels() []int {
+	return append([]int(nil), cm.labels...)
+}
+
+// Counts возвращает копию матрицы ошибок.
+func (cm *ConfusionMatrix) Counts() [][]int {
+	res := make([][]int, len(cm.counts))
+	for i := range cm.counts {
+		res[i] = append([]int(nil), cm.counts[i]...)
+	}
+	return res
+}
+
+// At возвращает число объектов класса trueLabel, предсказанных как класс predLabel.
+// Для неизвестных меток возвращает 0.
+func (cm *ConfusionMatrix) At(trueLabel, predLabel int) int {
+	i, ok := cm.index[trueLabel]
+	if !ok {
+		return 0
+	}
+	j, ok := cm.index[predLabel]
+	if !ok {
+		return 0
+	}
+	return cm.counts[i][j]
+}
+
+// Total возвращает общее число объектов.
+func (cm *ConfusionMatrix) Total() int {
+	res := 0
+	for i := range cm.counts {
+		for j := range cm.counts[i] {
+			res += cm.counts[i][j]
+		}
+	}
+	return res
+}
+
+// TP возвращает число верно предсказанных объектов класса label.
+func (cm *ConfusionMatrix) TP(label int) int {
+	return cm.At(label, label)
+}
+
+// FP возвращает число объектов других классов, предсказанных как класс label.
+func (cm *ConfusionMatrix) FP(label int) int {
+	j, ok := cm.index[label]
+	if !ok {
+		return 0
+	}
+	res := 0
+	for i := range cm.counts {
+		if i != j {
+			res += cm.counts[i][j]
+		}
+	}
+	return res
+}
+
+// FN возвращает число объектов класса label, предсказанных как другой класс.
+func (cm *ConfusionMatrix) FN(label int) int {
+	i, ok := cm.index[label]
+	if !ok {
+		return 0
+	}
+	res := 0
+	for j := range cm.counts[i] {
+		if i != j {
+			res += cm.counts[i][j]
+		}
+	}
+	return res
+}
+
+// TN возвращает число объектов других классов, предсказанных как другой класс,
+// то есть число верно отвергнутых объектов в задаче "класс label против остальных".
+func (cm *ConfusionMatrix) TN(label int) int {
+	return cm.Total() - cm.TP(label) - cm.FP(label) - cm.FN(label)
+}
+
+// Support возвращает число объектов класса label.
+func (cm *ConfusionMatrix) Support(label int) int {
+	return cm.TP(label) + cm.FN(label)
+}
+
+// Normalize возвращает нормированную матрицу ошибок. Строки или столбцы без объектов остаются нулевыми.
+// Возвращает ошибку, если способ нормировки неизвестен.
+func (cm *ConfusionMatrix) Normalize(by Normalization) ([][]float64, error) {
+	n := len(cm.labels)
+	sums := make([]float64, n)
+	switch by {
+	case NormalizeTrue:
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				sums[i] += float64(cm.counts[i][j])
+			}
+		}
+	case NormalizePred:
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				sums[j] += float64(cm.counts[i][j])
+			}
+		}
+	case NormalizeAll:
+	default:
+		return nil, fmt.Errorf("unknown normalization: %s", by)
+	}
+	total := float64(cm.Total())
+
+	res := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		res[i] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			den := total
+			switch by {
+			case NormalizeTrue:
+				den = sums[i]
+			case NormalizePred:
+				den = sums[j]
+			}
+			if den > 0 {
+				res[i][j] = float64(cm.counts[i][j]) / den
+			}
+		}
+	}
+	return res, nil
+}
+
+// Merge поэлементно прибавляет к матрице матрицу other, например, матрицу ошибок другого блока кросс-валидации.
+// Возвращает ошибку, если метки матриц различаются; в этом случае матрица не изменяется.
+func (cm *ConfusionMatrix) Merge(other *ConfusionMatrix) error {
+	if len(cm.labels) != len(other.labels) {
+		return fmt.Errorf("cannot merge confusion matrices with labels %v and %v", cm.labels, other.labels)
+	}
+	for k := range cm.labels {
+		if cm.labels[k] != other.labels[k] {
+			return fmt.Errorf("cannot merge confusion matrices with labels %v and %v", cm.labels, other.labels)
+		}
+	}
+	for i := range cm.counts {
+		for j := range cm.counts[i] {
+			cm.counts[i][j] += other.counts[i][j]
+		}
+	}
+	return nil
+}
+
+// String возвращает матрицу ошибок в виде строки для красивого вывода: элементы строки разделены пробелами.
+func (cm *ConfusionMatrix) String() string {
+	sb := strings.Builder{}
+	for i := range cm.counts {
+		for j := range cm.counts[i] {
+			if j > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(fmt.Sprintf("%d", cm.counts[i][j]))
+		}
+		if i != len(cm.counts)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return fmt.Sprintf(`
+Confusion matrix
+%s
+`, sb.String())
+}
@@ -0,0 +1,214 @@
+package classification_metrics
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewConfusionMatrix(t *testing.T) {
+	type args struct {
+		yTrue []int
+		yPred []int
+		opts  []ConfusionMatrixOption
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantLabels []int
+		want       [][]int
+		wantErr    bool
+	}{
+		{
+			name: "Test default labels with predicted-only label",
+			args: args{
+				yTrue: []int{2, 1, 2, 1},
+				yPred: []int{2, 5, 1, 1},
+			},
+			wantLabels: []int{1, 2, 5},
+			want: [][]int{
+				{1, 0, 1},
+				{1, 1, 0},
+				{0, 0, 0},
+			},
+		},
+		{
+			name: "Test explicit labels order and missing class",
+			args: args{
+				yTrue: []int{2, 1, 2, 1},
+				yPred: []int{2, 1, 1, 1},
+				opts:  []ConfusionMatrixOption{Labels(3, 2, 1)},
+			},
+			wantLabels: []int{3, 2, 1},
+			want: [][]int{
+				{0, 0, 0},
+				{0, 1, 1},
+				{0, 0, 2},
+			},
+		},
+		{
+			name: "Test label not in labels",
+			args: args{
+				yTrue: []int{1, 2},
+				yPred: []int{1, 3},
+				opts:  []ConfusionMatrixOption{Labels(1, 2)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test repeated labels",
+			args: args{
+				yTrue: []int{1, 2},
+				yPred: []int{1, 2},
+				opts:  []ConfusionMatrixOption{Labels(1, 2, 1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test different lengths",
+			args: args{
+				yTrue: []int{1, 2},
+				yPred: []int{1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := NewConfusionMatrix(tt.args.yTrue, tt.args.yPred, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfusionMatrix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cm.Labels(), tt.wantLabels) {
+				t.Errorf("Labels() = %v, want %v", cm.Labels(), tt.wantLabels)
+			}
+			if !reflect.DeepEqual(cm.Counts(), tt.want) {
+				t.Errorf("Counts() = %v, want %v", cm.Counts(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConfusionMatrix_Accessors(t *testing.T) {
+	// Матрица ошибок:
+	// 1 1 0
+	// 0 2 0
+	// 1 0 1
+	cm, err := NewConfusionMatrix([]int{0, 0, 1, 1, 2, 2}, []int{0, 1, 1, 1, 2, 0})
+	if err != nil {
+		t.Fatalf("NewConfusionMatrix() error = %v", err)
+	}
+
+	want := map[int][5]int{
+		0: {1, 1, 1, 3, 2},
+		1: {2, 1, 0, 3, 2},
+		2: {1, 0, 1, 4, 2},
+		7: {0, 0, 0, 6, 0},
+	}
+	for label, w := range want {
+		got := [5]int{cm.TP(label), cm.FP(label), cm.FN(label), cm.TN(label), cm.Support(label)}
+		if got != w {
+			t.Errorf("TP, FP, FN, TN, Support(%d) = %v, want %v", label, got, w)
+		}
+	}
+	if cm.Total() != 6 {
+		t.Errorf("Total() = %d, want 6", cm.Total())
+	}
+	if cm.At(2, 0) != 1 {
+		t.Errorf("At(2, 0) = %d, want 1", cm.At(2, 0))
+	}
+
+	counts := cm.Counts()
+	counts[0][0] = 100
+	if cm.At(0, 0) != 1 {
+		t.Errorf("Counts() must return a copy")
+	}
+}
+
+func TestConfusionMatrix_Normalize(t *testing.T) {
+	// Класс 3 отсутствует в данных, его строка и столбец остаются нулевыми.
+	cm, err := NewConfusionMatrix([]int{1, 1, 2, 2}, []int{1, 2, 2, 2}, Labels(1, 2, 3))
+	if err != nil {
+		t.Fatalf("NewConfusionMatrix() error = %v", err)
+	}
+
+	tests := []struct {
+		by      Normalization
+		want    [][]float64
+		wantErr bool
+	}{
+		{
+			by:   NormalizeTrue,
+			want: [][]float64{{0.5, 0.5, 0}, {0, 1, 0}, {0, 0, 0}},
+		},
+		{
+			by:   NormalizePred,
+			want: [][]float64{{1, 0.333, 0}, {0, 0.667, 0}, {0, 0, 0}},
+		},
+		{
+			by:   NormalizeAll,
+			want: [][]float64{{0.25, 0.25, 0}, {0, 0.5, 0}, {0, 0, 0}},
+		},
+		{
+			by:      "rows",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Test %s", tt.by), func(t *testing.T) {
+			got, err := cm.Normalize(tt.by)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i := range tt.want {
+				for j := range tt.want[i] {
+					if fmt.Sprintf("%.3f", got[i][j]) != fmt.Sprintf("%.3f", tt.want[i][j]) {
+						t.Errorf("Normalize() = %v, want %v", got, tt.want)
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConfusionMatrix_Merge(t *testing.T) {
+	// В первом блоке нет класса 3, во втором - класса 1, но метки заданы явно, поэтому матрицы складываются.
+	first, err := NewConfusionMatrix([]int{1, 2}, []int{1, 1}, Labels(1, 2, 3))
+	if err != nil {
+		t.Fatalf("NewConfusionMatrix() error = %v", err)
+	}
+	second, err := NewConfusionMatrix([]int{2, 3}, []int{2, 2}, Labels(1, 2, 3))
+	if err != nil {
+		t.Fatalf("NewConfusionMatrix() error = %v", err)
+	}
+
+	if err := first.Merge(second); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := [][]int{
+		{1, 0, 0},
+		{1, 1, 0},
+		{0, 1, 0},
+	}
+	if !reflect.DeepEqual(first.Counts(), want) {
+		t.Errorf("Merge() = %v, want %v", first.Counts(), want)
+	}
+
+	other, err := NewConfusionMatrix([]int{2, 3}, []int{2, 2})
+	if err != nil {
+		t.Fatalf("NewConfusionMatrix() error = %v", err)
+	}
+	if err := first.Merge(other); err == nil {
+		t.Errorf("Merge() expected error for different labels")
+	}
+	if !reflect.DeepEqual(first.Counts(), want) {
+		t.Errorf("Merge() with error changed matrix: %v", first.Counts())
+	}
+}
@@ -28,4 +28,60 @@ const (
 	RecallWeighted    ClassificationMetric = "recall_weighted"
 	F1Weighted        ClassificationMetric = "f1_weighted"
 	FBetaWeighted     ClassificationMetric = "f1_beta_weighted"
+
+	// score-based metrics: computed from the decision function, not from predicted labels
+	// binary
+	ROCAUC           ClassificationMetric = "roc_auc"
+	AveragePrecision ClassificationMetric = "average_precision"
+
+	// multiclass
+	ROCAUCOvR         ClassificationMetric = "roc_auc_ovr"
+	ROCAUCOvO         ClassificationMetric = "roc_auc_ovo"
+	ROCAUCOvRWeighted ClassificationMetric = "roc_auc_ovr_weighted"
+	ROCAUCOvOWeighted ClassificationMetric = "roc_auc_ovo_weighted"
+
+	// metrics with the same name for binary and multiclass problems
+	MCC                 ClassificationMetric = "mcc"
+	CohenKappa          ClassificationMetric = "cohen_kappa"
+	CohenKappaQuadratic ClassificationMetric = "cohen_kappa_quadratic"
+	BalancedAccuracy    ClassificationMetric = "balanced_accuracy"
+
+	// binary metrics, for multiclass problems they are averaged
+	Specificity ClassificationMetric = "specificity"
+	NPV         ClassificationMetric = "npv"
+	Jaccard     ClassificationMetric = "jaccard"
+
+	// macro average
+	SpecificityMacro ClassificationMetric = "specificity_macro"
+	NPVMacro         ClassificationMetric = "npv_macro"
+	JaccardMacro     ClassificationMetric = "jaccard_macro"
+
+	// micro average
+	SpecificityMicro ClassificationMetric = "specificity_micro"
+	NPVMicro         ClassificationMetric = "npv_micro"
+	JaccardMicro     ClassificationMetric = "jaccard_micro"
+
+	// weighted average
+	SpecificityWeighted ClassificationMetric = "specificity_weighted"
+	NPVWeighted         ClassificationMetric = "npv_weighted"
+	JaccardWeighted     ClassificationMetric = "jaccard_weighted"
+
+	// probabilistic metrics: computed from the predicted class probabilities, the lower the better
+	LogLoss    ClassificationMetric = "log_loss"
+	BrierScore ClassificationMetric = "brier_score"
 )
+
+// IsScoreBased возвращает true, если метрика вычисляется по значениям решающей функции,
+// а не по предсказанным меткам.
+func IsScoreBased(metric ClassificationMetric) bool {
+	switch metric {
+	case ROCAUC, AveragePrecision, ROCAUCOvR, ROCAUCOvO, ROCAUCOvRWeighted, ROCAUCOvOWeighted:
+		return true
+	}
+	return false
+}
+
+// IsProbabilistic возвращает true, если метрика вычисляется по предсказанным вероятностям классов.
+func IsProbabilistic(metric ClassificationMetric) bool {
+	return metric == LogLoss || metric == BrierScore
+}
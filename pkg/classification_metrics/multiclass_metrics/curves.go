@@ -0,0 +1,131 @@
+package multiclass_metrics
+
+import (
+	"fmt"
+
+	"github.com/ziyadovea/svm/pkg/classification_metrics/binary_metrics"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// Strategy тип для стратегии сведения многоклассовой метрики к бинарным.
+type Strategy string
+
+const (
+	// OvR (One-vs-Rest) - метрика вычисляется для каждого класса против остальных.
+	OvR Strategy = "ovr"
+	// OvO (One-vs-One) - метрика вычисляется для каждой пары классов (Hand, Till, 2001).
+	OvO Strategy = "ovo"
+)
+
+// ROCAUC вычисляет площадь под ROC-кривой для многоклассовой задачи.
+// scores - значения решающей функции или вероятности: каждая строка соответствует объекту,
+// столбцы - классам в порядке classes.
+// Для стратегии OvR вычисляется площадь для каждого класса против остальных по столбцу этого класса,
+// для стратегии OvO - для каждой пары классов a и b среднее площадей "a против b" по столбцу a
+// и "b против a" по столбцу b на объектах этих двух классов.
+// average задает усреднение: Macro - среднее арифметическое, Weighted - среднее, взвешенное долей объектов
+// класса (для OvO - долей объектов пары классов). Микроусреднение не поддерживается.
+// Возвращает ошибку, если размеры входных данных не согласованы, метка из yTrue отсутствует в classes
+// или в yTrue меньше двух классов.
+func ROCAUC(yTrue []int, scores [][]float64, classes []int, strategy Strategy, average Average) (float64, error) {
+	column, err := classColumns(yTrue, scores, classes, "scores")
+	if err != nil {
+		return 0, err
+	}
+	present := vector_operations.GetUniques(yTrue)
+	if len(present) < 2 {
+		return 0, fmt.Errorf("ROC AUC is undefined when yTrue contains less than two classes")
+	}
+	if average != Macro && average != Weighted {
+		return 0, fmt.Errorf("unsupported average for ROC AUC: %s", average)
+	}
+
+	switch strategy {
+	case OvR:
+		return rocAUCOvR(yTrue, scores, column, present, average)
+	case OvO:
+		return rocAUCOvO(yTrue, scores, column, present, average)
+	default:
+		return 0, fmt.Errorf("unknown strategy: %s", strategy)
+	}
+}
+
+// rocAUCOvR вычисляет площадь под ROC-кривой стратегией OvR для классов present.
+// column - номер столбца scores для каждой метки.
+func rocAUCOvR(yTrue []int, scores [][]float64, column map[int]int, present []int, average Average) (float64, error) {
+	classCount := vector_operations.Counter(yTrue)
+	res, sumWeights := 0.0, 0.0
+	for _, class := range present {
+		auc, err := binary_metrics.ROCAUC(oneVsRest(yTrue, class), scoresColumn(scores, column[class]))
+		if err != nil {
+			return 0, fmt.Errorf("class %d: %w", class, err)
+		}
+		weight := 1.0
+		if average == Weighted {
+			weight = float64(classCount[class])
+		}
+		res += weight * auc
+		sumWeights += weight
+	}
+	return res / sumWeights, nil
+}
+
+// rocAUCOvO вычисляет площадь под ROC-кривой стратегией OvO для пар классов из present.
+// column - номер столбца scores для каждой метки.
+func rocAUCOvO(yTrue []int, scores [][]float64, column map[int]int, present []int, average Average) (float64, error) {
+	res, sumWeights := 0.0, 0.0
+	for i := range present {
+		for j := i + 1; j < len(present); j++ {
+			a, b := present[i], present[j]
+
+			// Оставим только объекты классов a и b.
+			yPair := make([]int, 0, len(yTrue))
+			scoresPair := make([][]float64, 0, len(yTrue))
+			for k := range yTrue {
+				if yTrue[k] == a || yTrue[k] == b {
+					yPair = append(yPair, yTrue[k])
+					scoresPair = append(scoresPair, scores[k])
+				}
+			}
+
+			aucA, err := binary_metrics.ROCAUC(oneVsRest(yPair, a), scoresColumn(scoresPair, column[a]))
+			if err != nil {
+				return 0, fmt.Errorf("classes %d and %d: %w", a, b, err)
+			}
+			aucB, err := binary_metrics.ROCAUC(oneVsRest(yPair, b), scoresColumn(scoresPair, column[b]))
+			if err != nil {
+				return 0, fmt.Errorf("classes %d and %d: %w", b, a, err)
+			}
+
+			weight := 1.0
+			if average == Weighted {
+				weight = float64(len(yPair)) / float64(len(yTrue))
+			}
+			res += weight * (aucA + aucB) / 2
+			sumWeights += weight
+		}
+	}
+	return res / sumWeights, nil
+}
+
+// oneVsRest помечает объекты класса class как +1, объекты остальных классов - как -1.
+func oneVsRest(y []int, class int) []int {
+	res := make([]int, len(y))
+	for i := range y {
+		if y[i] == class {
+			res[i] = +1
+		} else {
+			res[i] = -1
+		}
+	}
+	return res
+}
+
+// scoresColumn возвращает столбец k матрицы scores.
+func scoresColumn(scores [][]float64, k int) []float64 {
+	res := make([]float64, len(scores))
+	for i := range scores {
+		res[i] = scores[i][k]
+	}
+	return res
+}
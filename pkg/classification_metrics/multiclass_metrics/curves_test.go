@@ -0,0 +1,99 @@
+package multiclass_metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestROCAUC(t *testing.T) {
+	yTrue := []int{0, 1, 2, 2}
+	scores := [][]float64{
+		{0.8, 0.1, 0.1},
+		{0.3, 0.4, 0.3},
+		{0.2, 0.3, 0.5},
+		{0.1, 0.6, 0.3},
+	}
+	classes := []int{0, 1, 2}
+
+	type args struct {
+		yTrue    []int
+		scores   [][]float64
+		classes  []int
+		strategy Strategy
+		average  Average
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test OvR macro",
+			args: args{yTrue: yTrue, scores: scores, classes: classes, strategy: OvR, average: Macro},
+			want: "0.847",
+		},
+		{
+			name: "Test OvR weighted",
+			args: args{yTrue: yTrue, scores: scores, classes: classes, strategy: OvR, average: Weighted},
+			want: "0.854",
+		},
+		{
+			name: "Test OvO macro",
+			args: args{yTrue: yTrue, scores: scores, classes: classes, strategy: OvO, average: Macro},
+			want: "0.875",
+		},
+		{
+			name: "Test OvO weighted",
+			args: args{yTrue: yTrue, scores: scores, classes: classes, strategy: OvO, average: Weighted},
+			want: "0.859",
+		},
+		{
+			name: "Test columns in another order",
+			args: args{
+				yTrue: yTrue,
+				scores: [][]float64{
+					{0.1, 0.8, 0.1},
+					{0.3, 0.3, 0.4},
+					{0.5, 0.2, 0.3},
+					{0.3, 0.1, 0.6},
+				},
+				classes:  []int{2, 0, 1},
+				strategy: OvR,
+				average:  Macro,
+			},
+			want: "0.847",
+		},
+		{
+			name:    "Test micro average",
+			args:    args{yTrue: yTrue, scores: scores, classes: classes, strategy: OvR, average: Micro},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown label",
+			args:    args{yTrue: []int{0, 1, 2, 3}, scores: scores, classes: classes, strategy: OvR, average: Macro},
+			wantErr: true,
+		},
+		{
+			name:    "Test one class",
+			args:    args{yTrue: []int{2, 2, 2, 2}, scores: scores, classes: classes, strategy: OvO, average: Macro},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown strategy",
+			args:    args{yTrue: yTrue, scores: scores, classes: classes, strategy: "ovx", average: Macro},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ROCAUC(tt.args.yTrue, tt.args.scores, tt.args.classes, tt.args.strategy, tt.args.average)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ROCAUC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", got) != tt.want {
+				t.Errorf("ROCAUC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package multiclass_metrics
+
+import (
+	"math"
+
+	cls_metrics "github.com/ziyadovea/svm/pkg/classification_metrics"
+)
+
+// KappaWeights тип для весов несогласованности в коэффициенте каппа Коэна.
+type KappaWeights string
+
+const (
+	// Unweighted - все несовпадения меток имеют одинаковый вес.
+	Unweighted KappaWeights = "unweighted"
+	// Linear - вес несовпадения пропорционален расстоянию между номерами классов |i - j|.
+	Linear KappaWeights = "linear"
+	// Quadratic - вес несовпадения пропорционален квадрату расстояния между номерами классов (i - j)^2.
+	Quadratic KappaWeights = "quadratic"
+)
+
+// MatthewsCorrCoef вычисляет коэффициент корреляции Мэтьюса для многоклассовой задачи (Gorodkin, 2004):
+// (c*n - sum(p_k*t_k)) / sqrt((n^2 - sum(p_k^2)) * (n^2 - sum(t_k^2))), где c - число верных предсказаний,
+// n - число объектов, p_k и t_k - число предсказанных и настоящих объектов класса k.
+// Для двух классов совпадает с бинарным коэффициентом. Если знаменатель равен нулю, возвращает 0.
+func MatthewsCorrCoef(yTrue []int, yPred []int) float64 {
+	cm, err := cls_metrics.NewConfusionMatrix(yTrue, yPred)
+	if err != nil {
+		return 0.0
+	}
+	labels, counts := cm.Labels(), cm.Counts()
+
+	n, correct := 0.0, 0.0
+	sumPT, sumPP, sumTT := 0.0, 0.0, 0.0
+	for k := range labels {
+		t, p := 0.0, 0.0
+		for j := range labels {
+			t += float64(counts[k][j])
+			p += float64(counts[j][k])
+		}
+		n += t
+		correct += float64(counts[k][k])
+		sumPT += p * t
+		sumPP += p * p
+		sumTT += t * t
+	}
+
+	den := math.Sqrt((n*n - sumPP) * (n*n - sumTT))
+	if den == 0 {
+		return 0.0
+	}
+	return (correct*n - sumPT) / den
+}
+
+// CohenKappa вычисляет коэффициент каппа Коэна для многоклассовой задачи:
+// 1 - sum(w_ij * O_ij) / sum(w_ij * E_ij), где O - матрица ошибок, E - ожидаемая матрица ошибок
+// при независимых yTrue и yPred, w - веса несовпадений, заданные параметром weights.
+// Классы нумеруются в порядке возрастания меток, поэтому взвешенная каппа имеет смысл для упорядоченных меток,
+// например, для степени опасности состояния скважины. Неизвестные веса считаются равными Unweighted.
+// Если ожидаемая несогласованность равна нулю или длины yTrue и yPred различны, возвращает 0.
+func CohenKappa(yTrue []int, yPred []int, weights KappaWeights) float64 {
+	cm, err := cls_metrics.NewConfusionMatrix(yTrue, yPred)
+	if err != nil {
+		return 0.0
+	}
+	labels, counts := cm.Labels(), cm.Counts()
+
+	n := float64(len(yTrue))
+	rows := make([]float64, len(labels))
+	cols := make([]float64, len(labels))
+	for i := range labels {
+		for j := range labels {
+			rows[i] += float64(counts[i][j])
+			cols[j] += float64(counts[i][j])
+		}
+	}
+
+	observed, expected := 0.0, 0.0
+	for i := range labels {
+		for j := range labels {
+			w := 0.0
+			switch weights {
+			case Linear:
+				w = math.Abs(float64(i - j))
+			case Quadratic:
+				w = float64((i - j) * (i - j))
+			default:
+				if i != j {
+					w = 1
+				}
+			}
+			observed += w * float64(counts[i][j])
+			expected += w * rows[i] * cols[j] / n
+		}
+	}
+
+	if expected == 0 {
+		return 0.0
+	}
+	return 1 - observed/expected
+}
+
+// BalancedAccuracy вычисляет сбалансированную точность - среднее значение полноты по классам из yTrue.
+// В отличие от макроусредненной метрики Recall, классы, которые только предсказывались, в среднем не участвуют.
+// Если длины yTrue и yPred различны или они пусты, возвращает 0.
+func BalancedAccuracy(yTrue []int, yPred []int) float64 {
+	cm, err := cls_metrics.NewConfusionMatrix(yTrue, yPred)
+	if err != nil {
+		return 0.0
+	}
+	res, count := 0.0, 0
+	for _, class := range cm.Labels() {
+		if support := cm.Support(class); support > 0 {
+			res += float64(cm.TP(class)) / float64(support)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0.0
+	}
+	return res / float64(count)
+}
+
+// Specificity вычисляет специфичность: для каждого класса - TN / (TN + FP) задачи
+// "класс против остальных", затем значения усредняются в зависимости от параметра average.
+// Метки классов можно задать опцией classification_metrics.Labels.
+func Specificity(yTrue []int, yPred []int, average Average, opts ...cls_metrics.ConfusionMatrixOption) float64 {
+	return averageOneVsRest(yTrue, yPred, average, opts, func(tp, fp, fn, tn float64) (float64, float64) {
+		return tn, tn + fp
+	})
+}
+
+// NegativePredictiveValue вычисляет прогностическую ценность отрицательного результата:
+// для каждого класса - TN / (TN + FN) задачи "класс против остальных",
+// затем значения усредняются в зависимости от параметра average.
+// Метки классов можно задать опцией classification_metrics.Labels.
+func NegativePredictiveValue(yTrue []int, yPred []int, average Average, opts ...cls_metrics.ConfusionMatrixOption) float64 {
+	return averageOneVsRest(yTrue, yPred, average, opts, func(tp, fp, fn, tn float64) (float64, float64) {
+		return tn, tn + fn
+	})
+}
+
+// Jaccard вычисляет индекс Жаккара: для каждого класса - TP / (TP + FP + FN) задачи
+// "класс против остальных", затем значения усредняются в зависимости от параметра average.
+// Метки классов можно задать опцией classification_metrics.Labels.
+func Jaccard(yTrue []int, yPred []int, average Average, opts ...cls_metrics.ConfusionMatrixOption) float64 {
+	return averageOneVsRest(yTrue, yPred, average, opts, func(tp, fp, fn, tn float64) (float64, float64) {
+		return tp, tp + fp + fn
+	})
+}
@@ -0,0 +1,159 @@
+package multiclass_metrics
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtendedMetrics(t *testing.T) {
+	// Матрица ошибок:
+	// 1 1 0
+	// 0 2 0
+	// 1 0 1
+	yTrue := []int{0, 0, 1, 1, 2, 2}
+	yPred := []int{0, 1, 1, 1, 2, 0}
+
+	tests := []struct {
+		name   string
+		metric func(yTrue []int, yPred []int) float64
+		want   string
+	}{
+		{
+			name:   "Test MatthewsCorrCoef",
+			metric: MatthewsCorrCoef,
+			want:   "0.522",
+		},
+		{
+			name: "Test CohenKappa unweighted",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return CohenKappa(yTrue, yPred, Unweighted)
+			},
+			want: "0.500",
+		},
+		{
+			name: "Test CohenKappa linear",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return CohenKappa(yTrue, yPred, Linear)
+			},
+			want: "0.400",
+		},
+		{
+			name: "Test CohenKappa quadratic",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return CohenKappa(yTrue, yPred, Quadratic)
+			},
+			want: "0.286",
+		},
+		{
+			name:   "Test BalancedAccuracy",
+			metric: BalancedAccuracy,
+			want:   "0.667",
+		},
+		{
+			name: "Test Specificity macro",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return Specificity(yTrue, yPred, Macro)
+			},
+			want: "0.833",
+		},
+		{
+			name: "Test NegativePredictiveValue macro",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return NegativePredictiveValue(yTrue, yPred, Macro)
+			},
+			want: "0.850",
+		},
+		{
+			name: "Test NegativePredictiveValue micro",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return NegativePredictiveValue(yTrue, yPred, Micro)
+			},
+			want: "0.833",
+		},
+		{
+			name: "Test Jaccard weighted",
+			metric: func(yTrue []int, yPred []int) float64 {
+				return Jaccard(yTrue, yPred, Weighted)
+			},
+			want: "0.500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metric(yTrue, yPred); fmt.Sprintf("%.3f", got) != tt.want {
+				t.Errorf("metric = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatthewsCorrCoef_PredictedLabelAbsentInTrue(t *testing.T) {
+	// Предсказанная метка 3 отсутствует в yTrue, но учитывается в матрице ошибок.
+	got := MatthewsCorrCoef([]int{0, 1, 2, 2}, []int{0, 1, 2, 3})
+	if fmt.Sprintf("%.3f", got) != "0.730" {
+		t.Errorf("MatthewsCorrCoef() = %v, want 0.730", got)
+	}
+}
+
+func TestProbabilisticMetrics(t *testing.T) {
+	proba := [][]float64{
+		{0.7, 0.2, 0.1},
+		{0.1, 0.8, 0.1},
+		{0.3, 0.3, 0.4},
+	}
+
+	type args struct {
+		yTrue   []int
+		proba   [][]float64
+		classes []int
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantLogLoss    string
+		wantBrierScore string
+		wantErr        bool
+	}{
+		{
+			name:           "Test probabilities",
+			args:           args{yTrue: []int{0, 1, 2}, proba: proba, classes: []int{0, 1, 2}},
+			wantLogLoss:    "0.499",
+			wantBrierScore: "0.247",
+		},
+		{
+			name:           "Test columns in another order",
+			args:           args{yTrue: []int{2, 0, 1}, proba: proba, classes: []int{2, 0, 1}},
+			wantLogLoss:    "0.499",
+			wantBrierScore: "0.247",
+		},
+		{
+			name:    "Test unknown label",
+			args:    args{yTrue: []int{0, 1, 3}, proba: proba, classes: []int{0, 1, 2}},
+			wantErr: true,
+		},
+		{
+			name:    "Test wrong number of columns",
+			args:    args{yTrue: []int{0, 1, 1}, proba: proba, classes: []int{0, 1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logLoss, err := LogLoss(tt.args.yTrue, tt.args.proba, tt.args.classes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogLoss() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", logLoss) != tt.wantLogLoss {
+				t.Errorf("LogLoss() = %v, want %v", logLoss, tt.wantLogLoss)
+			}
+
+			brierScore, err := BrierScore(tt.args.yTrue, tt.args.proba, tt.args.classes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BrierScore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprintf("%.3f", brierScore) != tt.wantBrierScore {
+				t.Errorf("BrierScore() = %v, want %v", brierScore, tt.wantBrierScore)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	cls_metrics "github.com/ziyadovea/svm/pkg/classification_metrics"
 )
 
 func TestGetConfusionMatrix(t *testing.T) {
@@ -71,6 +73,24 @@ Confusion matrix
 2 0 0
 0 0 1
 1 0 2
+`,
+		},
+		{
+			name: "Test predicted-only label",
+			args: args{
+				yTrue: []int{1, 1, 2},
+				yPred: []int{1, 3, 2},
+			},
+			want: [][]int{
+				{1, 0, 1},
+				{0, 1, 0},
+				{0, 0, 0},
+			},
+			want1: `
+Confusion matrix
+1 0 1
+0 1 0
+0 0 0
 `,
 		},
 	}
@@ -120,6 +140,7 @@ func TestPrecision(t *testing.T) {
 		yTrue   []int
 		yPred   []int
 		average Average
+		labels  []int
 	}
 	tests := []struct {
 		name string
@@ -153,10 +174,34 @@ func TestPrecision(t *testing.T) {
 			},
 			want: "0.812",
 		},
+		{
+			name: "Test macro with labels",
+			args: args{
+				yTrue:   []int{2, 1, 3, 2, 4, 5, 3, 5, 2, 4, 2, 4, 3, 2, 4, 2, 4, 2, 2, 1, 4, 2},
+				yPred:   []int{2, 2, 1, 2, 4, 1, 3, 5, 3, 4, 2, 4, 3, 2, 2, 2, 4, 2, 2, 1, 4, 2},
+				average: Macro,
+				labels:  []int{1, 2, 3, 4, 5, 6},
+			},
+			want: "0.633",
+		},
+		{
+			name: "Test label not in labels",
+			args: args{
+				yTrue:   []int{2, 1, 3, 2, 4, 5, 3, 5, 2, 4, 2, 4, 3, 2, 4, 2, 4, 2, 2, 1, 4, 2},
+				yPred:   []int{2, 2, 1, 2, 4, 1, 3, 5, 3, 4, 2, 4, 3, 2, 2, 2, 4, 2, 2, 1, 4, 2},
+				average: Macro,
+				labels:  []int{1, 2, 3, 4},
+			},
+			want: "0.000",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Precision(tt.args.yTrue, tt.args.yPred, tt.args.average); fmt.Sprintf("%.3f", got) != tt.want {
+			var opts []cls_metrics.ConfusionMatrixOption
+			if tt.args.labels != nil {
+				opts = append(opts, cls_metrics.Labels(tt.args.labels...))
+			}
+			if got := Precision(tt.args.yTrue, tt.args.yPred, tt.args.average, opts...); fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("Precision() = %v, want %v", got, tt.want)
 			}
 		})
@@ -168,6 +213,7 @@ func TestRecall(t *testing.T) {
 		yTrue   []int
 		yPred   []int
 		average Average
+		labels  []int
 	}
 	tests := []struct {
 		name string
@@ -201,10 +247,34 @@ func TestRecall(t *testing.T) {
 			},
 			want: "0.773",
 		},
+		{
+			name: "Test macro with labels",
+			args: args{
+				yTrue:   []int{2, 1, 3, 2, 4, 5, 3, 5, 2, 4, 2, 4, 3, 2, 4, 2, 4, 2, 2, 1, 4, 2},
+				yPred:   []int{2, 2, 1, 2, 4, 1, 3, 5, 3, 4, 2, 4, 3, 2, 2, 2, 4, 2, 2, 1, 4, 2},
+				average: Macro,
+				labels:  []int{1, 2, 3, 4, 5, 6},
+			},
+			want: "0.565",
+		},
+		{
+			name: "Test label not in labels",
+			args: args{
+				yTrue:   []int{2, 1, 3, 2, 4, 5, 3, 5, 2, 4, 2, 4, 3, 2, 4, 2, 4, 2, 2, 1, 4, 2},
+				yPred:   []int{2, 2, 1, 2, 4, 1, 3, 5, 3, 4, 2, 4, 3, 2, 2, 2, 4, 2, 2, 1, 4, 2},
+				average: Macro,
+				labels:  []int{1, 2, 3, 4},
+			},
+			want: "0.000",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Recall(tt.args.yTrue, tt.args.yPred, tt.args.average); fmt.Sprintf("%.3f", got) != tt.want {
+			var opts []cls_metrics.ConfusionMatrixOption
+			if tt.args.labels != nil {
+				opts = append(opts, cls_metrics.Labels(tt.args.labels...))
+			}
+			if got := Recall(tt.args.yTrue, tt.args.yPred, tt.args.average, opts...); fmt.Sprintf("%.3f", got) != tt.want {
 				t.Errorf("Recall() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,93 @@
+package multiclass_metrics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ziyadovea/svm/pkg/classification_metrics/binary_metrics"
+)
+
+// classColumns проверяет, что матрица values с названием name согласована с метками yTrue и classes,
+// и возвращает номер столбца values для каждой метки.
+func classColumns(yTrue []int, values [][]float64, classes []int, name string) (map[int]int, error) {
+	if len(yTrue) != len(values) {
+		return nil, fmt.Errorf("yTrue and %s must have the same length, actual: %d and %d", name, len(yTrue), len(values))
+	}
+	column := make(map[int]int, len(classes))
+	for k, class := range classes {
+		column[class] = k
+	}
+	for i := range values {
+		if len(values[i]) != len(classes) {
+			return nil, fmt.Errorf("%s must have %d columns, row %d has %d", name, len(classes), i, len(values[i]))
+		}
+		if _, ok := column[yTrue[i]]; !ok {
+			return nil, fmt.Errorf("label %d is not present in classes %v", yTrue[i], classes)
+		}
+	}
+	return column, nil
+}
+
+// checkProba проверяет вероятности proba так же, как classColumns, а также то, что они лежат на отрезке [0, 1].
+func checkProba(yTrue []int, proba [][]float64, classes []int) (map[int]int, error) {
+	if len(yTrue) == 0 {
+		return nil, fmt.Errorf("yTrue is empty")
+	}
+	column, err := classColumns(yTrue, proba, classes, "proba")
+	if err != nil {
+		return nil, err
+	}
+	for i := range proba {
+		for k, p := range proba[i] {
+			if p < 0 || p > 1 || math.IsNaN(p) {
+				return nil, fmt.Errorf("probability must be in [0, 1], proba[%d][%d] = %v", i, k, p)
+			}
+		}
+	}
+	return column, nil
+}
+
+// LogLoss вычисляет логистическую функцию потерь (кросс-энтропию) для многоклассовой задачи -
+// среднее -log(p) по объектам, где p - предсказанная вероятность настоящего класса объекта.
+// proba - вероятности классов: каждая строка соответствует объекту, столбцы - классам в порядке classes.
+// Вероятности ограничиваются отрезком [binary_metrics.Eps, 1 - binary_metrics.Eps]. Чем меньше значение, тем лучше.
+// Возвращает ошибку, если размеры входных данных не согласованы, метка из yTrue отсутствует в classes
+// или вероятности вне отрезка [0, 1].
+func LogLoss(yTrue []int, proba [][]float64, classes []int) (float64, error) {
+	column, err := checkProba(yTrue, proba, classes)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for i := range yTrue {
+		p := math.Min(math.Max(proba[i][column[yTrue[i]]], binary_metrics.Eps), 1-binary_metrics.Eps)
+		res -= math.Log(p)
+	}
+	return res / float64(len(yTrue)), nil
+}
+
+// BrierScore вычисляет многоклассовую оценку Брайера - среднюю по объектам сумму квадратов разностей
+// вероятностей классов и индикаторов принадлежности объекта этим классам.
+// proba - вероятности классов: каждая строка соответствует объекту, столбцы - классам в порядке classes.
+// Принимает значения от 0 до 2, чем меньше значение, тем лучше. Для двух классов в два раза больше,
+// чем binary_metrics.BrierScore.
+// Возвращает ошибку в тех же случаях, что и LogLoss.
+func BrierScore(yTrue []int, proba [][]float64, classes []int) (float64, error) {
+	column, err := checkProba(yTrue, proba, classes)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+	for i := range yTrue {
+		for k, p := range proba[i] {
+			target := 0.0
+			if k == column[yTrue[i]] {
+				target = 1
+			}
+			res += (p - target) * (p - target)
+		}
+	}
+	return res / float64(len(yTrue)), nil
+}
@@ -0,0 +1,296 @@
+package classification_metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReportFormat тип для формата вывода отчета по метрикам классификации.
+type ReportFormat string
+
+const (
+	// TextFormat - выровненная текстовая таблица.
+	TextFormat ReportFormat = "text"
+	// MarkdownFormat - таблицы Markdown.
+	MarkdownFormat ReportFormat = "markdown"
+	// JSONFormat - объект JSON с отступами.
+	JSONFormat ReportFormat = "json"
+	// CSVFormat - таблица метрик по классам и усреднений с заголовком, без матрицы ошибок.
+	CSVFormat ReportFormat = "csv"
+)
+
+// ClassReport описывает метрики одного класса.
+type ClassReport struct {
+	// Метка класса и ее название для вывода.
+	Label int    `json:"label"`
+	Name  string `json:"name"`
+
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+
+	// Число объектов класса в yTrue.
+	Support int `json:"support"`
+}
+
+// AverageReport описывает усредненные по классам метрики.
+type AverageReport struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+
+	// Общее число объектов.
+	Support int `json:"support"`
+}
+
+// ClassificationReport описывает отчет по метрикам классификации:
+// метрики каждого класса, их микро-, макро- и взвешенные средние, долю верных ответов и матрицу ошибок.
+// Подходит и для бинарной, и для многоклассовой задачи.
+type ClassificationReport struct {
+	// Метрики классов в порядке возрастания меток.
+	Classes []ClassReport `json:"classes"`
+
+	// Микроусреднение: метрики по суммарным числам TP, FP и FN всех классов.
+	Micro AverageReport `json:"micro_avg"`
+	// Макроусреднение: среднее арифметическое метрик классов.
+	Macro AverageReport `json:"macro_avg"`
+	// Взвешенное усреднение: среднее метрик классов, взвешенное числом объектов класса.
+	Weighted AverageReport `json:"weighted_avg"`
+
+	Accuracy float64 `json:"accuracy"`
+
+	// Матрица ошибок: элемент [i][j] - число объектов класса Classes[i], предсказанных как Classes[j].
+	ConfusionMatrix [][]int `json:"confusion_matrix"`
+}
+
+// NewClassificationReport вычисляет отчет по метрикам классификации.
+// В отчет попадают все метки из yTrue и yPred, поэтому класс, который только предсказывался,
+// тоже виден в отчете (с нулевой полнотой и нулевым числом объектов) и участвует в макроусреднении.
+// names задает названия классов для вывода, для меток без названия выводится сама метка; names может быть nil.
+// Возвращает ошибку, если длины yTrue и yPred различны или они пусты.
+func NewClassificationReport(yTrue []int, yPred []int, names map[int]string) (*ClassificationReport, error) {
+	if len(yTrue) == 0 {
+		return nil, fmt.Errorf("yTrue is empty")
+	}
+	matrix, err := NewConfusionMatrix(yTrue, yPred)
+	if err != nil {
+		return nil, err
+	}
+	labels, cm := matrix.Labels(), matrix.Counts()
+
+	r := &ClassificationReport{
+		Classes:         make([]ClassReport, len(labels)),
+		ConfusionMatrix: cm,
+	}
+	n := len(yTrue)
+	tpSum := 0
+	for k, label := range labels {
+		tp, support := matrix.TP(label), matrix.Support(label)
+		predicted := tp + matrix.FP(label)
+		tpSum += tp
+
+		name, ok := names[label]
+		if !ok {
+			name = strconv.Itoa(label)
+		}
+		class := ClassReport{
+			Label:     label,
+			Name:      name,
+			Precision: ratio(tp, predicted),
+			Recall:    ratio(tp, support),
+			Support:   support,
+		}
+		class.F1 = f1(class.Precision, class.Recall)
+		r.Classes[k] = class
+
+		r.Macro.Precision += class.Precision / float64(len(labels))
+		r.Macro.Recall += class.Recall / float64(len(labels))
+		r.Macro.F1 += class.F1 / float64(len(labels))
+		r.Weighted.Precision += class.Precision * float64(support) / float64(n)
+		r.Weighted.Recall += class.Recall * float64(support) / float64(n)
+		r.Weighted.F1 += class.F1 * float64(support) / float64(n)
+	}
+
+	// Каждый объект предсказан ровно одним классом, поэтому суммарные FP и FN равны числу ошибок,
+	// а микроусредненные метрики совпадают с долей верных ответов.
+	r.Accuracy = ratio(tpSum, n)
+	r.Micro.Precision = r.Accuracy
+	r.Micro.Recall = r.Accuracy
+	r.Micro.F1 = r.Accuracy
+	r.Micro.Support, r.Macro.Support, r.Weighted.Support = n, n, n
+
+	return r, nil
+}
+
+// ratio возвращает num / den или 0, если знаменатель равен нулю.
+func ratio(num, den int) float64 {
+	if den == 0 {
+		return 0.0
+	}
+	return float64(num) / float64(den)
+}
+
+// f1 возвращает среднее гармоническое точности и полноты или 0, если они обе равны нулю.
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0.0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+// averages возвращает названия и значения усреднений в порядке вывода.
+func (r *ClassificationReport) averages() ([]string, []AverageReport) {
+	return []string{"micro avg", "macro avg", "weighted avg"}, []AverageReport{r.Micro, r.Macro, r.Weighted}
+}
+
+// Write выводит отчет в формате format.
+// Возвращает ошибку записи или ошибку, если формат неизвестен.
+func (r *ClassificationReport) Write(w io.Writer, format ReportFormat) error {
+	switch format {
+	case TextFormat:
+		_, err := io.WriteString(w, r.String())
+		return err
+	case MarkdownFormat:
+		_, err := io.WriteString(w, r.markdown())
+		return err
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case CSVFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(r.Records()); err != nil {
+			return fmt.Errorf("error writing csv: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+// String возвращает отчет в виде выровненной текстовой таблицы с матрицей ошибок.
+func (r *ClassificationReport) String() string {
+	avgNames, avgs := r.averages()
+	// Ширина считается в символах, так как названия классов могут быть не латиницей.
+	width := len("accuracy")
+	for _, name := range avgNames {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, class := range r.Classes {
+		if l := utf8.RuneCountInString(class.Name); l > width {
+			width = l
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%*s %10s %10s %10s %10s\n\n", width, "", "precision", "recall", "f1-score", "support"))
+	for _, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf("%*s %10.3f %10.3f %10.3f %10d\n",
+			width, class.Name, class.Precision, class.Recall, class.F1, class.Support))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("%*s %10s %10s %10.3f %10d\n", width, "accuracy", "", "", r.Accuracy, r.Micro.Support))
+	for k, avg := range avgs {
+		sb.WriteString(fmt.Sprintf("%*s %10.3f %10.3f %10.3f %10d\n",
+			width, avgNames[k], avg.Precision, avg.Recall, avg.F1, avg.Support))
+	}
+
+	// Ширина столбца матрицы ошибок - по самому длинному названию класса или числу.
+	cellWidth := 0
+	for k, class := range r.Classes {
+		if l := utf8.RuneCountInString(class.Name); l > cellWidth {
+			cellWidth = l
+		}
+		for _, elem := range r.ConfusionMatrix[k] {
+			if l := len(strconv.Itoa(elem)); l > cellWidth {
+				cellWidth = l
+			}
+		}
+	}
+	sb.WriteString("\nConfusion matrix (rows - true labels, columns - predicted labels)\n")
+	sb.WriteString(fmt.Sprintf("%*s", width, ""))
+	for _, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf(" %*s", cellWidth, class.Name))
+	}
+	sb.WriteString("\n")
+	for k, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf("%*s", width, class.Name))
+		for _, elem := range r.ConfusionMatrix[k] {
+			sb.WriteString(fmt.Sprintf(" %*d", cellWidth, elem))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// markdown возвращает отчет в виде таблиц Markdown: метрик и матрицы ошибок.
+func (r *ClassificationReport) markdown() string {
+	avgNames, avgs := r.averages()
+
+	sb := strings.Builder{}
+	sb.WriteString("| class | precision | recall | f1-score | support |\n")
+	sb.WriteString("|---|---:|---:|---:|---:|\n")
+	for _, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf("| %s | %.3f | %.3f | %.3f | %d |\n",
+			markdownEscape(class.Name), class.Precision, class.Recall, class.F1, class.Support))
+	}
+	sb.WriteString(fmt.Sprintf("| accuracy | | | %.3f | %d |\n", r.Accuracy, r.Micro.Support))
+	for k, avg := range avgs {
+		sb.WriteString(fmt.Sprintf("| %s | %.3f | %.3f | %.3f | %d |\n",
+			avgNames[k], avg.Precision, avg.Recall, avg.F1, avg.Support))
+	}
+
+	sb.WriteString("\nConfusion matrix (rows - true labels, columns - predicted labels)\n\n")
+	sb.WriteString("| true \\ predicted |")
+	for _, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf(" %s |", markdownEscape(class.Name)))
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---:|", len(r.Classes)))
+	sb.WriteString("\n")
+	for k, class := range r.Classes {
+		sb.WriteString(fmt.Sprintf("| %s |", markdownEscape(class.Name)))
+		for _, elem := range r.ConfusionMatrix[k] {
+			sb.WriteString(fmt.Sprintf(" %d |", elem))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// markdownEscape экранирует символ "|", чтобы название класса не разбивало ячейку таблицы.
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// Records возвращает отчет в виде строк таблицы CSV: заголовок, метрики классов, доля верных ответов и усреднения.
+// У доли верных ответов заполнены только столбцы f1 и support.
+// Первый столбец - название класса или усреднения.
+func (r *ClassificationReport) Records() [][]string {
+	avgNames, avgs := r.averages()
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	res := make([][]string, 0, len(r.Classes)+len(avgs)+2)
+	res = append(res, []string{"class", "precision", "recall", "f1", "support"})
+	for _, class := range r.Classes {
+		res = append(res, []string{class.Name, format(class.Precision), format(class.Recall), format(class.F1),
+			strconv.Itoa(class.Support)})
+	}
+	res = append(res, []string{"accuracy", "", "", format(r.Accuracy), strconv.Itoa(r.Micro.Support)})
+	for k, avg := range avgs {
+		res = append(res, []string{avgNames[k], format(avg.Precision), format(avg.Recall), format(avg.F1),
+			strconv.Itoa(avg.Support)})
+	}
+	return res
+}
@@ -0,0 +1,177 @@
+package classification_metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewClassificationReport(t *testing.T) {
+	// Матрица ошибок:
+	// 1 1 0
+	// 0 2 0
+	// 1 0 1
+	yTrue := []int{0, 0, 1, 1, 2, 2}
+	yPred := []int{0, 1, 1, 1, 2, 0}
+
+	r, err := NewClassificationReport(yTrue, yPred, map[int]string{0: "норма"})
+	if err != nil {
+		t.Fatalf("NewClassificationReport() error = %v", err)
+	}
+
+	wantClasses := []string{
+		"0 норма 0.500 0.500 0.500 2",
+		"1 1 0.667 1.000 0.800 2",
+		"2 2 1.000 0.500 0.667 2",
+	}
+	if len(r.Classes) != len(wantClasses) {
+		t.Fatalf("len(Classes) = %d, want %d", len(r.Classes), len(wantClasses))
+	}
+	for k, class := range r.Classes {
+		got := fmt.Sprintf("%d %s %.3f %.3f %.3f %d",
+			class.Label, class.Name, class.Precision, class.Recall, class.F1, class.Support)
+		if got != wantClasses[k] {
+			t.Errorf("Classes[%d] = %s, want %s", k, got, wantClasses[k])
+		}
+	}
+
+	averages := map[string]AverageReport{
+		"micro":    r.Micro,
+		"macro":    r.Macro,
+		"weighted": r.Weighted,
+	}
+	wantAverages := map[string]string{
+		"micro":    "0.667 0.667 0.667 6",
+		"macro":    "0.722 0.667 0.656 6",
+		"weighted": "0.722 0.667 0.656 6",
+	}
+	for name, avg := range averages {
+		got := fmt.Sprintf("%.3f %.3f %.3f %d", avg.Precision, avg.Recall, avg.F1, avg.Support)
+		if got != wantAverages[name] {
+			t.Errorf("%s average = %s, want %s", name, got, wantAverages[name])
+		}
+	}
+	if fmt.Sprintf("%.3f", r.Accuracy) != "0.667" {
+		t.Errorf("Accuracy = %v, want 0.667", r.Accuracy)
+	}
+	wantCm := [][]int{{1, 1, 0}, {0, 2, 0}, {1, 0, 1}}
+	if !reflect.DeepEqual(r.ConfusionMatrix, wantCm) {
+		t.Errorf("ConfusionMatrix = %v, want %v", r.ConfusionMatrix, wantCm)
+	}
+}
+
+func TestNewClassificationReport_PredictedOnlyLabel(t *testing.T) {
+	// Метка 3 только предсказывалась, но попадает в отчет и в макроусреднение.
+	r, err := NewClassificationReport([]int{1, 1, 2}, []int{1, 3, 2}, nil)
+	if err != nil {
+		t.Fatalf("NewClassificationReport() error = %v", err)
+	}
+	last := r.Classes[len(r.Classes)-1]
+	if last.Label != 3 || last.Support != 0 || last.Precision != 0 {
+		t.Errorf("Classes[last] = %+v, want label 3 with zero support and precision", last)
+	}
+	if fmt.Sprintf("%.3f", r.Macro.Recall) != "0.500" {
+		t.Errorf("Macro.Recall = %v, want 0.500", r.Macro.Recall)
+	}
+
+	if _, err := NewClassificationReport([]int{1, 2}, []int{1}, nil); err == nil {
+		t.Errorf("NewClassificationReport() expected error for different lengths")
+	}
+}
+
+func TestClassificationReport_Write(t *testing.T) {
+	r, err := NewClassificationReport([]int{0, 0, 1, 1}, []int{0, 1, 1, 1}, map[int]string{1: "авария"})
+	if err != nil {
+		t.Fatalf("NewClassificationReport() error = %v", err)
+	}
+
+	t.Run("Test text", func(t *testing.T) {
+		want := `              precision     recall   f1-score    support
+
+           0      1.000      0.500      0.667          2
+      авария      0.667      1.000      0.800          2
+
+    accuracy                            0.750          4
+   micro avg      0.750      0.750      0.750          4
+   macro avg      0.833      0.750      0.733          4
+weighted avg      0.833      0.750      0.733          4
+
+Confusion matrix (rows - true labels, columns - predicted labels)
+                  0 авария
+           0      1      1
+      авария      0      2
+`
+		var buf bytes.Buffer
+		if err := r.Write(&buf, TextFormat); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if buf.String() != want {
+			t.Errorf("Write() = \n%s, want \n%s", buf.String(), want)
+		}
+	})
+
+	t.Run("Test markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.Write(&buf, MarkdownFormat); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		for _, line := range []string{
+			"| авария | 0.667 | 1.000 | 0.800 | 2 |",
+			"| accuracy | | | 0.750 | 4 |",
+			"| true \\ predicted | 0 | авария |",
+			"| 0 | 1 | 1 |",
+		} {
+			if !strings.Contains(buf.String(), line+"\n") {
+				t.Errorf("Write() = \n%s, want line %s", buf.String(), line)
+			}
+		}
+	})
+
+	t.Run("Test json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.Write(&buf, JSONFormat); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		var got ClassificationReport
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if !reflect.DeepEqual(&got, r) {
+			t.Errorf("json.Unmarshal() = %+v, want %+v", got, *r)
+		}
+	})
+
+	t.Run("Test csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := r.Write(&buf, CSVFormat); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("csv.ReadAll() error = %v", err)
+		}
+		if !reflect.DeepEqual(records, r.Records()) {
+			t.Errorf("csv records = %v, want %v", records, r.Records())
+		}
+		want := [][]string{
+			{"class", "precision", "recall", "f1", "support"},
+			{"0", "1", "0.5", "0.6666666666666666", "2"},
+		}
+		if !reflect.DeepEqual(records[:2], want) {
+			t.Errorf("csv records = %v, want prefix %v", records, want)
+		}
+		if len(records) != 7 || records[3][0] != "accuracy" || records[3][3] != "0.75" {
+			t.Errorf("csv records = %v, want accuracy row and three averages", records)
+		}
+	})
+
+	t.Run("Test unknown format", func(t *testing.T) {
+		if err := r.Write(&bytes.Buffer{}, "xml"); err == nil {
+			t.Errorf("Write() expected error for unknown format")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package classification_metrics
+
+import "sort"
+
+// TargetType тип задачи классификации, определяемый по меткам классов.
+type TargetType string
+
+const (
+	// Binary - задача с не более чем двумя различными метками.
+	Binary TargetType = "binary"
+	// Multiclass - задача с тремя и более различными метками.
+	Multiclass TargetType = "multiclass"
+)
+
+// UniqueLabels возвращает отсортированные различные метки, встречающиеся хотя бы в одном из слайсов ys.
+func UniqueLabels(ys ...[]int) []int {
+	set := make(map[int]struct{})
+	for _, y := range ys {
+		for _, label := range y {
+			set[label] = struct{}{}
+		}
+	}
+
+	res := make([]int, 0, len(set))
+	for label := range set {
+		res = append(res, label)
+	}
+	sort.Ints(res)
+	return res
+}
+
+// TypeOfTarget определяет тип задачи по меткам из слайсов ys (например, настоящим и предсказанным).
+// Задача бинарная, если различных меток не больше двух, при этом сами значения меток не важны:
+// {0, 1}, {-1, +1} и {3, 7} - одинаково бинарные задачи.
+func TypeOfTarget(ys ...[]int) TargetType {
+	if len(UniqueLabels(ys...)) <= 2 {
+		return Binary
+	}
+	return Multiclass
+}
@@ -1,7 +1,9 @@
 package cross_validation
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"sort"
 	"sync"
 
@@ -16,40 +18,149 @@ import (
 // KFoldCVScore реализует K-fold кросс валидацию.
 // Возвращает мапу, где ключ - это метрика, значение - слайс значений этой метрики для каждого из разбиений.
 // Не поддерживает метрику F beta score.
+// Метрики ROC AUC и average precision вычисляются по значениям решающей функции, поэтому требуют
+// классификатор, реализующий svm.Scorer, а метрики log-loss и оценка Брайера вычисляются по вероятностям классов
+// и требуют классификатор, реализующий svm.ProbabilisticClassifier.
+// Для классификатора с предвычисленным ядром (svm.PairwiseClassifier) x - матрица Грама,
+// которая разбивается функцией KFoldCVPairwise.
 func KFoldCVScore(cls svm.Classifier, x [][]float64, y []int, nSplits int,
 	metrics ...cls_metrics.ClassificationMetric) (map[cls_metrics.ClassificationMetric][]float64, error) {
-	if nSplits < 2 {
-		return nil, fmt.Errorf("nSplits must be at least 2, actual: %d", nSplits)
+	return KFoldCVScoreContext(context.Background(), cls, x, y, nSplits, metrics...)
+}
+
+// KFoldCVScoreContext реализует K-fold кросс валидацию так же, как KFoldCVScore.
+// Если контекст отменен или истек его срок, то обучение на всех разбиениях прерывается
+// и возвращается ошибка контекста. Ошибка обучения на одном разбиении также прерывает остальные.
+// Классификаторы, реализующие svm.ContextClassifier, прерываются во время обучения,
+// остальные - перед его началом.
+func KFoldCVScoreContext(ctx context.Context, cls svm.Classifier, x [][]float64, y []int, nSplits int,
+	metrics ...cls_metrics.ClassificationMetric) (map[cls_metrics.ClassificationMetric][]float64, error) {
+	return NewKFold(nSplits).Score(ctx, cls, x, y, metrics...)
+}
+
+// KFold описывает разбиение выборки на NSplits блоков для K-fold кросс-валидации.
+type KFold struct {
+	// Количество блоков.
+	NSplits int
+
+	// Перемешивать ли объекты перед разбиением.
+	// Без перемешивания тестовые части идут подряд в исходном порядке объектов.
+	Shuffle bool
+
+	// Зерно генератора случайных чисел для перемешивания. При одинаковом зерне разбиение одинаково.
+	// Если задать то же зерно, что и у классификатора, то вся кросс-валидация воспроизводима.
+	Seed int64
+}
+
+// NewKFold возвращает разбиение на nSplits блоков без перемешивания.
+func NewKFold(nSplits int) *KFold {
+	return &KFold{
+		NSplits: nSplits,
+		Shuffle: false,
+		Seed:    1,
 	}
+}
 
-	// Проверим, является ли задача бинарной
-	isBinary := vector_operations.IsBinary(y)
+// Score реализует K-fold кросс валидацию так же, как KFoldCVScoreContext, но с разбиением k.
+// Значения метрики в результате идут в порядке блоков, поэтому при одинаковом разбиении
+// и воспроизводимом обучении результат одинаков.
+func (k *KFold) Score(ctx context.Context, cls svm.Classifier, x [][]float64, y []int,
+	metrics ...cls_metrics.ClassificationMetric) (map[cls_metrics.ClassificationMetric][]float64, error) {
+	if k.NSplits < 2 {
+		return nil, fmt.Errorf("nSplits must be at least 2, actual: %d", k.NSplits)
+	}
+
+	// Проверим, является ли задача бинарной. Значения меток не важны, важно лишь их количество.
+	isBinary := cls_metrics.TypeOfTarget(y) == cls_metrics.Binary
+	// Положительный класс бинарной задачи определим по всей выборке, так как в тестовой части разбиения
+	// может оказаться только один класс.
+	posLabel := 0
+	if isBinary {
+		posLabel, _ = binary_metrics.DefaultPosLabel(y)
+	}
 	// Далее профильтруем метрики в зависимости от типа задачи
 	filteredMetrics := filterMetrics(isBinary, metrics...)
+	labels := cls_metrics.UniqueLabels(y)
 
-	res := make(map[cls_metrics.ClassificationMetric][]float64)
-	cvData := KFoldCV(x, y, nSplits)
+	// Метрики по значениям решающей функции вычисляются только для классификатора, реализующего svm.Scorer.
+	needScores := false
+	for _, metric := range filteredMetrics {
+		if cls_metrics.IsScoreBased(metric) {
+			needScores = true
+		}
+	}
+	if _, ok := cls.(svm.Scorer); needScores && !ok {
+		return nil, fmt.Errorf("score-based metrics require a classifier that implements svm.Scorer")
+	}
+	// Метрики по вероятностям классов вычисляются только для классификатора, реализующего svm.ProbabilisticClassifier.
+	needProba := false
+	for _, metric := range filteredMetrics {
+		if cls_metrics.IsProbabilistic(metric) {
+			needProba = true
+		}
+	}
+	if _, ok := cls.(svm.ProbabilisticClassifier); needProba && !ok {
+		return nil, fmt.Errorf("probabilistic metrics require a classifier that implements svm.ProbabilisticClassifier")
+	}
 
-	eg := new(errgroup.Group)
+	res := make(map[cls_metrics.ClassificationMetric][]float64, len(filteredMetrics))
+	for _, metric := range filteredMetrics {
+		res[metric] = make([]float64, k.NSplits)
+	}
+	var cvData []CVData
+	if pc, ok := cls.(svm.PairwiseClassifier); ok && pc.Pairwise() {
+		for i := range x {
+			if len(x[i]) != len(y) {
+				return nil, fmt.Errorf("precomputed kernel matrix must be %d x %d, row %d has %d columns",
+					len(y), len(y), i, len(x[i]))
+			}
+		}
+		cvData = k.SplitPairwise(x, y)
+	} else {
+		cvData = k.Split(x, y)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
 	mu := sync.Mutex{}
-	for _, data := range cvData {
-		data := data
+	for i, data := range cvData {
+		i, data := i, data
 		cls, err := cls.Clone()
 		if err != nil {
 			return nil, err
 		}
 
 		eg.Go(func() error {
-			err := cls.Fit(data.XTrain, data.YTrain)
-			if err != nil {
+			if err := fitContext(ctx, cls, data.XTrain, data.YTrain); err != nil {
 				return err
 			}
 
-			yPred := cls.Predict(data.XTest)
+			prediction := foldPrediction{
+				yTrue:    data.YTest,
+				yPred:    cls.Predict(data.XTest),
+				labels:   labels,
+				binary:   isBinary,
+				posLabel: posLabel,
+			}
+			if needScores || needProba {
+				prediction.classes = scoreClasses(cls, data.YTrain)
+			}
+			if needScores {
+				prediction.scores = scoreColumns(cls.(svm.Scorer).DecisionFunction(data.XTest), len(prediction.classes))
+			}
+			if needProba {
+				prediction.proba = cls.(svm.ProbabilisticClassifier).PredictProba(data.XTest)
+				if prediction.proba == nil {
+					return fmt.Errorf("classifier returned no probabilities, it may be fitted without probability estimates")
+				}
+			}
 
 			for _, metric := range filteredMetrics {
+				value, err := calculateMetric(prediction, metric)
+				if err != nil {
+					return fmt.Errorf("error in calculating the %s metric: %w", metric, err)
+				}
 				mu.Lock()
-				res[metric] = append(res[metric], calculateMetric(data.YTest, yPred, metric))
+				res[metric][i] = value
 				mu.Unlock()
 			}
 
@@ -64,6 +175,17 @@ func KFoldCVScore(cls svm.Classifier, x [][]float64, y []int, nSplits int,
 	return res, nil
 }
 
+// fitContext обучает классификатор cls с учетом контекста ctx.
+func fitContext(ctx context.Context, cls svm.Classifier, x [][]float64, y []int) error {
+	if cc, ok := cls.(svm.ContextClassifier); ok {
+		return cc.FitContext(ctx, x, y)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return cls.Fit(x, y)
+}
+
 // CVData описывает набор данных, где xTrain и yTrain - данные для обучения, xTest и yTest - для валидации.
 // Используется для k-fold кросс-валидации.
 type CVData struct {
@@ -75,76 +197,314 @@ type CVData struct {
 
 // KFoldCV возвращает слайс из наборов данных для обучения и валидации.
 func KFoldCV(x [][]float64, y []int, nSplits int) []CVData {
-	res := make([]CVData, nSplits)
-	nSamples := len(y)
+	return NewKFold(nSplits).Split(x, y)
+}
 
-	// Надо узнать, сколько элементов у нас будет в тестовой выборке
-	testSize := nSamples / nSplits
-	beginTestSample := 0
-	endTestSample := testSize - 1
+// Split возвращает слайс из наборов данных для обучения и валидации для разбиения k.
+func (k *KFold) Split(x [][]float64, y []int) []CVData {
+	res := make([]CVData, k.NSplits)
+	for i, split := range k.splits(len(y)) {
+		cvData := CVData{
+			XTrain: make([][]float64, 0, len(split.train)),
+			YTrain: make([]int, 0, len(split.train)),
+			XTest:  make([][]float64, 0, len(split.test)),
+			YTest:  make([]int, 0, len(split.test)),
+		}
+		for _, j := range split.train {
+			cvData.XTrain = append(cvData.XTrain, x[j])
+			cvData.YTrain = append(cvData.YTrain, y[j])
+		}
+		for _, j := range split.test {
+			cvData.XTest = append(cvData.XTest, x[j])
+			cvData.YTest = append(cvData.YTest, y[j])
+		}
+		res[i] = cvData
+	}
 
-	for i := 0; i < nSplits; i++ {
+	return res
+}
+
+// KFoldCVPairwise возвращает слайс из наборов данных для обучения и валидации
+// для классификатора с предвычисленным ядром.
+// gram - матрица Грама n x n всей выборки. В каждом разбиении XTrain - матрица Грама обучающей части,
+// XTest - значения ядра между объектами тестовой и обучающей частей.
+func KFoldCVPairwise(gram [][]float64, y []int, nSplits int) []CVData {
+	return NewKFold(nSplits).SplitPairwise(gram, y)
+}
+
+// SplitPairwise возвращает слайс из наборов данных для обучения и валидации
+// для классификатора с предвычисленным ядром так же, как KFoldCVPairwise, но для разбиения k.
+func (k *KFold) SplitPairwise(gram [][]float64, y []int) []CVData {
+	res := make([]CVData, k.NSplits)
+	for i, split := range k.splits(len(y)) {
 		cvData := CVData{
-			XTrain: make([][]float64, 0, nSamples-testSize),
-			YTrain: make([]int, 0, nSamples-testSize),
-			XTest:  make([][]float64, 0, testSize),
-			YTest:  make([]int, 0, testSize),
+			XTrain: make([][]float64, 0, len(split.train)),
+			YTrain: make([]int, 0, len(split.train)),
+			XTest:  make([][]float64, 0, len(split.test)),
+			YTest:  make([]int, 0, len(split.test)),
+		}
+		for _, j := range split.train {
+			cvData.XTrain = append(cvData.XTrain, gramRow(gram[j], split.train))
+			cvData.YTrain = append(cvData.YTrain, y[j])
+		}
+		for _, j := range split.test {
+			cvData.XTest = append(cvData.XTest, gramRow(gram[j], split.train))
+			cvData.YTest = append(cvData.YTest, y[j])
+		}
+		res[i] = cvData
+	}
+
+	return res
+}
+
+// kFoldSplit описывает индексы объектов обучающей и тестовой частей одного разбиения.
+type kFoldSplit struct {
+	train []int
+	test  []int
+}
+
+// splits возвращает индексы объектов для каждого из k.NSplits разбиений выборки размера nSamples.
+// Тестовые части имеют размер nSamples / k.NSplits и идут подряд в порядке объектов
+// (при перемешивании - в случайном порядке с зерном k.Seed). Оставшиеся в конце этого порядка объекты
+// всегда попадают в обучающую часть. Внутри частей объекты идут в исходном порядке.
+func (k *KFold) splits(nSamples int) []kFoldSplit {
+	res := make([]kFoldSplit, k.NSplits)
+
+	// Надо узнать, сколько элементов у нас будет в тестовой выборке
+	testSize := nSamples / k.NSplits
+
+	// Для каждого объекта определим номер блока, в котором он попадает в тестовую часть.
+	order := make([]int, nSamples)
+	for j := range order {
+		order[j] = j
+	}
+	if k.Shuffle {
+		rnd := rand.New(rand.NewSource(k.Seed))
+		rnd.Shuffle(nSamples, func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	testFold := make([]int, nSamples)
+	for pos, j := range order {
+		testFold[j] = -1
+		if testSize > 0 && pos < testSize*k.NSplits {
+			testFold[j] = pos / testSize
+		}
+	}
+
+	for i := 0; i < k.NSplits; i++ {
+		split := kFoldSplit{
+			train: make([]int, 0, nSamples-testSize),
+			test:  make([]int, 0, testSize),
 		}
 		for j := 0; j < nSamples; j++ {
-			if beginTestSample <= j && j <= endTestSample {
-				cvData.XTest = append(cvData.XTest, x[j])
-				cvData.YTest = append(cvData.YTest, y[j])
+			if testFold[j] == i {
+				split.test = append(split.test, j)
 			} else {
-				cvData.XTrain = append(cvData.XTrain, x[j])
-				cvData.YTrain = append(cvData.YTrain, y[j])
+				split.train = append(split.train, j)
 			}
-
 		}
-		beginTestSample = endTestSample + 1
-		endTestSample += testSize
-		res[i] = cvData
+		res[i] = split
 	}
 
 	return res
 }
 
-// Возвращает значение метрики для yTrue и yPred.
-func calculateMetric(yTrue, yPred []int, metric cls_metrics.ClassificationMetric) float64 {
+// gramRow возвращает значения строки матрицы ядра в столбцах cols.
+func gramRow(row []float64, cols []int) []float64 {
+	res := make([]float64, len(cols))
+	for k, j := range cols {
+		res[k] = row[j]
+	}
+	return res
+}
+
+// foldPrediction описывает предсказания классификатора на тестовой части одного разбиения.
+type foldPrediction struct {
+	yTrue []int
+	yPred []int
+	// Метки классов всей выборки.
+	labels []int
+
+	// Является ли задача бинарной и метка положительного класса бинарной задачи.
+	binary   bool
+	posLabel int
+
+	// Значения решающей функции, вероятности классов и метки классов в порядке их столбцов.
+	// Равны nil, если метрики по значениям решающей функции или по вероятностям не вычисляются.
+	scores  [][]float64
+	proba   [][]float64
+	classes []int
+}
+
+// scoreClasses возвращает метки классов в порядке столбцов DecisionFunction и PredictProba классификатора cls.
+// Если классификатор не сообщает их методом Classes, то это отсортированные метки обучающей выборки yTrain.
+func scoreClasses(cls svm.Classifier, yTrain []int) []int {
+	if c, ok := cls.(interface{ Classes() []int }); ok {
+		return c.Classes()
+	}
+	return vector_operations.GetUniques(yTrain)
+}
+
+// scoreColumns возвращает значения решающей функции scores по одному столбцу на каждый из nClasses классов.
+// Бинарный классификатор возвращает одно значение s для класса с большей меткой,
+// оно заменяется парой значений (-s, s).
+func scoreColumns(scores [][]float64, nClasses int) [][]float64 {
+	if nClasses != 2 {
+		return scores
+	}
+	res := make([][]float64, len(scores))
+	for i := range scores {
+		res[i] = scores[i]
+		if len(scores[i]) == 1 {
+			res[i] = []float64{-scores[i][0], scores[i][0]}
+		}
+	}
+	return res
+}
+
+// positiveColumn возвращает столбец положительного класса p.posLabel матрицы values,
+// столбцы которой соответствуют классам p.classes (значения решающей функции или вероятности).
+func positiveColumn(p foldPrediction, values [][]float64) ([]float64, error) {
+	column := -1
+	for k, class := range p.classes {
+		if class == p.posLabel {
+			column = k
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("positive label %d is not present in classifier classes %v", p.posLabel, p.classes)
+	}
+
+	res := make([]float64, len(values))
+	for i := range values {
+		if len(values[i]) != len(p.classes) {
+			return nil, fmt.Errorf("classifier output must have %d columns, actual: %d",
+				len(p.classes), len(values[i]))
+		}
+		res[i] = values[i][column]
+	}
+	return res, nil
+}
+
+// Возвращает значение метрики для предсказаний p.
+func calculateMetric(p foldPrediction, metric cls_metrics.ClassificationMetric) (float64, error) {
+	yTrue, yPred := p.yTrue, p.yPred
+	// Усредненные по классам метрики вычисляются по всем классам выборки, а не только по классам
+	// тестовой части разбиения, чтобы значения метрики на разных блоках были сравнимы.
+	labels := cls_metrics.Labels(cls_metrics.UniqueLabels(p.labels, yPred)...)
 	res := 0.0
 	switch metric {
 	case cls_metrics.Accuracy:
 		res = multiclass_metrics.Accuracy(yTrue, yPred)
 	case cls_metrics.Precision:
-		res = binary_metrics.Precision(yTrue, yPred)
+		return binary_metrics.Precision(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
 	case cls_metrics.Recall:
-		res = binary_metrics.Recall(yTrue, yPred)
+		return binary_metrics.Recall(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
 	case cls_metrics.F1:
-		res = binary_metrics.FScore(yTrue, yPred)
+		return binary_metrics.FScore(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
 	case cls_metrics.PrecisionMacro:
-		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Macro)
+		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Macro, labels)
 	case cls_metrics.RecallMacro:
-		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Macro)
+		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Macro, labels)
 	case cls_metrics.F1Macro:
-		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Macro)
+		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Macro, labels)
 	case cls_metrics.PrecisionMicro:
-		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Micro)
+		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Micro, labels)
 	case cls_metrics.RecallMicro:
-		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Micro)
+		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Micro, labels)
 	case cls_metrics.F1Micro:
-		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Micro)
+		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Micro, labels)
 	case cls_metrics.PrecisionWeighted:
-		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Weighted)
+		res = multiclass_metrics.Precision(yTrue, yPred, multiclass_metrics.Weighted, labels)
 	case cls_metrics.RecallWeighted:
-		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Weighted)
+		res = multiclass_metrics.Recall(yTrue, yPred, multiclass_metrics.Weighted, labels)
 	case cls_metrics.F1Weighted:
-		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Weighted)
+		res = multiclass_metrics.FScore(yTrue, yPred, multiclass_metrics.Weighted, labels)
+	case cls_metrics.ROCAUC, cls_metrics.AveragePrecision:
+		scores, err := positiveColumn(p, p.scores)
+		if err != nil {
+			return 0, err
+		}
+		if metric == cls_metrics.ROCAUC {
+			return binary_metrics.ROCAUC(yTrue, scores, binary_metrics.PosLabel(p.posLabel))
+		}
+		return binary_metrics.AveragePrecision(yTrue, scores, binary_metrics.PosLabel(p.posLabel))
+	case cls_metrics.ROCAUCOvR:
+		return multiclass_metrics.ROCAUC(yTrue, p.scores, p.classes, multiclass_metrics.OvR, multiclass_metrics.Macro)
+	case cls_metrics.ROCAUCOvO:
+		return multiclass_metrics.ROCAUC(yTrue, p.scores, p.classes, multiclass_metrics.OvO, multiclass_metrics.Macro)
+	case cls_metrics.ROCAUCOvRWeighted:
+		return multiclass_metrics.ROCAUC(yTrue, p.scores, p.classes, multiclass_metrics.OvR, multiclass_metrics.Weighted)
+	case cls_metrics.ROCAUCOvOWeighted:
+		return multiclass_metrics.ROCAUC(yTrue, p.scores, p.classes, multiclass_metrics.OvO, multiclass_metrics.Weighted)
+	case cls_metrics.MCC:
+		if p.binary {
+			return binary_metrics.MatthewsCorrCoef(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+		}
+		res = multiclass_metrics.MatthewsCorrCoef(yTrue, yPred)
+	case cls_metrics.CohenKappa, cls_metrics.CohenKappaQuadratic:
+		// Для двух классов взвешенная каппа совпадает с обычной.
+		if p.binary {
+			return binary_metrics.CohenKappa(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+		}
+		weights := multiclass_metrics.Unweighted
+		if metric == cls_metrics.CohenKappaQuadratic {
+			weights = multiclass_metrics.Quadratic
+		}
+		res = multiclass_metrics.CohenKappa(yTrue, yPred, weights)
+	case cls_metrics.BalancedAccuracy:
+		if p.binary {
+			return binary_metrics.BalancedAccuracy(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+		}
+		res = multiclass_metrics.BalancedAccuracy(yTrue, yPred)
+	case cls_metrics.Specificity:
+		return binary_metrics.Specificity(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+	case cls_metrics.NPV:
+		return binary_metrics.NegativePredictiveValue(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+	case cls_metrics.Jaccard:
+		return binary_metrics.Jaccard(yTrue, yPred, binary_metrics.PosLabel(p.posLabel))
+	case cls_metrics.SpecificityMacro:
+		res = multiclass_metrics.Specificity(yTrue, yPred, multiclass_metrics.Macro, labels)
+	case cls_metrics.NPVMacro:
+		res = multiclass_metrics.NegativePredictiveValue(yTrue, yPred, multiclass_metrics.Macro, labels)
+	case cls_metrics.JaccardMacro:
+		res = multiclass_metrics.Jaccard(yTrue, yPred, multiclass_metrics.Macro, labels)
+	case cls_metrics.SpecificityMicro:
+		res = multiclass_metrics.Specificity(yTrue, yPred, multiclass_metrics.Micro, labels)
+	case cls_metrics.NPVMicro:
+		res = multiclass_metrics.NegativePredictiveValue(yTrue, yPred, multiclass_metrics.Micro, labels)
+	case cls_metrics.JaccardMicro:
+		res = multiclass_metrics.Jaccard(yTrue, yPred, multiclass_metrics.Micro, labels)
+	case cls_metrics.SpecificityWeighted:
+		res = multiclass_metrics.Specificity(yTrue, yPred, multiclass_metrics.Weighted, labels)
+	case cls_metrics.NPVWeighted:
+		res = multiclass_metrics.NegativePredictiveValue(yTrue, yPred, multiclass_metrics.Weighted, labels)
+	case cls_metrics.JaccardWeighted:
+		res = multiclass_metrics.Jaccard(yTrue, yPred, multiclass_metrics.Weighted, labels)
+	case cls_metrics.LogLoss, cls_metrics.BrierScore:
+		if !p.binary {
+			if metric == cls_metrics.LogLoss {
+				return multiclass_metrics.LogLoss(yTrue, p.proba, p.classes)
+			}
+			return multiclass_metrics.BrierScore(yTrue, p.proba, p.classes)
+		}
+		proba, err := positiveColumn(p, p.proba)
+		if err != nil {
+			return 0, err
+		}
+		if metric == cls_metrics.LogLoss {
+			return binary_metrics.LogLoss(yTrue, proba, binary_metrics.PosLabel(p.posLabel))
+		}
+		return binary_metrics.BrierScore(yTrue, proba, binary_metrics.PosLabel(p.posLabel))
 	default: // По умолчанию, если метрика не определена, возвращаем метрику Accuracy
 		res = multiclass_metrics.Accuracy(yTrue, yPred)
 	}
-	return res
+	return res, nil
 }
 
 // Фильтрует метрики в зависимости от типа задачи.
+// Метрика ROCAUC для мультиклассовой задачи раскрывается во все варианты ROC AUC со стратегиями OvR и OvO,
+// метрика AveragePrecision вычисляется только для бинарной задачи.
+// Метрики MCC, каппа Коэна, сбалансированная точность, log-loss и оценка Брайера имеют одно название для обоих типов задач.
 // Пример:
 // Бинарная задачи:
 // Если заданы метрики PrecisionMicro/Macro/Weighted, то в результат кладем только Precision.
@@ -163,6 +523,20 @@ func filterMetrics(isBinary bool, metrics ...cls_metrics.ClassificationMetric) [
 				set[cls_metrics.Recall] = struct{}{}
 			case cls_metrics.F1, cls_metrics.F1Micro, cls_metrics.F1Macro, cls_metrics.F1Weighted:
 				set[cls_metrics.F1] = struct{}{}
+			case cls_metrics.ROCAUC, cls_metrics.ROCAUCOvR, cls_metrics.ROCAUCOvO,
+				cls_metrics.ROCAUCOvRWeighted, cls_metrics.ROCAUCOvOWeighted:
+				set[cls_metrics.ROCAUC] = struct{}{}
+			case cls_metrics.AveragePrecision:
+				set[cls_metrics.AveragePrecision] = struct{}{}
+			case cls_metrics.MCC, cls_metrics.CohenKappa, cls_metrics.CohenKappaQuadratic, cls_metrics.BalancedAccuracy,
+				cls_metrics.LogLoss, cls_metrics.BrierScore:
+				set[metric] = struct{}{}
+			case cls_metrics.Specificity, cls_metrics.SpecificityMicro, cls_metrics.SpecificityMacro, cls_metrics.SpecificityWeighted:
+				set[cls_metrics.Specificity] = struct{}{}
+			case cls_metrics.NPV, cls_metrics.NPVMicro, cls_metrics.NPVMacro, cls_metrics.NPVWeighted:
+				set[cls_metrics.NPV] = struct{}{}
+			case cls_metrics.Jaccard, cls_metrics.JaccardMicro, cls_metrics.JaccardMacro, cls_metrics.JaccardWeighted:
+				set[cls_metrics.Jaccard] = struct{}{}
 			}
 		} else {
 			switch metric {
@@ -198,6 +572,38 @@ func filterMetrics(isBinary bool, metrics ...cls_metrics.ClassificationMetric) [
 				set[cls_metrics.RecallWeighted] = struct{}{}
 			case cls_metrics.F1Weighted:
 				set[cls_metrics.F1Weighted] = struct{}{}
+			case cls_metrics.ROCAUC:
+				set[cls_metrics.ROCAUCOvR] = struct{}{}
+				set[cls_metrics.ROCAUCOvO] = struct{}{}
+				set[cls_metrics.ROCAUCOvRWeighted] = struct{}{}
+				set[cls_metrics.ROCAUCOvOWeighted] = struct{}{}
+			case cls_metrics.ROCAUCOvR:
+				set[cls_metrics.ROCAUCOvR] = struct{}{}
+			case cls_metrics.ROCAUCOvO:
+				set[cls_metrics.ROCAUCOvO] = struct{}{}
+			case cls_metrics.ROCAUCOvRWeighted:
+				set[cls_metrics.ROCAUCOvRWeighted] = struct{}{}
+			case cls_metrics.ROCAUCOvOWeighted:
+				set[cls_metrics.ROCAUCOvOWeighted] = struct{}{}
+			case cls_metrics.MCC, cls_metrics.CohenKappa, cls_metrics.CohenKappaQuadratic, cls_metrics.BalancedAccuracy,
+				cls_metrics.LogLoss, cls_metrics.BrierScore:
+				set[metric] = struct{}{}
+			case cls_metrics.Specificity:
+				set[cls_metrics.SpecificityMicro] = struct{}{}
+				set[cls_metrics.SpecificityMacro] = struct{}{}
+				set[cls_metrics.SpecificityWeighted] = struct{}{}
+			case cls_metrics.NPV:
+				set[cls_metrics.NPVMicro] = struct{}{}
+				set[cls_metrics.NPVMacro] = struct{}{}
+				set[cls_metrics.NPVWeighted] = struct{}{}
+			case cls_metrics.Jaccard:
+				set[cls_metrics.JaccardMicro] = struct{}{}
+				set[cls_metrics.JaccardMacro] = struct{}{}
+				set[cls_metrics.JaccardWeighted] = struct{}{}
+			case cls_metrics.SpecificityMacro, cls_metrics.SpecificityMicro, cls_metrics.SpecificityWeighted,
+				cls_metrics.NPVMacro, cls_metrics.NPVMicro, cls_metrics.NPVWeighted,
+				cls_metrics.JaccardMacro, cls_metrics.JaccardMicro, cls_metrics.JaccardWeighted:
+				set[metric] = struct{}{}
 			}
 		}
 	}
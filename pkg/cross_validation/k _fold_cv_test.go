@@ -1,6 +1,7 @@
 package cross_validation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/ziyadovea/svm"
@@ -86,6 +87,76 @@ func Test_filterMetrics(t *testing.T) {
 				cls_metrics.RecallWeighted,
 			},
 		},
+		{
+			name: "Test is binary score-based metrics",
+			args: args{
+				isBinary: true,
+				metrics: []cls_metrics.ClassificationMetric{
+					cls_metrics.ROCAUCOvO,
+					cls_metrics.AveragePrecision,
+				},
+			},
+			want: []cls_metrics.ClassificationMetric{
+				cls_metrics.AveragePrecision,
+				cls_metrics.ROCAUC,
+			},
+		},
+		{
+			name: "Test is multiclass score-based metrics",
+			args: args{
+				isBinary: false,
+				metrics: []cls_metrics.ClassificationMetric{
+					cls_metrics.ROCAUC,
+					cls_metrics.AveragePrecision,
+				},
+			},
+			want: []cls_metrics.ClassificationMetric{
+				cls_metrics.ROCAUCOvO,
+				cls_metrics.ROCAUCOvOWeighted,
+				cls_metrics.ROCAUCOvR,
+				cls_metrics.ROCAUCOvRWeighted,
+			},
+		},
+		{
+			name: "Test is binary extended metrics",
+			args: args{
+				isBinary: true,
+				metrics: []cls_metrics.ClassificationMetric{
+					cls_metrics.MCC,
+					cls_metrics.CohenKappaQuadratic,
+					cls_metrics.SpecificityMacro,
+					cls_metrics.Jaccard,
+					cls_metrics.LogLoss,
+				},
+			},
+			want: []cls_metrics.ClassificationMetric{
+				cls_metrics.CohenKappaQuadratic,
+				cls_metrics.Jaccard,
+				cls_metrics.LogLoss,
+				cls_metrics.MCC,
+				cls_metrics.Specificity,
+			},
+		},
+		{
+			name: "Test is multiclass extended metrics",
+			args: args{
+				isBinary: false,
+				metrics: []cls_metrics.ClassificationMetric{
+					cls_metrics.BalancedAccuracy,
+					cls_metrics.NPV,
+					cls_metrics.JaccardWeighted,
+					cls_metrics.BrierScore,
+				},
+			},
+			want: []cls_metrics.ClassificationMetric{
+				cls_metrics.BalancedAccuracy,
+				cls_metrics.BrierScore,
+				cls_metrics.JaccardWeighted,
+				cls_metrics.NPVMacro,
+				cls_metrics.NPVMicro,
+				cls_metrics.NPVWeighted,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -319,17 +390,49 @@ func TestKFoldCVScore(t *testing.T) {
 			want: map[cls_metrics.ClassificationMetric][]float64{
 				cls_metrics.Accuracy: []float64{0.25, 0.5, 0},
 
+				// Макроусреднение идет по всем 12 классам выборки, а не только по классам блока.
 				cls_metrics.RecallMicro:    []float64{0.25, 0.5, 0},
-				cls_metrics.RecallMacro:    []float64{0.25, 0.5, 0},
+				cls_metrics.RecallMacro:    []float64{0.083, 0.167, 0},
 				cls_metrics.RecallWeighted: []float64{0.25, 0.5, 0},
 
-				cls_metrics.PrecisionMicro:    []float64{0, 0.5, 1},
-				cls_metrics.PrecisionMacro:    []float64{0, 0, 0},
-				cls_metrics.PrecisionWeighted: []float64{0, 0, 0},
+				cls_metrics.PrecisionMicro:    []float64{0.25, 0.5, 0},
+				cls_metrics.PrecisionMacro:    []float64{0.083, 0.167, 0},
+				cls_metrics.PrecisionWeighted: []float64{0.25, 0.5, 0},
 
-				cls_metrics.F1Micro:    []float64{0.667, 0, 0.333},
-				cls_metrics.F1Macro:    []float64{0, 0, 0},
-				cls_metrics.F1Weighted: []float64{0, 0, 0},
+				cls_metrics.F1Micro:    []float64{0.25, 0.5, 0},
+				cls_metrics.F1Macro:    []float64{0.083, 0.167, 0},
+				cls_metrics.F1Weighted: []float64{0.25, 0.5, 0},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Test binary problem with arbitrary labels",
+			args: args{
+				cls: &MockClassifier{
+					fitImpl: func(x [][]float64, y []int) error {
+						return nil
+					},
+					predictImpl: func(x [][]float64) []int {
+						res := make([]int, len(x))
+						for i := range x {
+							res[i] = 3
+							if x[i][0] > 3 {
+								res[i] = 7
+							}
+						}
+						return res
+					},
+				},
+				x:       [][]float64{{1}, {2}, {3}, {4}, {5}, {6}},
+				y:       []int{3, 3, 7, 3, 7, 7},
+				nSplits: 2,
+				metrics: []cls_metrics.ClassificationMetric{cls_metrics.Recall, cls_metrics.PrecisionMacro, cls_metrics.F1, cls_metrics.Accuracy},
+			},
+			want: map[cls_metrics.ClassificationMetric][]float64{
+				cls_metrics.Accuracy:  []float64{0.667, 0.667},
+				cls_metrics.Precision: []float64{0, 0.667},
+				cls_metrics.Recall:    []float64{0, 1},
+				cls_metrics.F1:        []float64{0, 0.8},
 			},
 			wantErr: false,
 		},
@@ -392,3 +495,265 @@ func cmpMapFloats(got, want map[cls_metrics.ClassificationMetric][]float64) bool
 	}
 	return true
 }
+
+func TestKFoldCVPairwise(t *testing.T) {
+	type args struct {
+		gram    [][]float64
+		y       []int
+		nSplits int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []CVData
+	}{
+		{
+			name: "Test gram slicing",
+			args: args{
+				gram: [][]float64{
+					{11, 12, 13, 14},
+					{21, 22, 23, 24},
+					{31, 32, 33, 34},
+					{41, 42, 43, 44},
+				},
+				y:       []int{1, 2, 3, 4},
+				nSplits: 2,
+			},
+			want: []CVData{
+				{
+					XTrain: [][]float64{{33, 34}, {43, 44}},
+					YTrain: []int{3, 4},
+					XTest:  [][]float64{{13, 14}, {23, 24}},
+					YTest:  []int{1, 2},
+				},
+				{
+					XTrain: [][]float64{{11, 12}, {21, 22}},
+					YTrain: []int{1, 2},
+					XTest:  [][]float64{{31, 32}, {41, 42}},
+					YTest:  []int{3, 4},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KFoldCVPairwise(tt.args.gram, tt.args.y, tt.args.nSplits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KFoldCVPairwise() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKFold_SplitShuffle(t *testing.T) {
+	x := make([][]float64, 23)
+	y := make([]int, len(x))
+	for i := range x {
+		x[i] = []float64{float64(i)}
+		y[i] = i
+	}
+
+	// Без перемешивания разбиение совпадает с KFoldCV.
+	if got, want := NewKFold(4).Split(x, y), KFoldCV(x, y, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %v, want %v", got, want)
+	}
+
+	split := func(seed int64) []CVData {
+		k := NewKFold(4)
+		k.Shuffle = true
+		k.Seed = seed
+		return k.Split(x, y)
+	}
+	got := split(7)
+	if !reflect.DeepEqual(got, split(7)) {
+		t.Errorf("Split() differs for the same seed")
+	}
+	if reflect.DeepEqual(got, split(8)) {
+		t.Errorf("Split() is the same for different seeds")
+	}
+
+	// Каждый объект попадает в тестовую часть не более одного раза, а в каждом блоке -
+	// либо в обучающую, либо в тестовую часть.
+	seen := make(map[int]int)
+	for _, data := range got {
+		if len(data.YTest) != len(y)/4 || len(data.YTrain)+len(data.YTest) != len(y) {
+			t.Fatalf("Split() sizes = %d/%d", len(data.YTrain), len(data.YTest))
+		}
+		all := append(append([]int{}, data.YTrain...), data.YTest...)
+		sort.Ints(all)
+		if !reflect.DeepEqual(all, y) {
+			t.Errorf("Split() parts %v and %v do not cover the sample", data.YTrain, data.YTest)
+		}
+		for _, label := range data.YTest {
+			seen[label]++
+		}
+	}
+	for label, count := range seen {
+		if count != 1 {
+			t.Errorf("object %d is in the test part %d times", label, count)
+		}
+	}
+}
+
+func TestKFold_ScoreOrder(t *testing.T) {
+	// Значения метрики идут в порядке блоков, хотя блоки обучаются параллельно.
+	x := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+	y := []int{1, 1, 0, 0, 0, 1}
+	for i := 0; i < 10; i++ {
+		cls := &MockClassifier{
+			fitImpl: func(x [][]float64, y []int) error {
+				return nil
+			},
+			predictImpl: func(x [][]float64) []int {
+				res := make([]int, len(x))
+				for i := range res {
+					res[i] = 1
+				}
+				return res
+			},
+		}
+		got, err := NewKFold(3).Score(context.Background(), cls, x, y, cls_metrics.Accuracy)
+		if err != nil {
+			t.Fatalf("Score() error = %v", err)
+		}
+		want := []float64{1, 0, 0.5}
+		if !reflect.DeepEqual(got[cls_metrics.Accuracy], want) {
+			t.Fatalf("Score() = %v, want %v", got[cls_metrics.Accuracy], want)
+		}
+	}
+}
+
+func TestKFoldCVScore_ScoreBasedMetrics(t *testing.T) {
+	x := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}}
+	y := []int{0, 0, 1, 1, 0, 1, 0, 1}
+	predict := func(x [][]float64) []int {
+		res := make([]int, len(x))
+		for i := range x {
+			if x[i][0] > 4 {
+				res[i] = 1
+			}
+		}
+		return res
+	}
+
+	// Классификатор без решающей функции не подходит для ROC AUC.
+	cls := &MockClassifier{
+		fitImpl:     func(x [][]float64, y []int) error { return nil },
+		predictImpl: predict,
+	}
+	if _, err := KFoldCVScore(cls, x, y, 2, cls_metrics.ROCAUC); err == nil {
+		t.Errorf("KFoldCVScore() expected error for a classifier without a decision function")
+	}
+
+	// Решающая функция равна x - 4.5.
+	scorer := &MockScorer{MockClassifier: *cls}
+	got, err := KFoldCVScore(scorer, x, y, 2, cls_metrics.ROCAUC, cls_metrics.AveragePrecision)
+	if err != nil {
+		t.Fatalf("KFoldCVScore() error = %v", err)
+	}
+	// В первом блоке ранжирование идеально, во втором один положительный объект ниже отрицательного.
+	want := map[cls_metrics.ClassificationMetric][]string{
+		cls_metrics.ROCAUC:           {"1.000", "0.750"},
+		cls_metrics.AveragePrecision: {"1.000", "0.833"},
+	}
+	for metric, values := range want {
+		if len(got[metric]) != len(values) {
+			t.Fatalf("KFoldCVScore()[%s] = %v, want %v", metric, got[metric], values)
+		}
+		for i := range values {
+			if fmt.Sprintf("%.3f", got[metric][i]) != values[i] {
+				t.Errorf("KFoldCVScore()[%s] = %v, want %v", metric, got[metric], values)
+			}
+		}
+	}
+}
+
+var _ svm.Scorer = (*MockScorer)(nil)
+
+type MockScorer struct {
+	MockClassifier
+}
+
+func (m *MockScorer) Clone() (svm.Classifier, error) {
+	return m, nil
+}
+
+func (m *MockScorer) DecisionFunction(x [][]float64) [][]float64 {
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = []float64{x[i][0] - 4.5}
+	}
+	return res
+}
+
+func TestKFoldCVScore_ProbabilisticMetrics(t *testing.T) {
+	x := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}}
+	y := []int{0, 0, 1, 1, 0, 1, 0, 1}
+	cls := &MockClassifier{
+		fitImpl: func(x [][]float64, y []int) error { return nil },
+		predictImpl: func(x [][]float64) []int {
+			res := make([]int, len(x))
+			for i := range x {
+				if x[i][0] > 4 {
+					res[i] = 1
+				}
+			}
+			return res
+		},
+	}
+
+	// Классификатор без вероятностей не подходит для log-loss.
+	if _, err := KFoldCVScore(cls, x, y, 2, cls_metrics.LogLoss); err == nil {
+		t.Errorf("KFoldCVScore() expected error for a classifier without probabilities")
+	}
+	if _, err := KFoldCVScore(&MockProbabilistic{MockClassifier: *cls}, x, y, 2, cls_metrics.BrierScore); err == nil {
+		t.Errorf("KFoldCVScore() expected error for a classifier that returned no probabilities")
+	}
+
+	// Вероятность класса 1 равна 0.8 для x > 4 и 0.2 иначе.
+	proba := &MockProbabilistic{MockClassifier: *cls, probability: true}
+	got, err := KFoldCVScore(proba, x, y, 2, cls_metrics.LogLoss, cls_metrics.BrierScore, cls_metrics.MCC)
+	if err != nil {
+		t.Fatalf("KFoldCVScore() error = %v", err)
+	}
+	// В каждом блоке верны два предсказания из четырех, при этом предсказан только один класс.
+	want := map[cls_metrics.ClassificationMetric][]string{
+		cls_metrics.LogLoss:    {"0.916", "0.916"},
+		cls_metrics.BrierScore: {"0.340", "0.340"},
+		cls_metrics.MCC:        {"0.000", "0.000"},
+	}
+	for metric, values := range want {
+		if len(got[metric]) != len(values) {
+			t.Fatalf("KFoldCVScore()[%s] = %v, want %v", metric, got[metric], values)
+		}
+		for i := range values {
+			if fmt.Sprintf("%.3f", got[metric][i]) != values[i] {
+				t.Errorf("KFoldCVScore()[%s] = %v, want %v", metric, got[metric], values)
+			}
+		}
+	}
+}
+
+var _ svm.ProbabilisticClassifier = (*MockProbabilistic)(nil)
+
+type MockProbabilistic struct {
+	MockClassifier
+	probability bool
+}
+
+func (m *MockProbabilistic) Clone() (svm.Classifier, error) {
+	return m, nil
+}
+
+func (m *MockProbabilistic) PredictProba(x [][]float64) [][]float64 {
+	if !m.probability {
+		return nil
+	}
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = []float64{0.8, 0.2}
+		if x[i][0] > 4 {
+			res[i] = []float64{0.2, 0.8}
+		}
+	}
+	return res
+}
@@ -25,6 +25,16 @@ func EuclideanDistance(x, y []float64) float64 {
 	return math.Sqrt(result)
 }
 
+// ManhattanDistance считает манхэттенское расстояние между двумя векторами
+// по формуле z = |x1 - y1| + |x2 - y2| + ... + |xn - yn|.
+func ManhattanDistance(x, y []float64) float64 {
+	var result float64
+	for i := range x {
+		result += math.Abs(x[i] - y[i])
+	}
+	return result
+}
+
 // CountOfUniques считает количество уникальных элементов в слайсе.
 func CountOfUniques(x []int) int {
 	return len(GetUniques(x))
@@ -92,6 +102,9 @@ func Average(x []float64) float64 {
 
 // IsBinary возвращает true, если x является бинарным (состоит только из 0 и 1),
 // иначе - false.
+//
+// Deprecated: используйте classification_metrics.TypeOfTarget, который определяет
+// тип целевых меток независимо от их значений.
 func IsBinary(x []int) bool {
 	for _, item := range x {
 		if item != 0 && item != 1 {
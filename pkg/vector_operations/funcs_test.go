@@ -102,6 +102,50 @@ func TestEuclideanDistance(t *testing.T) {
 	}
 }
 
+func TestManhattanDistance(t *testing.T) {
+	type args struct {
+		x []float64
+		y []float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "Test1",
+			args: args{
+				x: []float64{},
+				y: []float64{},
+			},
+			want: "0.00",
+		},
+		{
+			name: "Test2",
+			args: args{
+				x: []float64{1.4},
+				y: []float64{5},
+			},
+			want: "3.60",
+		},
+		{
+			name: "Test3",
+			args: args{
+				x: []float64{1.4, -324.5, 0, 34},
+				y: []float64{45.7, 23, 2, -1.6},
+			},
+			want: "429.40",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ManhattanDistance(tt.args.x, tt.args.y); fmt.Sprintf("%.2f", got) != tt.want {
+				t.Errorf("ManhattanDistance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCountOfUniques(t *testing.T) {
 	type args struct {
 		x []int
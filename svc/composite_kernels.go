@@ -0,0 +1,194 @@
+package svc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Имена составных ядер в конфигурации KernelConfig.
+const (
+	Sum           KernelName = "sum"
+	Product       KernelName = "product"
+	Scaled        KernelName = "scaled"
+	FeatureSubset KernelName = "feature_subset"
+)
+
+// SumKernel - сумма ядер: K(x, y) = K1(x, y) + ... + Kn(x, y).
+// Сумма положительно полуопределенных ядер положительно полуопределена.
+type SumKernel struct {
+	Kernels []Kernel
+}
+
+// Calculate вычисляет значение суммы ядер.
+func (k *SumKernel) Calculate(x, y []float64) float64 {
+	res := 0.0
+	for _, kernel := range k.Kernels {
+		res += kernel.Calculate(x, y)
+	}
+	return res
+}
+
+// ProductKernel - произведение ядер: K(x, y) = K1(x, y) * ... * Kn(x, y).
+// Произведение положительно полуопределенных ядер положительно полуопределено (теорема Шура).
+type ProductKernel struct {
+	Kernels []Kernel
+}
+
+// Calculate вычисляет значение произведения ядер.
+func (k *ProductKernel) Calculate(x, y []float64) float64 {
+	res := 1.0
+	for _, kernel := range k.Kernels {
+		res *= kernel.Calculate(x, y)
+	}
+	return res
+}
+
+// ScaledKernel - ядро, умноженное на неотрицательный коэффициент: K(x, y) = Weight * Kernel(x, y).
+type ScaledKernel struct {
+	Weight float64
+	Kernel Kernel
+}
+
+// Calculate вычисляет значение масштабированного ядра.
+func (k *ScaledKernel) Calculate(x, y []float64) float64 {
+	return k.Weight * k.Kernel.Calculate(x, y)
+}
+
+// FeatureSubsetKernel - ядро на подмножестве признаков: K(x, y) = Kernel(x[Features], y[Features]).
+// Позволяет применять к разным группам признаков разные ядра, например, RBF к дебитам
+// и линейное ядро к статическим характеристикам скважины.
+type FeatureSubsetKernel struct {
+	Features []int
+	Kernel   Kernel
+}
+
+// Calculate вычисляет значение ядра на выбранных признаках.
+func (k *FeatureSubsetKernel) Calculate(x, y []float64) float64 {
+	xSubset := make([]float64, len(k.Features))
+	ySubset := make([]float64, len(k.Features))
+	for i, feature := range k.Features {
+		xSubset[i] = x[feature]
+		ySubset[i] = y[feature]
+	}
+	return k.Kernel.Calculate(xSubset, ySubset)
+}
+
+// validateKernelFeatures проверяет, что индексы признаков ядер 'feature_subset' внутри ядра kernel
+// меньше числа признаков nFeatures. Иначе вычисление ядра при обучении завершилось бы паникой.
+func validateKernelFeatures(kernel Kernel, nFeatures int) error {
+	switch k := kernel.(type) {
+	case *SumKernel:
+		return validateKernelsFeatures(k.Kernels, nFeatures)
+	case *ProductKernel:
+		return validateKernelsFeatures(k.Kernels, nFeatures)
+	case *ScaledKernel:
+		return validateKernelFeatures(k.Kernel, nFeatures)
+	case *FeatureSubsetKernel:
+		for _, feature := range k.Features {
+			if feature < 0 || feature >= nFeatures {
+				return fmt.Errorf("feature index must be in [0, %d), actual: %d", nFeatures, feature)
+			}
+		}
+		return validateKernelFeatures(k.Kernel, len(k.Features))
+	}
+	return nil
+}
+
+// validateKernelsFeatures проверяет индексы признаков каждого из ядер kernels.
+func validateKernelsFeatures(kernels []Kernel, nFeatures int) error {
+	for _, kernel := range kernels {
+		if err := validateKernelFeatures(kernel, nFeatures); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KernelConfig описывает ядро в конфигурации, например, в JSON.
+// Простое ядро задается именем из реестра и гиперпараметрами, составное - именем
+// 'sum', 'product', 'scaled' или 'feature_subset' и вложенными ядрами Kernels.
+type KernelConfig struct {
+	// Имя ядра.
+	Name KernelName `json:"name"`
+	// Гиперпараметры простого ядра. Если не заданы, то используются гиперпараметры,
+	// переданные в NewKernelFromConfig (для ядер, выбранных по имени, - гиперпараметры оценщика).
+	Params *KernelParams `json:"params,omitempty"`
+	// Коэффициент для ядра 'scaled'.
+	Weight float64 `json:"weight,omitempty"`
+	// Индексы признаков для ядра 'feature_subset'. Их верхняя граница проверяется при обучении,
+	// когда известно число признаков.
+	Features []int `json:"features,omitempty"`
+	// Вложенные ядра: одно для 'scaled' и 'feature_subset', хотя бы одно для 'sum' и 'product'.
+	Kernels []KernelConfig `json:"kernels,omitempty"`
+}
+
+// NewKernelFromConfig строит ядро по конфигурации.
+// params - гиперпараметры для простых ядер, у которых они не заданы в конфигурации.
+func NewKernelFromConfig(config KernelConfig, params KernelParams) (Kernel, error) {
+	name := KernelName(strings.ToLower(string(config.Name)))
+
+	// Построим вложенные ядра.
+	kernels := make([]Kernel, len(config.Kernels))
+	for i := range config.Kernels {
+		kernel, err := NewKernelFromConfig(config.Kernels[i], params)
+		if err != nil {
+			return nil, fmt.Errorf("error in building kernel %d of the %s kernel: %w", i, name, err)
+		}
+		kernels[i] = kernel
+	}
+
+	switch name {
+	case Sum, Product:
+		if len(kernels) == 0 {
+			return nil, fmt.Errorf("%s kernel requires at least one kernel", name)
+		}
+		if name == Sum {
+			return &SumKernel{Kernels: kernels}, nil
+		}
+		return &ProductKernel{Kernels: kernels}, nil
+	case Scaled:
+		if len(kernels) != 1 {
+			return nil, fmt.Errorf("%s kernel requires exactly one kernel, actual: %d", name, len(kernels))
+		}
+		if config.Weight < 0 {
+			return nil, fmt.Errorf("weight of the %s kernel must be non-negative, actual: %v", name, config.Weight)
+		}
+		return &ScaledKernel{Weight: config.Weight, Kernel: kernels[0]}, nil
+	case FeatureSubset:
+		if len(kernels) != 1 {
+			return nil, fmt.Errorf("%s kernel requires exactly one kernel, actual: %d", name, len(kernels))
+		}
+		if len(config.Features) == 0 {
+			return nil, fmt.Errorf("%s kernel requires at least one feature", name)
+		}
+		for _, feature := range config.Features {
+			if feature < 0 {
+				return nil, fmt.Errorf("feature index must be non-negative, actual: %d", feature)
+			}
+		}
+		features := make([]int, len(config.Features))
+		copy(features, config.Features)
+		return &FeatureSubsetKernel{Features: features, Kernel: kernels[0]}, nil
+	}
+
+	if len(kernels) > 0 {
+		return nil, fmt.Errorf("%s kernel does not accept nested kernels", name)
+	}
+	if config.Params != nil {
+		params = *config.Params
+	}
+	return NewKernel(name, params)
+}
+
+// RegisterKernelConfig регистрирует ядро, заданное конфигурацией, под именем name,
+// после чего его можно выбрать через SetKernelByName.
+// Вложенные ядра без заданных гиперпараметров строятся из гиперпараметров оценщика при каждом вызове Fit.
+// Возвращает ошибку, если конфигурация некорректна или ядро с таким именем уже зарегистрировано.
+func RegisterKernelConfig(name KernelName, config KernelConfig) error {
+	if _, err := NewKernelFromConfig(config, KernelParams{Degree: 1, Gamma: 1, Alpha: 1}); err != nil {
+		return fmt.Errorf("invalid kernel config: %w", err)
+	}
+	return RegisterKernel(name, func(params KernelParams) (Kernel, error) {
+		return NewKernelFromConfig(config, params)
+	})
+}
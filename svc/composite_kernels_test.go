@@ -0,0 +1,258 @@
+package svc
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// isPSD проверяет, что симметричная матрица положительно полуопределена:
+// матрица с малой добавкой на диагонали должна раскладываться по Холецкому.
+func isPSD(m [][]float64) bool {
+	n := len(m)
+	maxDiag := 0.0
+	for i := range m {
+		maxDiag = math.Max(maxDiag, math.Abs(m[i][i]))
+	}
+	jitter := 1e-9 * math.Max(1, maxDiag) * float64(n)
+
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, i+1)
+		for j := 0; j <= i; j++ {
+			sum := m[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				sum += jitter
+				if sum <= 0 {
+					return false
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return true
+}
+
+func TestCompositeKernels_Calculate(t *testing.T) {
+	x := []float64{1, 2, 3}
+	y := []float64{2, 0, 1}
+	rbf := &RbfKernel{Gamma: 0.5}
+	linear := &LinearKernel{}
+
+	tests := []struct {
+		name   string
+		kernel Kernel
+		want   float64
+	}{
+		{
+			name:   "Test sum",
+			kernel: &SumKernel{Kernels: []Kernel{rbf, linear}},
+			want:   rbf.Calculate(x, y) + 5,
+		},
+		{
+			name:   "Test product",
+			kernel: &ProductKernel{Kernels: []Kernel{rbf, linear}},
+			want:   rbf.Calculate(x, y) * 5,
+		},
+		{
+			name:   "Test scaled",
+			kernel: &ScaledKernel{Weight: 2.5, Kernel: linear},
+			want:   12.5,
+		},
+		{
+			name:   "Test feature subset",
+			kernel: &FeatureSubsetKernel{Features: []int{0, 2}, Kernel: linear},
+			want:   5,
+		},
+		{
+			name: "Test nested",
+			kernel: &SumKernel{Kernels: []Kernel{
+				&FeatureSubsetKernel{Features: []int{0, 1}, Kernel: rbf},
+				&ScaledKernel{Weight: 0.5, Kernel: &FeatureSubsetKernel{Features: []int{2}, Kernel: linear}},
+			}},
+			want: math.Exp(-0.5*5) + 1.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.kernel.Calculate(x, y); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Calculate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKernelFromConfig(t *testing.T) {
+	params := KernelParams{Gamma: 0.5, Degree: 2}
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "Test sum of subset kernels",
+			config: `{"name": "sum", "kernels": [
+				{"name": "feature_subset", "features": [0, 1], "kernels": [{"name": "rbf", "params": {"gamma": 0.1}}]},
+				{"name": "scaled", "weight": 2, "kernels": [{"name": "feature_subset", "features": [2, 3], "kernels": [{"name": "linear"}]}]}
+			]}`,
+		},
+		{
+			name:   "Test product with estimator params",
+			config: `{"name": "product", "kernels": [{"name": "rbf"}, {"name": "poly"}]}`,
+		},
+		{
+			name:   "Test leaf kernel",
+			config: `{"name": "RBF"}`,
+		},
+		{
+			name:    "Test empty sum",
+			config:  `{"name": "sum"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test scaled without kernel",
+			config:  `{"name": "scaled", "weight": 2}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test negative weight",
+			config:  `{"name": "scaled", "weight": -1, "kernels": [{"name": "linear"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test feature subset without features",
+			config:  `{"name": "feature_subset", "kernels": [{"name": "linear"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test negative feature",
+			config:  `{"name": "feature_subset", "features": [-1], "kernels": [{"name": "linear"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test nested kernels in leaf kernel",
+			config:  `{"name": "rbf", "kernels": [{"name": "linear"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown nested kernel",
+			config:  `{"name": "sum", "kernels": [{"name": "linear"}, {"name": "unknown"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config KernelConfig
+			if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			kernel, err := NewKernelFromConfig(config, params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKernelFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && kernel == nil {
+				t.Errorf("NewKernelFromConfig() = nil")
+			}
+		})
+	}
+}
+
+func TestCompositeKernels_GramIsPSD(t *testing.T) {
+	x, _ := loadIris(t)
+
+	kernels := map[string]Kernel{
+		"sum": &SumKernel{Kernels: []Kernel{&RbfKernel{Gamma: 0.5}, &LinearKernel{}}},
+		"product": &ProductKernel{Kernels: []Kernel{
+			&RbfKernel{Gamma: 0.5}, &PolyKernel{Degree: 2, Coef0: 1},
+		}},
+		"scaled":         &ScaledKernel{Weight: 3, Kernel: &LaplacianKernel{Gamma: 0.2}},
+		"feature subset": &FeatureSubsetKernel{Features: []int{1, 3}, Kernel: &RbfKernel{Gamma: 1}},
+		"rates and attributes": &SumKernel{Kernels: []Kernel{
+			&FeatureSubsetKernel{Features: []int{0, 1}, Kernel: &RbfKernel{Gamma: 0.5}},
+			&ScaledKernel{Weight: 0.1, Kernel: &FeatureSubsetKernel{Features: []int{2, 3}, Kernel: &LinearKernel{}}},
+		}},
+	}
+	for name, kernel := range kernels {
+		t.Run(name, func(t *testing.T) {
+			if !isPSD(gramMatrix(kernel, x, x)) {
+				t.Errorf("Gram matrix of the %s kernel is not positive semi-definite", name)
+			}
+		})
+	}
+
+	// Проверка должна отвергать неопределенные матрицы.
+	if isPSD([][]float64{{1, 2}, {2, 1}}) {
+		t.Errorf("isPSD() = true for an indefinite matrix")
+	}
+}
+
+func TestRegisterKernelConfig(t *testing.T) {
+	config := KernelConfig{
+		Name: Sum,
+		Kernels: []KernelConfig{
+			{Name: FeatureSubset, Features: []int{0}, Kernels: []KernelConfig{{Name: Rbf}}},
+			{Name: Scaled, Weight: 0.5, Kernels: []KernelConfig{
+				{Name: FeatureSubset, Features: []int{1}, Kernels: []KernelConfig{{Name: Linear}}},
+			}},
+		},
+	}
+	if err := RegisterKernelConfig("test_rates_and_attributes", config); err != nil {
+		t.Fatalf("RegisterKernelConfig() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterKernel("test_rates_and_attributes") })
+	if err := RegisterKernelConfig("test_invalid", KernelConfig{Name: Sum}); err == nil {
+		t.Errorf("RegisterKernelConfig() expected error for invalid config")
+	}
+
+	svc := NewSVC()
+	if err := svc.SetKernelByName("test_rates_and_attributes"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	// Вложенное ядро 'rbf' без гиперпараметров берет gamma оценщика.
+	svc.Gamma = 0.2
+	if err := svc.Fit(separableX, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	sum, ok := svc.Kernel.(*SumKernel)
+	if !ok {
+		t.Fatalf("Kernel = %T, want *SumKernel", svc.Kernel)
+	}
+	rbf := sum.Kernels[0].(*FeatureSubsetKernel).Kernel.(*RbfKernel)
+	if rbf.Gamma != 0.2 {
+		t.Errorf("Gamma = %v, want %v", rbf.Gamma, 0.2)
+	}
+	for i, label := range svc.Predict(separableX) {
+		if label != separableY[i] {
+			t.Errorf("Predict()[%d] = %v, want %v", i, label, separableY[i])
+		}
+	}
+}
+
+func TestFeatureSubsetKernel_FitOutOfRange(t *testing.T) {
+	// У объектов separableX два признака, признака с индексом 2 нет.
+	kernel := &SumKernel{Kernels: []Kernel{
+		&LinearKernel{},
+		&ScaledKernel{Weight: 1, Kernel: &FeatureSubsetKernel{Features: []int{0, 2}, Kernel: &LinearKernel{}}},
+	}}
+
+	svc := NewSVC()
+	svc.SetKernel(kernel)
+	if err := svc.Fit(separableX, separableY); err == nil {
+		t.Errorf("SVC.Fit() error = nil, want error")
+	}
+
+	mkl := NewMKL(&LinearKernel{}, kernel)
+	if err := mkl.Fit(separableX, separableY); err == nil {
+		t.Errorf("MKL.Fit() error = nil, want error")
+	}
+
+	nystroem := NewNystroem(kernel)
+	if err := nystroem.Fit(separableX); err == nil {
+		t.Errorf("Nystroem.Fit() error = nil, want error")
+	}
+}
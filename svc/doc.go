@@ -1,3 +1,6 @@
 // Package svc предоставляет реализацию классификатора методом опорных векторов.
 // Пакет предоставляет реализацию как бинарного SVM, так и многоклассового SVM, построенного с помощью метода один против всех (OVA - One-vs-All или OVR - One-vs-Rest).
+// Для больших выборок с линейной разделяющей поверхностью предназначен LinearSVC, который не строит матрицу Грама.
+// Приближенный ядерный SVM для больших выборок получается из LinearSVC и преобразований признаков RandomFourierFeatures и Nystroem.
+// Также пакет предоставляет регрессию методом опорных векторов (epsilon-SVR) и одноклассовый SVM для поиска аномалий.
 package svc
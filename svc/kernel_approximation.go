@@ -0,0 +1,333 @@
+package svc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jinzhu/copier"
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// Проверим, что структуры RandomFourierFeatures и Nystroem удовлетворяют интерфейсу Transformer.
+var (
+	_ svm.Transformer = (*RandomFourierFeatures)(nil)
+	_ svm.Transformer = (*Nystroem)(nil)
+)
+
+// RandomFourierFeatures - преобразование признаков случайными признаками Фурье (Rahimi, Recht, 2007).
+// Объект x отображается в z(x) = sqrt(2 / D) * cos(W * x + b), где строки W выбираются случайно из спектрального
+// распределения ядра, а b - равномерно из [0, 2*pi). Тогда скалярное произведение z(x) * z(y) приближает
+// значение ядра K(x, y) тем точнее, чем больше число компонент D.
+// Поддерживаются ядра 'rbf' (строки W из нормального распределения) и 'laplacian' (из распределения Коши).
+// Вместе с LinearSVC преобразование дает приближенный ядерный SVM, время обучения и предсказания которого
+// не зависит от числа опорных векторов.
+type RandomFourierFeatures struct {
+	// Приближаемое ядро: 'rbf' или 'laplacian'.
+	Kernel KernelName
+
+	// Параметр gamma ядра.
+	Gamma float64
+
+	// Число компонент D - размерность нового признакового пространства.
+	NComponents int
+
+	// Зерно генератора случайных чисел. При одинаковом зерне преобразование одинаково.
+	Seed int64
+
+	// Случайные частоты W: NComponents x nFeatures.
+	weights [][]float64
+
+	// Случайные сдвиги b.
+	offsets []float64
+}
+
+// NewRandomFourierFeatures возвращает экземпляр RandomFourierFeatures с параметрами по умолчанию.
+func NewRandomFourierFeatures() *RandomFourierFeatures {
+	return &RandomFourierFeatures{
+		Kernel:      Rbf,
+		Gamma:       1.0,
+		NComponents: 100,
+		Seed:        1,
+		weights:     nil,
+		offsets:     nil,
+	}
+}
+
+// Fit выбирает случайные частоты и сдвиги для объектов с числом признаков, как в x.
+// x - матрица признаков.
+func (r *RandomFourierFeatures) Fit(x [][]float64) error {
+	// Проверим валидность входных данных.
+	if err := validateTransformerInput(x, r.NComponents); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+	if r.Gamma <= 0 {
+		return fmt.Errorf("gamma must be positive, actual: %v", r.Gamma)
+	}
+
+	// Выберем распределение частот по спектральной плотности ядра.
+	var sample func(rnd *rand.Rand) float64
+	switch r.Kernel {
+	case Rbf:
+		// exp(-gamma * ||x - y||^2) - преобразование Фурье нормального распределения N(0, 2 * gamma).
+		scale := math.Sqrt(2 * r.Gamma)
+		sample = func(rnd *rand.Rand) float64 {
+			return scale * rnd.NormFloat64()
+		}
+	case Laplacian:
+		// exp(-gamma * ||x - y||_1) - произведение преобразований Фурье распределений Коши с масштабом gamma.
+		sample = func(rnd *rand.Rand) float64 {
+			return r.Gamma * math.Tan(math.Pi*(rnd.Float64()-0.5))
+		}
+	default:
+		return fmt.Errorf("random Fourier features support only the %s and %s kernels, actual: %s", Rbf, Laplacian, r.Kernel)
+	}
+
+	rnd := rand.New(rand.NewSource(r.Seed))
+	nFeatures := len(x[0])
+	r.weights = make([][]float64, r.NComponents)
+	r.offsets = make([]float64, r.NComponents)
+	for k := range r.weights {
+		r.weights[k] = make([]float64, nFeatures)
+		for j := range r.weights[k] {
+			r.weights[k][j] = sample(rnd)
+		}
+		r.offsets[k] = 2 * math.Pi * rnd.Float64()
+	}
+
+	return nil
+}
+
+// Transform отображает объекты x в пространство случайных признаков Фурье.
+// x - матрица признаков.
+func (r *RandomFourierFeatures) Transform(x [][]float64) [][]float64 {
+	norm := math.Sqrt(2 / float64(len(r.weights)))
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = make([]float64, len(r.weights))
+		for k := range r.weights {
+			res[i][k] = norm * math.Cos(vector_operations.ScalarProduct(r.weights[k], x[i])+r.offsets[k])
+		}
+	}
+	return res
+}
+
+// Clone возвращает копию преобразования.
+func (r *RandomFourierFeatures) Clone() (svm.Transformer, error) {
+	res := &RandomFourierFeatures{}
+	if err := copier.Copy(res, r); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Nystroem - преобразование признаков методом Нистрема (Williams, Seeger, 2001).
+// Из обучающей выборки случайно выбираются NComponents опорных точек (landmarks) l_1, ..., l_m, и объект x
+// отображается в z(x) = K_mm^(-1/2) * (K(x, l_1), ..., K(x, l_m)), где K_mm - матрица ядра опорных точек.
+// Тогда скалярное произведение z(x) * z(y) приближает значение ядра K(x, y).
+// В отличие от RandomFourierFeatures подходит для любого положительно полуопределенного ядра.
+type Nystroem struct {
+	// Приближаемое ядро.
+	Kernel Kernel
+
+	// Число опорных точек - размерность нового признакового пространства.
+	// Если обучающая выборка меньше, то опорными точками становятся все ее объекты.
+	NComponents int
+
+	// Зерно генератора случайных чисел для выбора опорных точек.
+	// При одинаковом зерне и одинаковой обучающей выборке преобразование одинаково.
+	Seed int64
+
+	// Опорные точки.
+	landmarks [][]float64
+
+	// Матрица K_mm^(-1/2). Собственные значения K_mm, близкие к нулю, отбрасываются.
+	normalization [][]float64
+}
+
+// NewNystroem возвращает экземпляр Nystroem с параметрами по умолчанию для ядра kernel.
+func NewNystroem(kernel Kernel) *Nystroem {
+	return &Nystroem{
+		Kernel:        kernel,
+		NComponents:   100,
+		Seed:          1,
+		landmarks:     nil,
+		normalization: nil,
+	}
+}
+
+// Fit выбирает опорные точки из обучающей выборки и вычисляет нормирующую матрицу.
+// x - матрица признаков.
+func (n *Nystroem) Fit(x [][]float64) error {
+	// Проверим валидность входных данных.
+	if err := validateTransformerInput(x, n.NComponents); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+	if n.Kernel == nil {
+		return fmt.Errorf("kernel is not set")
+	}
+	if err := validateKernelFeatures(n.Kernel, len(x[0])); err != nil {
+		return fmt.Errorf("invalid kernel: %w", err)
+	}
+
+	// Выберем опорные точки без повторений.
+	m := n.NComponents
+	if m > len(x) {
+		m = len(x)
+	}
+	rnd := rand.New(rand.NewSource(n.Seed))
+	idx := rnd.Perm(len(x))[:m]
+	n.landmarks = make([][]float64, m)
+	for k, i := range idx {
+		n.landmarks[k] = make([]float64, len(x[i]))
+		copy(n.landmarks[k], x[i])
+	}
+
+	// Вычислим K_mm^(-1/2) через спектральное разложение K_mm = V * diag(lambda) * V^T.
+	gram := make([][]float64, m)
+	for k := range gram {
+		gram[k] = make([]float64, m)
+		for l := 0; l <= k; l++ {
+			gram[k][l] = n.Kernel.Calculate(n.landmarks[k], n.landmarks[l])
+			gram[l][k] = gram[k][l]
+		}
+	}
+	values, vectors := symmetricEigen(gram)
+	maxValue := 0.0
+	for _, value := range values {
+		maxValue = math.Max(maxValue, value)
+	}
+	n.normalization = make([][]float64, m)
+	for k := range n.normalization {
+		n.normalization[k] = make([]float64, m)
+	}
+	for e, value := range values {
+		// Отбросим направления, в которых матрица вырождена.
+		if value <= 1e-12*maxValue {
+			continue
+		}
+		scale := 1 / math.Sqrt(value)
+		for k := 0; k < m; k++ {
+			for l := 0; l < m; l++ {
+				n.normalization[k][l] += scale * vectors[k][e] * vectors[l][e]
+			}
+		}
+	}
+
+	return nil
+}
+
+// Transform отображает объекты x в пространство признаков Нистрема.
+// x - матрица признаков.
+func (n *Nystroem) Transform(x [][]float64) [][]float64 {
+	res := make([][]float64, len(x))
+	row := make([]float64, len(n.landmarks))
+	for i := range x {
+		for k := range n.landmarks {
+			row[k] = n.Kernel.Calculate(x[i], n.landmarks[k])
+		}
+		res[i] = make([]float64, len(n.landmarks))
+		for k := range n.normalization {
+			res[i][k] = vector_operations.ScalarProduct(n.normalization[k], row)
+		}
+	}
+	return res
+}
+
+// Landmarks возвращает опорные точки обученного преобразования.
+func (n *Nystroem) Landmarks() [][]float64 {
+	return n.landmarks
+}
+
+// Clone возвращает копию преобразования.
+func (n *Nystroem) Clone() (svm.Transformer, error) {
+	res := &Nystroem{}
+	if err := copier.Copy(res, n); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// validateTransformerInput проверяет матрицу признаков и число компонент преобразования.
+func validateTransformerInput(x [][]float64, nComponents int) error {
+	if len(x) == 0 {
+		return fmt.Errorf("feature matrix is empty")
+	}
+	// Проверим, что матрица признаков является прямоугольной.
+	if !vector_operations.IsMatrixRectangular(x) {
+		return fmt.Errorf("feature matrix must be rectangular")
+	}
+	if nComponents <= 0 {
+		return fmt.Errorf("number of components must be positive, actual: %d", nComponents)
+	}
+	return nil
+}
+
+// symmetricEigen вычисляет собственные значения и собственные векторы симметричной матрицы a
+// методом вращений Якоби. Собственный вектор, соответствующий values[k], - k-ый столбец vectors.
+func symmetricEigen(a [][]float64) (values []float64, vectors [][]float64) {
+	const maxSweeps = 100
+
+	n := len(a)
+	m := make([][]float64, n)
+	vectors = make([][]float64, n)
+	for i := range a {
+		m[i] = make([]float64, n)
+		copy(m[i], a[i])
+		vectors[i] = make([]float64, n)
+		vectors[i][i] = 1
+	}
+
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		// Остановимся, когда внедиагональные элементы пренебрежимо малы по сравнению с диагональными.
+		off, diag := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			diag += m[i][i] * m[i][i]
+			for j := i + 1; j < n; j++ {
+				off += m[i][j] * m[i][j]
+			}
+		}
+		if off <= 1e-30*diag {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				// Угол вращения, обнуляющего элемент m[p][q].
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p] = c*mkp - s*mkq
+					m[k][q] = s*mkp + c*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k] = c*mpk - s*mqk
+					m[q][k] = s*mpk + c*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := vectors[k][p], vectors[k][q]
+					vectors[k][p] = c*vkp - s*vkq
+					vectors[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values = make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	return values, vectors
+}
@@ -0,0 +1,219 @@
+package svc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// maxApproximationError возвращает наибольшее отклонение скалярных произведений преобразованных объектов
+// от значений ядра kernel.
+func maxApproximationError(kernel Kernel, x, z [][]float64) float64 {
+	res := 0.0
+	for i := range x {
+		for j := range x {
+			diff := vector_operations.ScalarProduct(z[i], z[j]) - kernel.Calculate(x[i], x[j])
+			res = math.Max(res, math.Abs(diff))
+		}
+	}
+	return res
+}
+
+func Test_symmetricEigen(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 2},
+		{1, 3, 0.5},
+		{2, 0.5, 5},
+	}
+	values, vectors := symmetricEigen(a)
+	for i := range a {
+		for j := range a {
+			got := 0.0
+			for k := range values {
+				got += vectors[i][k] * values[k] * vectors[j][k]
+			}
+			if math.Abs(got-a[i][j]) > 1e-9 {
+				t.Errorf("V * diag(values) * V^T [%d][%d] = %v, want %v", i, j, got, a[i][j])
+			}
+		}
+	}
+}
+
+func TestRandomFourierFeatures_Transform(t *testing.T) {
+	x, _ := loadIris(t)
+	x = x[:30]
+
+	tests := []struct {
+		name   string
+		kernel KernelName
+		gamma  float64
+		exact  Kernel
+	}{
+		{
+			name:   "Test rbf",
+			kernel: Rbf,
+			gamma:  0.5,
+			exact:  &RbfKernel{Gamma: 0.5},
+		},
+		{
+			name:   "Test laplacian",
+			kernel: Laplacian,
+			gamma:  0.3,
+			exact:  &LaplacianKernel{Gamma: 0.3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRandomFourierFeatures()
+			r.Kernel = tt.kernel
+			r.Gamma = tt.gamma
+			r.NComponents = 5000
+			if err := r.Fit(x); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			z := r.Transform(x)
+			if got := maxApproximationError(tt.exact, x, z); got > 0.1 {
+				t.Errorf("max approximation error = %v, want at most 0.1", got)
+			}
+		})
+	}
+}
+
+func TestNystroem_Transform(t *testing.T) {
+	x, _ := loadIris(t)
+	x = x[:40]
+	kernel := &RbfKernel{Gamma: 0.5}
+
+	// Если опорными точками являются все объекты, то на них приближение точное.
+	n := NewNystroem(kernel)
+	n.NComponents = len(x) + 10
+	if err := n.Fit(x); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if len(n.Landmarks()) != len(x) {
+		t.Errorf("len(Landmarks()) = %d, want %d", len(n.Landmarks()), len(x))
+	}
+	if got := maxApproximationError(kernel, x, n.Transform(x)); got > 1e-6 {
+		t.Errorf("max approximation error = %v, want at most 1e-6", got)
+	}
+
+	// На части опорных точек приближение грубее, но остается разумным.
+	n = NewNystroem(kernel)
+	n.NComponents = 20
+	if err := n.Fit(x); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if got := maxApproximationError(kernel, x, n.Transform(x)); got > 0.2 {
+		t.Errorf("max approximation error = %v, want at most 0.2", got)
+	}
+}
+
+func TestKernelApproximation_Reproducible(t *testing.T) {
+	x, _ := loadIris(t)
+
+	newTransformers := map[string]func(seed int64) svm.Transformer{
+		"random Fourier features": func(seed int64) svm.Transformer {
+			r := NewRandomFourierFeatures()
+			r.Seed = seed
+			return r
+		},
+		"Nystroem": func(seed int64) svm.Transformer {
+			n := NewNystroem(&RbfKernel{Gamma: 0.5})
+			n.NComponents = 20
+			n.Seed = seed
+			return n
+		},
+	}
+	for name, newTransformer := range newTransformers {
+		t.Run(name, func(t *testing.T) {
+			transform := func(seed int64) [][]float64 {
+				tr := newTransformer(seed)
+				if err := tr.Fit(x); err != nil {
+					t.Fatalf("Fit() error = %v", err)
+				}
+				return tr.Transform(x)
+			}
+			if !reflect.DeepEqual(transform(7), transform(7)) {
+				t.Errorf("Transform() differs for the same seed")
+			}
+			if reflect.DeepEqual(transform(7), transform(8)) {
+				t.Errorf("Transform() is the same for different seeds")
+			}
+		})
+	}
+}
+
+func TestKernelApproximation_LinearSVC(t *testing.T) {
+	x, y := loadIris(t)
+
+	n := NewNystroem(&RbfKernel{Gamma: 0.5})
+	n.NComponents = 50
+	if err := n.Fit(x); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	z := n.Transform(x)
+
+	l := NewLinearSVC()
+	l.C = 10
+	if err := l.Fit(z, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	correct := 0
+	for i, label := range l.Predict(z) {
+		if label == y[i] {
+			correct++
+		}
+	}
+	if accuracy := float64(correct) / float64(len(y)); accuracy < 0.95 {
+		t.Errorf("accuracy = %v, want at least 0.95", accuracy)
+	}
+}
+
+func TestKernelApproximation_FitErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer svm.Transformer
+		x           [][]float64
+	}{
+		{
+			name:        "Test unsupported kernel",
+			transformer: &RandomFourierFeatures{Kernel: Poly, Gamma: 1, NComponents: 10},
+			x:           separableX,
+		},
+		{
+			name:        "Test non-positive gamma",
+			transformer: &RandomFourierFeatures{Kernel: Rbf, Gamma: 0, NComponents: 10},
+			x:           separableX,
+		},
+		{
+			name:        "Test zero components",
+			transformer: &Nystroem{Kernel: &LinearKernel{}, NComponents: 0},
+			x:           separableX,
+		},
+		{
+			name:        "Test nil kernel",
+			transformer: &Nystroem{NComponents: 10},
+			x:           separableX,
+		},
+		{
+			name:        "Test empty matrix",
+			transformer: NewRandomFourierFeatures(),
+			x:           nil,
+		},
+		{
+			name:        "Test non-rectangular matrix",
+			transformer: NewNystroem(&LinearKernel{}),
+			x:           [][]float64{{1, 2}, {3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.transformer.Fit(tt.x); err == nil {
+				t.Errorf("Fit() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package svc
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Размер кэша ядра по умолчанию в мегабайтах.
+const defaultCacheSizeMB = 200.0
+
+// kernelStorage хранит вычисленные строки матрицы ядра объектов x.
+// Если строки не помещаются в заданный объем памяти, то вытесняются давно не использованные (LRU).
+// Хранилище безопасно для одновременного использования из нескольких горутин, поэтому его разделяют
+// бинарные классификаторы MultiSVC и фолды внутренней кросс-валидации, которые обучаются на тех же объектах.
+type kernelStorage struct {
+	kernel Kernel
+	x      [][]float64
+
+	// Для предвычисленного ядра строки берутся из матрицы Грама и не кэшируются.
+	gram [][]float64
+
+	// Максимальное число хранимых строк.
+	maxRows int
+
+	mu   sync.Mutex
+	rows map[int]*list.Element
+	// Список строк от недавно использованных к давно не использованным.
+	lru  *list.List
+	diag []float64
+}
+
+// cachedRow описывает строку матрицы ядра в кэше.
+type cachedRow struct {
+	i      int
+	values []float64
+}
+
+// newKernelStorage возвращает хранилище строк матрицы ядра объемом не больше cacheSizeMB мегабайт.
+// Независимо от объема хранится хотя бы две строки - столько нужно для одного шага SMO.
+func newKernelStorage(kernel Kernel, x [][]float64, cacheSizeMB float64) *kernelStorage {
+	maxRows := 2
+	if len(x) > 0 {
+		if n := int(cacheSizeMB * (1 << 20) / float64(8*len(x))); n > maxRows {
+			maxRows = n
+		}
+	}
+	return &kernelStorage{
+		kernel:  kernel,
+		x:       x,
+		maxRows: maxRows,
+		rows:    make(map[int]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// newPrecomputedStorage возвращает хранилище, которое берет строки из матрицы Грама gram.
+func newPrecomputedStorage(gram [][]float64) *kernelStorage {
+	return &kernelStorage{gram: gram}
+}
+
+// row возвращает i-ую строку матрицы ядра. Возвращаемый слайс нельзя изменять.
+func (s *kernelStorage) row(i int) []float64 {
+	if s.gram != nil {
+		return s.gram[i]
+	}
+
+	if values, ok := s.lookup(i); ok {
+		return values
+	}
+
+	// Строку считаем без блокировки, чтобы другие горутины могли пользоваться кэшем.
+	values := make([]float64, len(s.x))
+	for j := range s.x {
+		values[j] = s.kernel.Calculate(s.x[i], s.x[j])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Строку могла уже посчитать другая горутина.
+	if e, ok := s.rows[i]; ok {
+		s.lru.MoveToFront(e)
+		return e.Value.(*cachedRow).values
+	}
+	s.rows[i] = s.lru.PushFront(&cachedRow{i: i, values: values})
+	for s.lru.Len() > s.maxRows {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		delete(s.rows, e.Value.(*cachedRow).i)
+	}
+	return values
+}
+
+// lookup возвращает i-ую строку матрицы ядра, если она есть в кэше.
+func (s *kernelStorage) lookup(i int) ([]float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.rows[i]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(e)
+	return e.Value.(*cachedRow).values, true
+}
+
+// diagonal возвращает диагональ матрицы ядра. Диагональ вычисляется один раз и хранится вне LRU.
+func (s *kernelStorage) diagonal() []float64 {
+	if s.gram != nil {
+		res := make([]float64, len(s.gram))
+		for i := range res {
+			res[i] = s.gram[i][i]
+		}
+		return res
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.diag == nil {
+		s.diag = make([]float64, len(s.x))
+		for i := range s.x {
+			s.diag[i] = s.kernel.Calculate(s.x[i], s.x[i])
+		}
+	}
+	return s.diag
+}
+
+// kernelRowCache предоставляет доступ к матрице ядра подвыборки объектов хранилища.
+type kernelRowCache struct {
+	storage *kernelStorage
+	// Индексы объектов подвыборки в хранилище; nil означает все объекты.
+	idx []int
+}
+
+// newKernelRowCache возвращает кэш строк ядра для объектов x.
+// Для предвычисленного ядра x - матрица Грама.
+func newKernelRowCache(kernel Kernel, pairwise bool, x [][]float64, cacheSizeMB float64) *kernelRowCache {
+	if pairwise {
+		return &kernelRowCache{storage: newPrecomputedStorage(x)}
+	}
+	return &kernelRowCache{storage: newKernelStorage(kernel, x, cacheSizeMB)}
+}
+
+// subset возвращает кэш для подвыборки объектов с индексами idx, который разделяет хранилище с исходным.
+func (c *kernelRowCache) subset(idx []int) *kernelRowCache {
+	res := &kernelRowCache{storage: c.storage, idx: make([]int, len(idx))}
+	for k, i := range idx {
+		res.idx[k] = c.index(i)
+	}
+	return res
+}
+
+// index возвращает индекс i-ого объекта подвыборки в хранилище.
+func (c *kernelRowCache) index(i int) int {
+	if c.idx == nil {
+		return i
+	}
+	return c.idx[i]
+}
+
+// row возвращает i-ую строку матрицы ядра подвыборки. Возвращаемый слайс нельзя изменять.
+func (c *kernelRowCache) row(i int) []float64 {
+	values := c.storage.row(c.index(i))
+	if c.idx == nil {
+		return values
+	}
+	res := make([]float64, len(c.idx))
+	for k, j := range c.idx {
+		res[k] = values[j]
+	}
+	return res
+}
+
+// diagonal возвращает диагональ матрицы ядра подвыборки.
+func (c *kernelRowCache) diagonal() []float64 {
+	diag := c.storage.diagonal()
+	if c.idx == nil {
+		return diag
+	}
+	res := make([]float64, len(c.idx))
+	for k, i := range c.idx {
+		res[k] = diag[i]
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package svc
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func Test_kernelStorage_row(t *testing.T) {
+	kernel := &RbfKernel{Gamma: 0.1}
+	want := gramMatrix(kernel, separableX, separableX)
+
+	tests := []struct {
+		name        string
+		cacheSizeMB float64
+		wantMaxRows int
+	}{
+		{
+			name:        "Test cache for all rows",
+			cacheSizeMB: 1,
+			wantMaxRows: len(separableX),
+		},
+		{
+			// Строка из 12 значений занимает 96 байт, в кэш помещается 3 строки.
+			name:        "Test small cache",
+			cacheSizeMB: 300.0 / (1 << 20),
+			wantMaxRows: 3,
+		},
+		{
+			name:        "Test cache of at least two rows",
+			cacheSizeMB: 0,
+			wantMaxRows: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newKernelStorage(kernel, separableX, tt.cacheSizeMB)
+			// Дважды проходим по всем строкам в разном порядке, чтобы часть строк бралась из кэша.
+			for pass := 0; pass < 2; pass++ {
+				for k := range separableX {
+					i := k
+					if pass == 1 {
+						i = len(separableX) - 1 - k
+					}
+					if got := s.row(i); !reflect.DeepEqual(got, want[i]) {
+						t.Fatalf("row(%d) = %v, want %v", i, got, want[i])
+					}
+					if s.lru.Len() > tt.wantMaxRows || len(s.rows) != s.lru.Len() {
+						t.Fatalf("cache holds %d rows, want at most %d", s.lru.Len(), tt.wantMaxRows)
+					}
+				}
+			}
+			if got := s.diagonal(); !reflect.DeepEqual(got, []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}) {
+				t.Errorf("diagonal() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_kernelRowCache_subset(t *testing.T) {
+	kernel := &LinearKernel{}
+	cache := newKernelRowCache(kernel, false, separableX, 0)
+	idx := []int{7, 2, 10, 4}
+	subset := cache.subset(idx)
+	// Подвыборка подвыборки ссылается на исходные объекты.
+	nested := subset.subset([]int{3, 0})
+
+	xSubset := [][]float64{separableX[7], separableX[2], separableX[10], separableX[4]}
+	want := gramMatrix(kernel, xSubset, xSubset)
+	for i := range idx {
+		if got := subset.row(i); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row(%d) = %v, want %v", i, got, want[i])
+		}
+	}
+	if got, want := nested.row(0), []float64{want[3][3], want[3][0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("row(0) of nested subset = %v, want %v", got, want)
+	}
+	if got, want := subset.diagonal(), []float64{want[0][0], want[1][1], want[2][2], want[3][3]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("diagonal() = %v, want %v", got, want)
+	}
+}
+
+func Test_kernelStorage_concurrentRows(t *testing.T) {
+	kernel := &RbfKernel{Gamma: 0.1}
+	want := gramMatrix(kernel, separableX, separableX)
+	s := newKernelStorage(kernel, separableX, 300.0/(1<<20))
+
+	wg := sync.WaitGroup{}
+	errs := make(chan int, len(separableX)*8)
+	for g := 0; g < 8; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range separableX {
+				i := (k + g) % len(separableX)
+				if !reflect.DeepEqual(s.row(i), want[i]) {
+					errs <- i
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for i := range errs {
+		t.Errorf("row(%d) differs from the kernel matrix", i)
+	}
+}
@@ -1,8 +1,13 @@
 package svc
 
 import (
-	"github.com/ziyadovea/svm/pkg/vector_operations"
+	"fmt"
 	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ziyadovea/svm/pkg/vector_operations"
 )
 
 // KernelName тип для имени ядра.
@@ -10,9 +15,31 @@ type KernelName string
 
 // Определяем в константах существующие ядра.
 const (
-	Linear KernelName = "linear"
-	Poly   KernelName = "poly"
-	Rbf    KernelName = "rbf"
+	Linear            KernelName = "linear"
+	Poly              KernelName = "poly"
+	Rbf               KernelName = "rbf"
+	Sigmoid           KernelName = "sigmoid"
+	Laplacian         KernelName = "laplacian"
+	Chi2              KernelName = "chi2"
+	Cosine            KernelName = "cosine"
+	RationalQuadratic KernelName = "rational_quadratic"
+)
+
+// Precomputed - режим предвычисленного ядра: вместо матрицы признаков классификатор принимает
+// матрицу значений ядра. Это не ядро из реестра, поэтому его поддерживают только SVC и MultiSVC.
+const Precomputed KernelName = "precomputed"
+
+// GammaMode тип для способа выбора параметра gamma ядра.
+type GammaMode string
+
+// Определяем в константах способы выбора gamma.
+const (
+	// GammaValue - используется значение из поля Gamma.
+	GammaValue GammaMode = "value"
+	// GammaScale - gamma = 1 / (nFeatures * Var(X)), где Var(X) - дисперсия всех элементов матрицы признаков.
+	GammaScale GammaMode = "scale"
+	// GammaAuto - gamma = 1 / nFeatures.
+	GammaAuto GammaMode = "auto"
 )
 
 // Kernel интерфейс для ядра.
@@ -20,8 +47,211 @@ type Kernel interface {
 	Calculate(x, y []float64) float64
 }
 
-// Удостоверяемся, что структура LinearKernel удовлетворяет интерфейсу Kernel.
-var _ Kernel = (*LinearKernel)(nil)
+// KernelParams описывает гиперпараметры, из которых строится ядро.
+// Каждое ядро использует только нужные ему параметры.
+type KernelParams struct {
+	// Степень многочлена для полиномиального ядра.
+	Degree int `json:"degree,omitempty"`
+	// Свободный член для полиномиального и сигмоидного ядер.
+	Coef0 float64 `json:"coef0,omitempty"`
+	// Масштаб для ядер 'poly', 'rbf', 'sigmoid', 'laplacian', 'chi2' и 'rational_quadratic'.
+	Gamma float64 `json:"gamma,omitempty"`
+	// Параметр alpha для ядра 'rational_quadratic'.
+	Alpha float64 `json:"alpha,omitempty"`
+}
+
+// ParametrizedKernel - ядро, которое может вернуть гиперпараметры, из которых оно построено.
+// Такие ядра сохраняются вместе с моделью и восстанавливаются из реестра по имени.
+type ParametrizedKernel interface {
+	Kernel
+	Params() KernelParams
+}
+
+// KernelFactory строит ядро по гиперпараметрам.
+type KernelFactory func(params KernelParams) (Kernel, error)
+
+// Реестр ядер: имя ядра - фабрика.
+// Доступ к реестру защищен мьютексом, так как регистрировать ядра можно из разных горутин.
+var (
+	kernelRegistryMu sync.RWMutex
+	kernelRegistry   = make(map[KernelName]KernelFactory)
+)
+
+// Зарегистрируем встроенные ядра.
+func init() {
+	builtins := map[KernelName]KernelFactory{
+		Linear: func(params KernelParams) (Kernel, error) {
+			return &LinearKernel{}, nil
+		},
+		Poly: func(params KernelParams) (Kernel, error) {
+			return &PolyKernel{Gamma: params.Gamma, Coef0: params.Coef0, Degree: params.Degree}, nil
+		},
+		Rbf: func(params KernelParams) (Kernel, error) {
+			return &RbfKernel{Gamma: params.Gamma}, nil
+		},
+		Sigmoid: func(params KernelParams) (Kernel, error) {
+			return &SigmoidKernel{Gamma: params.Gamma, Coef0: params.Coef0}, nil
+		},
+		Laplacian: func(params KernelParams) (Kernel, error) {
+			return &LaplacianKernel{Gamma: params.Gamma}, nil
+		},
+		Chi2: func(params KernelParams) (Kernel, error) {
+			return &Chi2Kernel{Gamma: params.Gamma}, nil
+		},
+		Cosine: func(params KernelParams) (Kernel, error) {
+			return &CosineKernel{}, nil
+		},
+		RationalQuadratic: func(params KernelParams) (Kernel, error) {
+			if params.Alpha <= 0 {
+				return nil, fmt.Errorf("alpha must be positive for the %s kernel, actual: %v", RationalQuadratic, params.Alpha)
+			}
+			return &RationalQuadraticKernel{Gamma: params.Gamma, Alpha: params.Alpha}, nil
+		},
+	}
+	for name, factory := range builtins {
+		if err := RegisterKernel(name, factory); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterKernel регистрирует ядро под именем name, после чего его можно выбрать через SetKernelByName.
+// Имена ядер не зависят от регистра.
+// Возвращает ошибку, если имя пустое, фабрика не задана или ядро с таким именем уже зарегистрировано.
+func RegisterKernel(name KernelName, factory KernelFactory) error {
+	name = KernelName(strings.ToLower(string(name)))
+	if name == "" {
+		return fmt.Errorf("kernel name is empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("kernel factory is nil for kernel %s", name)
+	}
+
+	kernelRegistryMu.Lock()
+	defer kernelRegistryMu.Unlock()
+	if _, ok := kernelRegistry[name]; ok {
+		return fmt.Errorf("kernel %s is already registered", name)
+	}
+	kernelRegistry[name] = factory
+	return nil
+}
+
+// NewKernel строит зарегистрированное ядро по его имени и гиперпараметрам.
+// Возвращает ошибку в случае неизвестного ядра.
+func NewKernel(name KernelName, params KernelParams) (Kernel, error) {
+	name = KernelName(strings.ToLower(string(name)))
+
+	kernelRegistryMu.RLock()
+	factory, ok := kernelRegistry[name]
+	kernelRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown kernel name: %s", name)
+	}
+
+	kernel, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("error in building the %s kernel: %w", name, err)
+	}
+	return kernel, nil
+}
+
+// kernelRegistered проверяет, что ядро с именем name зарегистрировано.
+func kernelRegistered(name KernelName) bool {
+	kernelRegistryMu.RLock()
+	defer kernelRegistryMu.RUnlock()
+	_, ok := kernelRegistry[name]
+	return ok
+}
+
+// kernelByName строит зарегистрированное ядро по имени, не зависящему от регистра.
+// Возвращает нормализованное имя ядра и само ядро.
+func kernelByName(kernelName string, params KernelParams) (KernelName, Kernel, error) {
+	name := KernelName(strings.ToLower(kernelName))
+	kernel, err := NewKernel(name, params)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, kernel, nil
+}
+
+// resolveKernel возвращает ядро для обучения. Ядро, заданное по имени, строится заново
+// из текущих значений гиперпараметров, иначе используется заданный объект ядра current.
+// Для предвычисленного ядра объект ядра не нужен, поэтому возвращается nil.
+// nFeatures - число признаков обучающей выборки, с ним сверяются индексы признаков составных ядер.
+func resolveKernel(name KernelName, params KernelParams, current Kernel, nFeatures int) (Kernel, error) {
+	if name == Precomputed {
+		return nil, nil
+	}
+	kernel := current
+	if name != "" {
+		var err error
+		kernel, err = NewKernel(name, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if kernel == nil {
+		return nil, fmt.Errorf("kernel is not set")
+	}
+	if err := validateKernelFeatures(kernel, nFeatures); err != nil {
+		return nil, err
+	}
+	return kernel, nil
+}
+
+// resolveGamma возвращает значение gamma для обучения на матрице признаков x.
+// Для режима GammaValue (и пустого режима) возвращается gamma.
+// Если все элементы x равны, то в режиме GammaScale возвращается 1.
+func resolveGamma(mode GammaMode, gamma float64, x [][]float64) (float64, error) {
+	switch mode {
+	case GammaValue, "":
+		return gamma, nil
+	case GammaAuto:
+		return 1 / float64(len(x[0])), nil
+	case GammaScale:
+		// Дисперсию считаем в два прохода, чтобы не терять точность на больших значениях признаков.
+		var sum float64
+		n := 0
+		for i := range x {
+			for _, value := range x[i] {
+				sum += value
+				n++
+			}
+		}
+		mean := sum / float64(n)
+		var variance float64
+		for i := range x {
+			for _, value := range x[i] {
+				variance += (value - mean) * (value - mean)
+			}
+		}
+		variance /= float64(n)
+		if variance <= 0 {
+			return 1, nil
+		}
+		return 1 / (float64(len(x[0])) * variance), nil
+	default:
+		return 0, fmt.Errorf("unknown gamma mode: %s", mode)
+	}
+}
+
+// KernelNames возвращает имена всех зарегистрированных ядер в отсортированном порядке.
+func KernelNames() []KernelName {
+	kernelRegistryMu.RLock()
+	defer kernelRegistryMu.RUnlock()
+
+	res := make([]KernelName, 0, len(kernelRegistry))
+	for name := range kernelRegistry {
+		res = append(res, name)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// Удостоверяемся, что структура LinearKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*LinearKernel)(nil)
 
 // LinearKernel представляет линейное ядро.
 type LinearKernel struct{}
@@ -31,27 +261,44 @@ func (k *LinearKernel) Calculate(x, y []float64) float64 {
 	return vector_operations.ScalarProduct(x, y)
 }
 
-// Удостоверяемся, что структура PolyKernel удовлетворяет интерфейсу Kernel.
-var _ Kernel = (*PolyKernel)(nil)
+// Params возвращает гиперпараметры ядра. У ядра нет гиперпараметров.
+func (k *LinearKernel) Params() KernelParams {
+	return KernelParams{}
+}
+
+// Удостоверяемся, что структура PolyKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*PolyKernel)(nil)
 
 // PolyKernel представляет собой полиномиальное ядро.
 type PolyKernel struct {
+	// Масштаб скалярного произведения. Нулевое значение означает gamma = 1.
+	Gamma  float64
 	Coef0  float64
 	Degree int
 }
 
 // Calculate считает произведение двух векторов
-// по формуле z = (<x, y> + r)^d, где:
-// 1. <x, y> - скалярное произведение векторов;
-// 2. r - свободный член;
-// 3. d - степень полинома.
+// по формуле z = (gamma * <x, y> + r)^d, где:
+// 1. gamma - масштаб скалярного произведения (1, если поле Gamma не задано);
+// 2. <x, y> - скалярное произведение векторов;
+// 3. r - свободный член;
+// 4. d - степень полинома.
 func (k *PolyKernel) Calculate(x, y []float64) float64 {
+	gamma := k.Gamma
+	if gamma == 0 {
+		gamma = 1
+	}
 	scalarProduct := vector_operations.ScalarProduct(x, y)
-	return math.Pow(scalarProduct+k.Coef0, float64(k.Degree))
+	return math.Pow(gamma*scalarProduct+k.Coef0, float64(k.Degree))
+}
+
+// Params возвращает гиперпараметры ядра.
+func (k *PolyKernel) Params() KernelParams {
+	return KernelParams{Degree: k.Degree, Coef0: k.Coef0, Gamma: k.Gamma}
 }
 
-// Удостоверяемся, что структура RbfKernel удовлетворяет интерфейсу Kernel.
-var _ Kernel = (*RbfKernel)(nil)
+// Удостоверяемся, что структура RbfKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*RbfKernel)(nil)
 
 // RbfKernel представляет собой ядро rbf - radial basic function.
 type RbfKernel struct {
@@ -64,3 +311,118 @@ func (k *RbfKernel) Calculate(x, y []float64) float64 {
 	euclideanDistance := vector_operations.EuclideanDistance(x, y)
 	return math.Exp(-k.Gamma * math.Pow(euclideanDistance, 2))
 }
+
+// Params возвращает гиперпараметры ядра.
+func (k *RbfKernel) Params() KernelParams {
+	return KernelParams{Gamma: k.Gamma}
+}
+
+// Удостоверяемся, что структура SigmoidKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*SigmoidKernel)(nil)
+
+// SigmoidKernel представляет собой сигмоидное ядро.
+// Ядро не является положительно полуопределенным при всех значениях параметров.
+type SigmoidKernel struct {
+	Gamma float64
+	Coef0 float64
+}
+
+// Calculate считает произведение двух векторов
+// по формуле z = tanh(gamma * <x, y> + r).
+func (k *SigmoidKernel) Calculate(x, y []float64) float64 {
+	return math.Tanh(k.Gamma*vector_operations.ScalarProduct(x, y) + k.Coef0)
+}
+
+// Params возвращает гиперпараметры ядра.
+func (k *SigmoidKernel) Params() KernelParams {
+	return KernelParams{Gamma: k.Gamma, Coef0: k.Coef0}
+}
+
+// Удостоверяемся, что структура LaplacianKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*LaplacianKernel)(nil)
+
+// LaplacianKernel представляет собой ядро Лапласа.
+type LaplacianKernel struct {
+	Gamma float64
+}
+
+// Calculate считает произведение двух векторов
+// по формуле z = exp{-gamma * |x - y|_1}, где |x - y|_1 - манхэттенское расстояние.
+func (k *LaplacianKernel) Calculate(x, y []float64) float64 {
+	return math.Exp(-k.Gamma * vector_operations.ManhattanDistance(x, y))
+}
+
+// Params возвращает гиперпараметры ядра.
+func (k *LaplacianKernel) Params() KernelParams {
+	return KernelParams{Gamma: k.Gamma}
+}
+
+// Удостоверяемся, что структура Chi2Kernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*Chi2Kernel)(nil)
+
+// Chi2Kernel представляет собой экспоненциальное ядро хи-квадрат для гистограммных признаков.
+// Признаки должны быть неотрицательными.
+type Chi2Kernel struct {
+	Gamma float64
+}
+
+// Calculate считает произведение двух векторов
+// по формуле z = exp{-gamma * sum((x[i] - y[i])^2 / (x[i] + y[i]))}.
+// Слагаемые с x[i] + y[i] = 0 пропускаются.
+func (k *Chi2Kernel) Calculate(x, y []float64) float64 {
+	var sum float64
+	for i := range x {
+		if denom := x[i] + y[i]; denom != 0 {
+			sum += (x[i] - y[i]) * (x[i] - y[i]) / denom
+		}
+	}
+	return math.Exp(-k.Gamma * sum)
+}
+
+// Params возвращает гиперпараметры ядра.
+func (k *Chi2Kernel) Params() KernelParams {
+	return KernelParams{Gamma: k.Gamma}
+}
+
+// Удостоверяемся, что структура CosineKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*CosineKernel)(nil)
+
+// CosineKernel представляет собой косинусное ядро - скалярное произведение нормированных векторов.
+type CosineKernel struct{}
+
+// Calculate считает произведение двух векторов
+// по формуле z = <x, y> / (|x| * |y|). Для нулевого вектора результат равен 0.
+func (k *CosineKernel) Calculate(x, y []float64) float64 {
+	norms := math.Sqrt(vector_operations.ScalarProduct(x, x) * vector_operations.ScalarProduct(y, y))
+	if norms == 0 {
+		return 0
+	}
+	return vector_operations.ScalarProduct(x, y) / norms
+}
+
+// Params возвращает гиперпараметры ядра. У ядра нет гиперпараметров.
+func (k *CosineKernel) Params() KernelParams {
+	return KernelParams{}
+}
+
+// Удостоверяемся, что структура RationalQuadraticKernel удовлетворяет интерфейсу ParametrizedKernel.
+var _ ParametrizedKernel = (*RationalQuadraticKernel)(nil)
+
+// RationalQuadraticKernel представляет собой рациональное квадратичное ядро - смесь ядер rbf
+// с разными масштабами. При alpha -> +inf оно совпадает с ядром rbf с тем же gamma.
+type RationalQuadraticKernel struct {
+	Gamma float64
+	Alpha float64
+}
+
+// Calculate считает произведение двух векторов
+// по формуле z = (1 + gamma * |x - y|^2 / alpha)^(-alpha), gamma > 0, alpha > 0.
+func (k *RationalQuadraticKernel) Calculate(x, y []float64) float64 {
+	euclideanDistance := vector_operations.EuclideanDistance(x, y)
+	return math.Pow(1+k.Gamma*euclideanDistance*euclideanDistance/k.Alpha, -k.Alpha)
+}
+
+// Params возвращает гиперпараметры ядра.
+func (k *RationalQuadraticKernel) Params() KernelParams {
+	return KernelParams{Gamma: k.Gamma, Alpha: k.Alpha}
+}
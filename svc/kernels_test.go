@@ -2,6 +2,8 @@ package svc
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -36,6 +38,7 @@ func TestLinearKernel_Calculate(t *testing.T) {
 
 func TestPolyKernel_Calculate(t *testing.T) {
 	type fields struct {
+		Gamma  float64
 		Coef0  float64
 		Degree int
 	}
@@ -97,10 +100,39 @@ func TestPolyKernel_Calculate(t *testing.T) {
 			},
 			want: "58170703201.17",
 		},
+		{
+			name: "Test gamma",
+			fields: fields{
+				Gamma:  0.5,
+				Coef0:  1,
+				Degree: 2,
+			},
+			args: args{
+				x: []float64{1, 2},
+				y: []float64{3, 4},
+			},
+			// (0.5 * 11 + 1)^2 = 42.25
+			want: "42.25",
+		},
+		{
+			name: "Test gamma greater than 1",
+			fields: fields{
+				Gamma:  2,
+				Coef0:  0,
+				Degree: 3,
+			},
+			args: args{
+				x: []float64{1, 2},
+				y: []float64{3, 4},
+			},
+			// (2 * 11)^3 = 10648
+			want: "10648.00",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			k := &PolyKernel{
+				Gamma:  tt.fields.Gamma,
 				Coef0:  tt.fields.Coef0,
 				Degree: tt.fields.Degree,
 			}
@@ -170,3 +202,227 @@ func TestRbfKernel_Calculate(t *testing.T) {
 		})
 	}
 }
+
+func TestKernels_Calculate(t *testing.T) {
+	type args struct {
+		x []float64
+		y []float64
+	}
+	tests := []struct {
+		name   string
+		kernel Kernel
+		args   args
+		want   string
+	}{
+		{
+			name:   "Test sigmoid",
+			kernel: &SigmoidKernel{Gamma: 0.1, Coef0: 0},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.46",
+		},
+		{
+			name:   "Test sigmoid with coef0",
+			kernel: &SigmoidKernel{Gamma: 0.1, Coef0: 1},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.91",
+		},
+		{
+			name:   "Test laplacian",
+			kernel: &LaplacianKernel{Gamma: 0.5},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.08",
+		},
+		{
+			name:   "Test chi2",
+			kernel: &Chi2Kernel{Gamma: 1},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.04",
+		},
+		{
+			name:   "Test chi2 with zero bins",
+			kernel: &Chi2Kernel{Gamma: 0.1},
+			args:   args{x: []float64{0, 1, 2, 3}, y: []float64{0, 2, 0, 1}},
+			want:   "0.72",
+		},
+		{
+			name:   "Test cosine",
+			kernel: &CosineKernel{},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.60",
+		},
+		{
+			name:   "Test cosine with zero vector",
+			kernel: &CosineKernel{},
+			args:   args{x: []float64{0, 0, 0}, y: []float64{2, 0, 1}},
+			want:   "0.00",
+		},
+		{
+			name:   "Test rational quadratic",
+			kernel: &RationalQuadraticKernel{Gamma: 1, Alpha: 1},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.10",
+		},
+		{
+			name:   "Test rational quadratic with alpha",
+			kernel: &RationalQuadraticKernel{Gamma: 0.5, Alpha: 2},
+			args:   args{x: []float64{1, 2, 3}, y: []float64{2, 0, 1}},
+			want:   "0.09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.kernel.Calculate(tt.args.x, tt.args.y); fmt.Sprintf("%.2f", got) != tt.want {
+				t.Errorf("Calculate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// constantKernel - пользовательское ядро для проверки реестра.
+type constantKernel struct {
+	value float64
+}
+
+func (k *constantKernel) Calculate(x, y []float64) float64 {
+	return k.value
+}
+
+// unregisterKernel удаляет ядро из реестра, чтобы тест можно было запускать повторно.
+func unregisterKernel(name KernelName) {
+	kernelRegistryMu.Lock()
+	defer kernelRegistryMu.Unlock()
+	delete(kernelRegistry, KernelName(strings.ToLower(string(name))))
+}
+
+func TestRegisterKernel(t *testing.T) {
+	factory := func(params KernelParams) (Kernel, error) {
+		return &constantKernel{value: params.Coef0}, nil
+	}
+	if err := RegisterKernel("Test_Constant", factory); err != nil {
+		t.Fatalf("RegisterKernel() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterKernel("Test_Constant") })
+
+	tests := []struct {
+		name    string
+		kernel  KernelName
+		factory KernelFactory
+	}{
+		{
+			name:    "Test duplicate",
+			kernel:  "test_constant",
+			factory: factory,
+		},
+		{
+			name:    "Test built-in duplicate",
+			kernel:  Rbf,
+			factory: factory,
+		},
+		{
+			name:    "Test empty name",
+			kernel:  "",
+			factory: factory,
+		},
+		{
+			name:    "Test nil factory",
+			kernel:  "test_nil",
+			factory: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterKernel(tt.kernel, tt.factory); err == nil {
+				t.Errorf("RegisterKernel() error = nil, want error")
+			}
+		})
+	}
+
+	// Пользовательское ядро выбирается по имени так же, как встроенные.
+	svc := NewSVC()
+	svc.Coef0 = 2
+	if err := svc.SetKernelByName("TEST_CONSTANT"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	if got := svc.Kernel.Calculate(nil, nil); got != 2 {
+		t.Errorf("Calculate() = %v, want 2", got)
+	}
+	if err := svc.SetKernelByName("unknown"); err == nil {
+		t.Errorf("SetKernelByName() error = nil, want error")
+	}
+
+	found := false
+	for _, name := range KernelNames() {
+		if name == "test_constant" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("KernelNames() = %v, want test_constant among them", KernelNames())
+	}
+}
+
+func Test_resolveGamma(t *testing.T) {
+	x := [][]float64{{1, 2}, {3, 4}}
+	tests := []struct {
+		name    string
+		mode    GammaMode
+		gamma   float64
+		x       [][]float64
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:  "Test value mode",
+			mode:  GammaValue,
+			gamma: 0.3,
+			x:     x,
+			want:  0.3,
+		},
+		{
+			name:  "Test empty mode",
+			mode:  "",
+			gamma: 0.3,
+			x:     x,
+			want:  0.3,
+		},
+		{
+			name:  "Test auto mode",
+			mode:  GammaAuto,
+			gamma: 0.3,
+			x:     x,
+			want:  0.5,
+		},
+		{
+			// Дисперсия элементов 1, 2, 3, 4 равна 1.25.
+			name:  "Test scale mode",
+			mode:  GammaScale,
+			gamma: 0.3,
+			x:     x,
+			want:  1 / (2 * 1.25),
+		},
+		{
+			name:  "Test scale mode with constant features",
+			mode:  GammaScale,
+			gamma: 0.3,
+			x:     [][]float64{{5, 5}, {5, 5}},
+			want:  1,
+		},
+		{
+			name:    "Test unknown mode",
+			mode:    "unknown",
+			x:       x,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveGamma(tt.mode, tt.gamma, tt.x)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveGamma() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("resolveGamma() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
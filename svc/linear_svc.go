@@ -0,0 +1,497 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jinzhu/copier"
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+	"golang.org/x/sync/errgroup"
+)
+
+// Проверим, что структура LinearSVC удовлетворяет интерфейсам Classifier и Scorer.
+var (
+	_ svm.Classifier        = (*LinearSVC)(nil)
+	_ svm.Scorer            = (*LinearSVC)(nil)
+	_ svm.ContextClassifier = (*LinearSVC)(nil)
+)
+
+// Penalty тип для регуляризатора линейного SVM.
+type Penalty string
+
+// Определяем в константах существующие регуляризаторы.
+const (
+	// L1 - регуляризатор ||w||_1, который приводит к разреженному вектору весов.
+	L1 Penalty = "l1"
+	// L2 - регуляризатор 0.5 * ||w||^2.
+	L2 Penalty = "l2"
+)
+
+// Loss тип для функции потерь линейного SVM.
+type Loss string
+
+// Определяем в константах существующие функции потерь.
+const (
+	// Hinge - кусочно-линейная функция потерь max(0, 1 - y * f(x)).
+	Hinge Loss = "hinge"
+	// SquaredHinge - квадрат кусочно-линейной функции потерь max(0, 1 - y * f(x))^2.
+	SquaredHinge Loss = "squared_hinge"
+)
+
+// LinearSVC (англ. Linear Support Vector Classifier) - структура для представления
+// линейного классификатора методом опорных векторов.
+// В отличие от SVC с линейным ядром, матрица Грама не строится: задача решается методом
+// покоординатного спуска, а модель хранит явный вектор весов. Поэтому память и время обучения
+// растут линейно с размером обучающей выборки.
+// Для числа классов больше 2 используется метод один против всех (OvR).
+type LinearSVC struct {
+	// Регуляризатор.
+	Penalty Penalty
+
+	// Функция потерь.
+	Loss Loss
+
+	// Параметр регуляризации.
+	C float64
+
+	// Обучать ли свободный член.
+	// Свободный член обучается как вес дополнительного признака, равного InterceptScaling,
+	// поэтому он тоже регуляризуется.
+	FitIntercept bool
+
+	// Значение дополнительного признака для обучения свободного члена.
+	InterceptScaling float64
+
+	// Точность.
+	Tol float64
+
+	// Максимальное количество проходов по обучающей выборке.
+	MaxIters int
+
+	// Зерно генератора случайных чисел, которым перемешивается порядок обхода объектов в двойственном
+	// покоординатном спуске. При одинаковом зерне и одинаковых данных получается одна и та же модель.
+	Seed int64
+
+	// Логгер для сообщений о ходе обучения. Если равен nil, то сообщения не выводятся.
+	Logger svm.Logger
+
+	// Веса признаков: по одной строке на каждый бинарный классификатор.
+	coef [][]float64
+
+	// Свободные члены бинарных классификаторов.
+	intercept []float64
+
+	// Слайс уникальных меток обучающего набора.
+	labels []int
+
+	// Число характеристик обучающей выборки
+	nFeatures int
+}
+
+// NewLinearSVC возвращает экземпляр LinearSVC с параметрами по умолчанию.
+func NewLinearSVC() *LinearSVC {
+	return &LinearSVC{
+		Penalty:          L2,
+		Loss:             SquaredHinge,
+		C:                1.0,
+		FitIntercept:     true,
+		InterceptScaling: 1.0,
+		Tol:              0.001,
+		MaxIters:         1000,
+		Seed:             1,
+		Logger:           nil,
+		coef:             nil,
+		intercept:        nil,
+		labels:           nil,
+		nFeatures:        0,
+	}
+}
+
+// Fit обучает алгоритм на обучающей выборке.
+// x - матрица признаков.
+// y - слайс меток.
+// Для двух классов обучается один классификатор, в котором положительным считается класс с большей меткой.
+func (l *LinearSVC) Fit(x [][]float64, y []int) error {
+	return l.FitContext(context.Background(), x, y)
+}
+
+// FitContext обучает алгоритм на обучающей выборке.
+// Если контекст отменен или истек его срок, то обучение всех бинарных классификаторов прерывается
+// и возвращается ошибка контекста.
+// x - матрица признаков.
+// y - слайс меток.
+func (l *LinearSVC) FitContext(ctx context.Context, x [][]float64, y []int) error {
+	// Проверим валидность входных данных.
+	if err := l.validateInput(x, y); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+
+	l.labels = vector_operations.GetUniques(y)
+	l.nFeatures = len(x[0])
+
+	// Для бинарной задачи достаточно одного классификатора.
+	positives := l.labels
+	if len(l.labels) == 2 {
+		positives = l.labels[1:]
+	}
+	l.coef = make([][]float64, len(positives))
+	l.intercept = make([]float64, len(positives))
+
+	l.logger().Info("Linear SVM fitting started", "samples", len(x), "features", l.nFeatures)
+
+	// Каждый бинарный классификатор обучается в отдельной горутине.
+	// Горутины пишут в разные элементы слайсов, поэтому мьютекс не нужен.
+	eg, ctx := errgroup.WithContext(ctx)
+	for k, label := range positives {
+		k, label := k, label
+		eg.Go(func() error {
+			yTmp := make([]float64, len(y))
+			for i := range y {
+				if y[i] == label {
+					yTmp[i] = +1
+				} else {
+					yTmp[i] = -1
+				}
+			}
+
+			var w []float64
+			var err error
+			switch l.Penalty {
+			case L2:
+				w, err = l.dualCD(ctx, x, yTmp)
+			case L1:
+				w, err = l.primalL1CD(ctx, x, yTmp)
+			}
+			if err != nil {
+				return err
+			}
+
+			l.coef[k] = w[:l.nFeatures]
+			if l.FitIntercept {
+				l.intercept[k] = w[l.nFeatures] * l.InterceptScaling
+			}
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
+
+// validateInput проверяет валидность входных данных и параметров для обучения.
+func (l *LinearSVC) validateInput(x [][]float64, y []int) error {
+	switch {
+	case l.Penalty != L1 && l.Penalty != L2:
+		return fmt.Errorf("unknown penalty: %s", l.Penalty)
+	case l.Loss != Hinge && l.Loss != SquaredHinge:
+		return fmt.Errorf("unknown loss: %s", l.Loss)
+	case l.Penalty == L1 && l.Loss == Hinge:
+		return fmt.Errorf("the combination of %s penalty and %s loss is not supported", l.Penalty, l.Loss)
+	case l.C <= 0:
+		return fmt.Errorf("C must be positive, actual: %v", l.C)
+	}
+
+	if len(x) == 0 {
+		return fmt.Errorf("training set is empty")
+	}
+
+	// Проверим, что матрица признаков является прямоугольной.
+	if !vector_operations.IsMatrixRectangular(x) {
+		return fmt.Errorf("feature matrix must be rectangular")
+	}
+
+	// Проверим, что все данные размечены
+	if len(x) != len(y) {
+		return fmt.Errorf("not all data is labeled")
+	}
+
+	// Для классификации нужно хотя бы 2 класса.
+	if nClasses := vector_operations.CountOfUniques(y); nClasses < 2 {
+		return fmt.Errorf("incorrect number of class labels: expected at least 2, actual: %d", nClasses)
+	}
+
+	return nil
+}
+
+// dot вычисляет скалярное произведение вектора весов w и объекта x,
+// дополненного признаком для свободного члена.
+func (l *LinearSVC) dot(w, x []float64) float64 {
+	res := vector_operations.ScalarProduct(w[:l.nFeatures], x)
+	if l.FitIntercept {
+		res += w[l.nFeatures] * l.InterceptScaling
+	}
+	return res
+}
+
+// dualCD решает двойственную задачу линейного SVM с L2-регуляризатором методом покоординатного спуска
+// (Hsieh et al., "A dual coordinate descent method for large-scale linear SVM", 2008):
+// min 0.5 * a^T * (Q + D) * a - e^T * a, 0 <= a[i] <= U,
+// где для hinge: U = C, D = 0, а для squared hinge: U = +inf, D = 1 / (2C).
+// Вектор весов w = sum(a[i] * y[i] * x[i]) поддерживается явно, поэтому матрица Q не хранится.
+// Возвращает вектор весов, дополненный весом признака для свободного члена, или ошибку отмененного контекста.
+func (l *LinearSVC) dualCD(ctx context.Context, x [][]float64, y []float64) ([]float64, error) {
+	n := len(x)
+	upper, diag := l.C, 0.0
+	if l.Loss == SquaredHinge {
+		upper, diag = math.Inf(1), 1/(2*l.C)
+	}
+
+	// Диагональ матрицы Q + D.
+	qd := make([]float64, n)
+	for i := range x {
+		qd[i] = vector_operations.ScalarProduct(x[i], x[i]) + diag
+		if l.FitIntercept {
+			qd[i] += l.InterceptScaling * l.InterceptScaling
+		}
+	}
+
+	w := make([]float64, l.nFeatures+1)
+	alpha := make([]float64, n)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	// Порядок обхода перемешивается собственным генератором с зерном Seed, чтобы результат был воспроизводимым.
+	rnd := rand.New(rand.NewSource(l.Seed))
+
+	iter := 0
+	for ; iter < l.MaxIters; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rnd.Shuffle(n, func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+
+		// Максимальное и минимальное значения проекции градиента за проход.
+		pgMax, pgMin := math.Inf(-1), math.Inf(1)
+		for _, i := range perm {
+			g := y[i]*l.dot(w, x[i]) - 1 + diag*alpha[i]
+
+			pg := g
+			switch {
+			case alpha[i] == 0:
+				pg = math.Min(g, 0)
+			case alpha[i] == upper:
+				pg = math.Max(g, 0)
+			}
+			pgMax = math.Max(pgMax, pg)
+			pgMin = math.Min(pgMin, pg)
+
+			if math.Abs(pg) < 1e-12 {
+				continue
+			}
+			old := alpha[i]
+			alpha[i] = math.Min(math.Max(alpha[i]-g/qd[i], 0), upper)
+			if delta := (alpha[i] - old) * y[i]; delta != 0 {
+				for j := range x[i] {
+					w[j] += delta * x[i][j]
+				}
+				if l.FitIntercept {
+					w[l.nFeatures] += delta * l.InterceptScaling
+				}
+			}
+		}
+
+		// Условия ККТ выполнены с точностью Tol.
+		if pgMax-pgMin < l.Tol {
+			break
+		}
+	}
+	l.logger().Info("Dual coordinate descent finished", "iterations", iter)
+
+	return w, nil
+}
+
+// primalL1CD решает прямую задачу линейного SVM с L1-регуляризатором и квадратичной кусочно-линейной
+// функцией потерь методом покоординатного спуска с шагом Ньютона и линейным поиском
+// (Yuan et al., "A comparison of optimization methods and software for large-scale L1-regularized
+// linear classification", 2010):
+// min ||w||_1 + C * sum(max(0, 1 - y[i] * w^T * x[i])^2).
+// Возвращает вектор весов, дополненный весом признака для свободного члена, или ошибку отмененного контекста.
+func (l *LinearSVC) primalL1CD(ctx context.Context, x [][]float64, y []float64) ([]float64, error) {
+	const (
+		sigma      = 0.01
+		beta       = 0.5
+		maxSearch  = 30
+		minHessian = 1e-12
+	)
+
+	n := len(x)
+	nWeights := l.nFeatures
+	if l.FitIntercept {
+		nWeights++
+	}
+	// feature возвращает j-ый признак i-ого объекта с учетом признака для свободного члена.
+	feature := func(i, j int) float64 {
+		if j == l.nFeatures {
+			return l.InterceptScaling
+		}
+		return x[i][j]
+	}
+
+	w := make([]float64, l.nFeatures+1)
+	// b[i] = 1 - y[i] * w^T * x[i] поддерживается инкрементально.
+	b := make([]float64, n)
+	for i := range b {
+		b[i] = 1
+	}
+
+	initViolation := 0.0
+	iter := 0
+	for ; iter < l.MaxIters; iter++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		maxViolation := 0.0
+		for j := 0; j < nWeights; j++ {
+			// Первая и вторая производные функции потерь по w[j].
+			g, h := 0.0, minHessian
+			for i := 0; i < n; i++ {
+				if b[i] <= 0 {
+					continue
+				}
+				xij := feature(i, j)
+				g -= 2 * l.C * y[i] * xij * b[i]
+				h += 2 * l.C * xij * xij
+			}
+
+			// Нарушение условий оптимальности по субградиенту ||w||_1.
+			var violation float64
+			switch {
+			case w[j] > 0:
+				violation = math.Abs(g + 1)
+			case w[j] < 0:
+				violation = math.Abs(g - 1)
+			default:
+				violation = math.Max(math.Max(g-1, -g-1), 0)
+			}
+			maxViolation = math.Max(maxViolation, violation)
+
+			// Шаг Ньютона для квадратичной аппроксимации с L1-регуляризатором.
+			var d float64
+			switch {
+			case g+1 <= h*w[j]:
+				d = -(g + 1) / h
+			case g-1 >= h*w[j]:
+				d = -(g - 1) / h
+			default:
+				d = -w[j]
+			}
+			if math.Abs(d) < 1e-12 {
+				continue
+			}
+
+			// Линейный поиск по правилу Армихо.
+			loss := 0.0
+			for i := 0; i < n; i++ {
+				if b[i] > 0 {
+					loss += b[i] * b[i]
+				}
+			}
+			delta := g*d + math.Abs(w[j]+d) - math.Abs(w[j])
+			step := 1.0
+			for search := 0; search < maxSearch; search++ {
+				newLoss := 0.0
+				for i := 0; i < n; i++ {
+					if bi := b[i] - step*d*y[i]*feature(i, j); bi > 0 {
+						newLoss += bi * bi
+					}
+				}
+				change := l.C*(newLoss-loss) + math.Abs(w[j]+step*d) - math.Abs(w[j])
+				if change <= sigma*step*delta {
+					break
+				}
+				step *= beta
+			}
+
+			w[j] += step * d
+			for i := 0; i < n; i++ {
+				b[i] -= step * d * y[i] * feature(i, j)
+			}
+		}
+
+		if iter == 0 {
+			initViolation = maxViolation
+		}
+		// Критерий остановки относительно нарушения в начальной точке.
+		if maxViolation <= l.Tol*initViolation {
+			break
+		}
+	}
+	l.logger().Info("Primal coordinate descent finished", "iterations", iter)
+
+	return w, nil
+}
+
+// logger возвращает логгер классификатора или, если он не задан, логгер, который ничего не выводит.
+func (l *LinearSVC) logger() svm.Logger {
+	return loggerOrNop(l.Logger)
+}
+
+// Predict классифицирует новые входные данные на основе обученной модели.
+// x - матрица признаков.
+func (l *LinearSVC) Predict(x [][]float64) []int {
+	res := make([]int, len(x))
+	for i, scores := range l.DecisionFunction(x) {
+		// Для бинарной задачи знак решающей функции определяет класс.
+		if len(scores) == 1 {
+			if scores[0] >= 0 {
+				res[i] = l.labels[1]
+			} else {
+				res[i] = l.labels[0]
+			}
+			continue
+		}
+
+		// Найдем класс с наибольшим значением решающей функции
+		best := 0
+		for k := range scores {
+			if scores[k] > scores[best] {
+				best = k
+			}
+		}
+		res[i] = l.labels[best]
+	}
+	return res
+}
+
+// DecisionFunction возвращает значения решающей функции для входных данных.
+// Для бинарной задачи каждая строка результата состоит из одного значения: положительное значение
+// соответствует классу с большей меткой. Иначе в строке по одному значению на каждый класс в порядке Classes.
+// x - матрица признаков.
+func (l *LinearSVC) DecisionFunction(x [][]float64) [][]float64 {
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = make([]float64, len(l.coef))
+		for k := range l.coef {
+			res[i][k] = vector_operations.ScalarProduct(l.coef[k], x[i]) + l.intercept[k]
+		}
+	}
+	return res
+}
+
+// Classes возвращает метки классов в отсортированном порядке.
+func (l *LinearSVC) Classes() []int {
+	return l.labels
+}
+
+// Coef возвращает веса признаков: по одной строке на каждый бинарный классификатор.
+// Для бинарной задачи строка одна и соответствует классу с большей меткой.
+func (l *LinearSVC) Coef() [][]float64 {
+	return l.coef
+}
+
+// Intercept возвращает свободные члены бинарных классификаторов.
+func (l *LinearSVC) Intercept() []float64 {
+	return l.intercept
+}
+
+// Clone возвращает копию линейного классификатора.
+func (l *LinearSVC) Clone() (svm.Classifier, error) {
+	res := &LinearSVC{}
+	if err := copier.Copy(res, l); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
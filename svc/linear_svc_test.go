@@ -0,0 +1,100 @@
+package svc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ziyadovea/svm/pkg/classification_metrics/multiclass_metrics"
+)
+
+func TestLinearSVC_Fit(t *testing.T) {
+	tests := []struct {
+		name    string
+		penalty Penalty
+		loss    Loss
+	}{
+		{
+			name:    "Test l2 hinge",
+			penalty: L2,
+			loss:    Hinge,
+		},
+		{
+			name:    "Test l2 squared hinge",
+			penalty: L2,
+			loss:    SquaredHinge,
+		},
+		{
+			name:    "Test l1 squared hinge",
+			penalty: L1,
+			loss:    SquaredHinge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLinearSVC()
+			l.Penalty = tt.penalty
+			l.Loss = tt.loss
+
+			// Бинарная задача: один вектор весов, метки восстанавливаются как есть.
+			if err := l.Fit(separableX, separableY); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if len(l.Coef()) != 1 || len(l.Coef()[0]) != len(separableX[0]) {
+				t.Errorf("Coef() = %v, want a single row of %d weights", l.Coef(), len(separableX[0]))
+			}
+			if got := l.Predict(separableX); !reflect.DeepEqual(got, separableY) {
+				t.Errorf("Predict() = %v, want %v", got, separableY)
+			}
+
+			// Многоклассовая задача: по одному вектору весов на каждый класс.
+			x, y := loadIris(t)
+			if err := l.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if len(l.Coef()) != 3 || len(l.Intercept()) != 3 {
+				t.Errorf("len(Coef()) = %d, len(Intercept()) = %d, want 3", len(l.Coef()), len(l.Intercept()))
+			}
+			if accuracy := multiclass_metrics.Accuracy(y, l.Predict(x)); accuracy < 0.9 {
+				t.Errorf("training accuracy = %v, want at least 0.9", accuracy)
+			}
+		})
+	}
+}
+
+func TestLinearSVC_FitErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		penalty Penalty
+		loss    Loss
+		y       []int
+	}{
+		{
+			name:    "Test l1 hinge",
+			penalty: L1,
+			loss:    Hinge,
+			y:       separableY,
+		},
+		{
+			name:    "Test unknown loss",
+			penalty: L2,
+			loss:    "unknown",
+			y:       separableY,
+		},
+		{
+			name:    "Test single class",
+			penalty: L2,
+			loss:    SquaredHinge,
+			y:       []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLinearSVC()
+			l.Penalty = tt.penalty
+			l.Loss = tt.loss
+			if err := l.Fit(separableX, tt.y); err == nil {
+				t.Errorf("Fit() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package svc
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ziyadovea/svm"
+)
+
+// Progress описывает состояние солвера после очередной итерации.
+type Progress struct {
+	// Номер итерации, начиная с 1.
+	Iter int
+	// Нарушение условий ККТ: обучение завершается, когда оно становится меньше Tol.
+	KKTViolation float64
+	// Значение целевой функции двойственной задачи в форме минимизации.
+	// При включенном сжатии градиент исключенных переменных не обновляется, поэтому значение приближенное.
+	Objective float64
+	// Количество опорных векторов - переменных с ненулевым значением альфа.
+	NSupport int
+}
+
+// Callback - функция, которую солвер вызывает после каждой итерации.
+type Callback func(progress Progress)
+
+// synchronizedCallback возвращает функцию, которая вызывает callback под мьютексом.
+// Нужна, когда одну функцию вызывают солверы, работающие в разных горутинах.
+func synchronizedCallback(callback Callback) Callback {
+	if callback == nil {
+		return nil
+	}
+	mu := sync.Mutex{}
+	return func(progress Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		callback(progress)
+	}
+}
+
+// nopLogger - логгер, который ничего не выводит. Используется, если логгер не задан.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// logSMOResult выводит число итераций и значение целевой функции решения res задачи QP,
+// а если решение остановлено по ограничению на количество итераций - предупреждение об этом.
+func logSMOResult(logger svm.Logger, res smoResult, maxIters int) {
+	logger.Info("SMO finished", "iterations", res.iters, "objective", res.obj)
+	if !res.converged {
+		logger.Warn("SMO reached the maximum number of iterations without convergence, consider increasing MaxIters",
+			"iterations", res.iters, "max_iters", maxIters)
+	}
+}
+
+// loggerOrNop возвращает logger или, если он не задан, логгер, который ничего не выводит.
+func loggerOrNop(logger svm.Logger) svm.Logger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	return logger
+}
+
+// StdLogger - адаптер стандартного логгера log.Logger к интерфейсу svm.Logger.
+// Выводит уровень, сообщение и пары ключ=значение одной строкой.
+type StdLogger struct {
+	// Стандартный логгер. Если равен nil, то используется log.Default().
+	Logger *log.Logger
+	// Выводить ли сообщения уровня Debug.
+	Verbose bool
+}
+
+// NewStdLogger возвращает адаптер стандартного логгера logger, который не выводит сообщения уровня Debug.
+func NewStdLogger(logger *log.Logger) *StdLogger {
+	return &StdLogger{
+		Logger:  logger,
+		Verbose: false,
+	}
+}
+
+// Debug выводит отладочное сообщение, если Verbose = true.
+func (l *StdLogger) Debug(msg string, args ...any) {
+	if l.Verbose {
+		l.print("DEBUG", msg, args)
+	}
+}
+
+// Info выводит информационное сообщение.
+func (l *StdLogger) Info(msg string, args ...any) {
+	l.print("INFO", msg, args)
+}
+
+// Warn выводит предупреждение.
+func (l *StdLogger) Warn(msg string, args ...any) {
+	l.print("WARN", msg, args)
+}
+
+// Error выводит сообщение об ошибке.
+func (l *StdLogger) Error(msg string, args ...any) {
+	l.print("ERROR", msg, args)
+}
+
+// print выводит строку вида "LEVEL msg key1=value1 key2=value2".
+// Значение без ключа выводится с ключом !BADKEY, как в log/slog.
+func (l *StdLogger) print(level, msg string, args []any) {
+	var sb strings.Builder
+	sb.WriteString(level)
+	sb.WriteString(" ")
+	sb.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			sb.WriteString(fmt.Sprintf(" !BADKEY=%v", args[i]))
+			break
+		}
+		sb.WriteString(fmt.Sprintf(" %v=%v", args[i], args[i+1]))
+	}
+
+	logger := l.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Println(sb.String())
+}
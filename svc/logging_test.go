@@ -0,0 +1,316 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/classification_metrics"
+	"github.com/ziyadovea/svm/pkg/cross_validation"
+)
+
+func TestFitContextCanceled(t *testing.T) {
+	x, y := loadIris(t)
+	yReg := make([]float64, len(y))
+	for i := range y {
+		yReg[i] = float64(y[i])
+	}
+	xBin, yBin := loadIrisBinary(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		fit  func() error
+	}{
+		{
+			name: "Test SVC",
+			fit:  func() error { return NewSVC().FitContext(ctx, xBin, yBin) },
+		},
+		{
+			name: "Test SVC with simplified SMO",
+			fit: func() error {
+				svc := NewSVC()
+				svc.Solver = SimplifiedSMO
+				return svc.FitContext(ctx, xBin, yBin)
+			},
+		},
+		{
+			name: "Test MultiSVC OvR",
+			fit:  func() error { return NewMultiSVC().FitContext(ctx, x, y) },
+		},
+		{
+			name: "Test MultiSVC OvO with probabilities",
+			fit: func() error {
+				m := NewMultiSVC()
+				m.Strategy = OvO
+				m.Probability = true
+				return m.FitContext(ctx, x, y)
+			},
+		},
+		{
+			name: "Test SVR",
+			fit:  func() error { return NewSVR().FitContext(ctx, x, yReg) },
+		},
+		{
+			name: "Test OneClassSVM",
+			fit:  func() error { return NewOneClassSVM().FitContext(ctx, x) },
+		},
+		{
+			name: "Test LinearSVC",
+			fit:  func() error { return NewLinearSVC().FitContext(ctx, x, y) },
+		},
+		{
+			name: "Test MKL",
+			fit:  func() error { return NewMKL(&LinearKernel{}, &RbfKernel{Gamma: 0.5}).FitContext(ctx, x, y) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fit(); !errors.Is(err, context.Canceled) {
+				t.Errorf("FitContext() error = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestSVC_CallbackCancel(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	// Отменим обучение из функции обратного вызова после пятой итерации.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	svc := NewSVC()
+	svc.Callback = func(progress Progress) {
+		calls++
+		if progress.Iter == 5 {
+			cancel()
+		}
+	}
+	if err := svc.FitContext(ctx, x, y); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FitContext() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 5 {
+		t.Errorf("Callback called %d times, want 5", calls)
+	}
+}
+
+func TestSVC_Callback(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	for _, solver := range []SolverName{SMO, SimplifiedSMO} {
+		t.Run(string(solver), func(t *testing.T) {
+			var progress []Progress
+			svc := NewSVC()
+			svc.Solver = solver
+			svc.Shrinking = false
+			svc.Callback = func(p Progress) {
+				progress = append(progress, p)
+			}
+			if err := svc.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if len(progress) == 0 {
+				t.Fatalf("Callback was not called")
+			}
+
+			for k, p := range progress {
+				if p.Iter != k+1 {
+					t.Errorf("progress[%d].Iter = %d, want %d", k, p.Iter, k+1)
+				}
+				if p.KKTViolation < 0 {
+					t.Errorf("progress[%d].KKTViolation = %v, want non-negative", k, p.KKTViolation)
+				}
+				if p.NSupport <= 0 || p.NSupport > len(y) {
+					t.Errorf("progress[%d].NSupport = %d, want in [1, %d]", k, p.NSupport, len(y))
+				}
+				// Метод SMO не увеличивает значение целевой функции.
+				if solver == SMO && k > 0 && p.Objective > progress[k-1].Objective+1e-9 {
+					t.Errorf("progress[%d].Objective = %v > %v", k, p.Objective, progress[k-1].Objective)
+				}
+			}
+			if last := progress[len(progress)-1]; last.Objective >= 0 {
+				t.Errorf("final Objective = %v, want negative", last.Objective)
+			}
+		})
+	}
+}
+
+func TestMultiSVC_Callback(t *testing.T) {
+	x, y := loadIris(t)
+
+	// Функция вызывается из нескольких горутин, но не одновременно: тест проверяется с флагом -race.
+	calls := 0
+	m := NewMultiSVC()
+	m.Probability = true
+	m.Callback = func(Progress) {
+		calls++
+	}
+	if err := m.Fit(x, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if calls == 0 {
+		t.Errorf("Callback was not called")
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0))
+
+	logger.Debug("hidden")
+	logger.Info("fitting started", "samples", 10, "features", 2)
+	logger.Warn("odd", "key")
+	logger.Verbose = true
+	logger.Debug("visible", "iteration", 1)
+
+	want := "INFO fitting started samples=10 features=2\n" +
+		"WARN odd !BADKEY=key\n" +
+		"DEBUG visible iteration=1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSVC_Logger(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	// По умолчанию классификатор ничего не выводит, в том числе через стандартный логгер.
+	var std bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&std)
+	err := NewSVC().Fit(x, y)
+	log.SetOutput(output)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if std.Len() != 0 {
+		t.Errorf("default logger output = %q, want empty", std.String())
+	}
+
+	var buf bytes.Buffer
+	svc := NewSVC()
+	svc.Logger = NewStdLogger(log.New(&buf, "", 0))
+	if err := svc.Fit(x, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "INFO SMO finished iterations=") {
+		t.Errorf("output = %q, want SMO finished message", buf.String())
+	}
+	if strings.Contains(buf.String(), "WARN") {
+		t.Errorf("output = %q, want no warnings", buf.String())
+	}
+}
+
+func TestSVC_LoggerMaxIters(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	tests := []struct {
+		name   string
+		solver SolverName
+		nu     bool
+	}{
+		{
+			name:   "Test SMO",
+			solver: SMO,
+		},
+		{
+			name:   "Test nu-SVC",
+			solver: SMO,
+			nu:     true,
+		},
+		{
+			name:   "Test simplified SMO",
+			solver: SimplifiedSMO,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			svc := NewSVC()
+			svc.Solver = tt.solver
+			if tt.nu {
+				svc.Formulation = NuSVC
+			}
+			svc.MaxIters = 1
+			svc.Logger = NewStdLogger(log.New(&buf, "", 0))
+			if err := svc.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), "reached the maximum number of iterations without convergence") ||
+				!strings.Contains(buf.String(), "max_iters=1") {
+				t.Errorf("output = %q, want non-convergence warning", buf.String())
+			}
+		})
+	}
+}
+
+func TestSMOSolver_DefaultMaxIters(t *testing.T) {
+	tests := []struct {
+		name string
+		l    int
+		want int
+	}{
+		{
+			name: "Test small problem",
+			l:    10,
+			want: 10000000,
+		},
+		{
+			name: "Test large problem",
+			l:    200000,
+			want: 20000000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]float64, tt.l)
+			q := &svcQMatrix{y: p, kernelCache: newKernelRowCache(&LinearKernel{}, false, make([][]float64, tt.l), 0)}
+			if got := newSMOSolver(q, p, p, p, p, 0.001, 0).maxIters; got != tt.want {
+				t.Errorf("maxIters = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKFoldCVScoreCallback(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	// Блоки обучаются параллельно на копиях классификатора, но функция не вызывается одновременно:
+	// тест проверяется с флагом -race.
+	calls := 0
+	callback := func(Progress) {
+		calls++
+	}
+	svc := NewSVC()
+	svc.Callback = callback
+	m := NewMultiSVC()
+	m.Callback = callback
+
+	for _, cls := range []svm.Classifier{svc, m} {
+		calls = 0
+		if _, err := cross_validation.KFoldCVScore(cls, x, y, 5, classification_metrics.Accuracy); err != nil {
+			t.Fatalf("%T: KFoldCVScore() error = %v", cls, err)
+		}
+		if calls == 0 {
+			t.Errorf("%T: Callback was not called", cls)
+		}
+	}
+}
+
+func TestKFoldCVScoreContextCanceled(t *testing.T) {
+	x, y := loadIris(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cross_validation.KFoldCVScoreContext(ctx, NewMultiSVC(), x, y, 5, classification_metrics.Accuracy)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("KFoldCVScoreContext() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,493 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jinzhu/copier"
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+	"golang.org/x/sync/errgroup"
+)
+
+// Проверим, что структура MKL удовлетворяет интерфейсам Classifier и Scorer.
+var (
+	_ svm.Classifier        = (*MKL)(nil)
+	_ svm.Scorer            = (*MKL)(nil)
+	_ svm.ContextClassifier = (*MKL)(nil)
+)
+
+// MKL (англ. Multiple Kernel Learning) - структура для представления классификатора методом опорных векторов
+// с ядром K = d_1 * K_1 + ... + d_M * K_M, где K_m - базовые ядра, а веса d_m >= 0, d_1 + ... + d_M = 1
+// обучаются вместе с двойственной задачей SVM.
+// Веса подбираются методом SimpleMKL (Rakotomamonjy et al., 2008): при фиксированных весах решается задача SVM,
+// затем веса сдвигаются по приведенному градиенту значения двойственной задачи, и так до сходимости.
+// Многоклассовая задача сводится к бинарным так же, как в MultiSVC, при этом веса ядер общие для всех
+// бинарных классификаторов.
+type MKL struct {
+	// Базовые ядра.
+	Kernels []Kernel
+
+	// Стратегия сведения многоклассовой задачи к бинарным.
+	Strategy Strategy
+
+	// Параметр регуляризации.
+	C float64
+
+	// Точность решения задачи SVM.
+	Tol float64
+
+	// Максимальное количество итераций метода SMO (см. SVC.MaxIters).
+	MaxIters int
+
+	// Веса классов: верхняя граница параметра альфа объекта класса c равна C * ClassWeight[c].
+	// Для классов, отсутствующих в карте, вес равен 1.
+	ClassWeight map[int]float64
+
+	// Вычислять ли веса классов автоматически, обратно пропорционально их размерам.
+	// Если задано, ClassWeight игнорируется.
+	BalancedClassWeight bool
+
+	// Точность подбора весов ядер: обучение останавливается, когда нарушение условий оптимальности
+	// по весам, отнесенное к значению двойственной задачи, становится меньше MKLTol.
+	MKLTol float64
+
+	// Максимальное количество итераций подбора весов ядер.
+	MKLMaxIters int
+
+	// Логгер для сообщений о ходе обучения. Если равен nil, то сообщения не выводятся.
+	Logger svm.Logger
+
+	// Веса базовых ядер обученной модели.
+	weights []float64
+
+	// Классификатор на предвычисленной матрице взвешенного ядра.
+	model *MultiSVC
+
+	// Индексы опорных векторов всех бинарных классификаторов в обучающей выборке и сами опорные вектора.
+	supportVectorsIdx []int
+	supportVectors    [][]float64
+
+	// Число образцов обучающей выборки.
+	nSamples int
+	// Число итераций подбора весов ядер.
+	nIters int
+}
+
+// NewMKL возвращает экземпляр MKL с параметрами по умолчанию для базовых ядер kernels.
+func NewMKL(kernels ...Kernel) *MKL {
+	return &MKL{
+		Kernels:             kernels,
+		Strategy:            OvR,
+		C:                   1.0,
+		Tol:                 0.001,
+		MaxIters:            0,
+		ClassWeight:         nil,
+		BalancedClassWeight: false,
+		MKLTol:              0.01,
+		MKLMaxIters:         100,
+		Logger:              nil,
+		weights:             nil,
+		model:               nil,
+		supportVectorsIdx:   nil,
+		supportVectors:      nil,
+		nSamples:            0,
+		nIters:              0,
+	}
+}
+
+// Fit обучает веса ядер и классификатор на обучающей выборке.
+// x - матрица признаков.
+// y - слайс меток.
+func (mkl *MKL) Fit(x [][]float64, y []int) error {
+	return mkl.FitContext(context.Background(), x, y)
+}
+
+// FitContext обучает веса ядер и классификатор на обучающей выборке.
+// Если контекст отменен или истек его срок, то обучение прерывается и возвращается ошибка контекста.
+// x - матрица признаков.
+// y - слайс меток.
+func (mkl *MKL) FitContext(ctx context.Context, x [][]float64, y []int) error {
+	// Проверим валидность входных данных.
+	if err := mkl.validateInput(x, y); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+
+	// Матрицы Грама базовых ядер вычисляются один раз, на каждой итерации меняются только их веса.
+	grams, err := kernelGrams(ctx, mkl.Kernels, x)
+	if err != nil {
+		return err
+	}
+
+	// Начнем с равных весов.
+	nKernels := len(mkl.Kernels)
+	weights := make([]float64, nKernels)
+	for k := range weights {
+		weights[k] = 1 / float64(nKernels)
+	}
+	state, err := mkl.solve(ctx, grams, weights, y)
+	if err != nil {
+		return err
+	}
+
+	mkl.nIters = 0
+	for mkl.nIters < mkl.MKLMaxIters {
+		mkl.nIters++
+
+		// Условия оптимальности: производные по ненулевым весам равны между собой
+		// и не больше производных по нулевым весам.
+		if mkl.violation(state) <= mkl.MKLTol*math.Max(1, math.Abs(state.objective)) {
+			break
+		}
+
+		// Направление спуска - приведенный градиент на симплексе весов.
+		direction := reducedGradient(state.weights, state.gradient)
+		slope := vector_operations.ScalarProduct(direction, state.gradient)
+		if slope >= 0 {
+			break
+		}
+
+		// Максимальный шаг, при котором веса остаются неотрицательными.
+		maxStep := math.Inf(1)
+		for k := range direction {
+			if direction[k] < 0 {
+				maxStep = math.Min(maxStep, -state.weights[k]/direction[k])
+			}
+		}
+
+		// Подберем шаг дроблением по правилу Армихо.
+		next, err := mkl.lineSearch(ctx, grams, y, state, direction, maxStep, slope)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			break
+		}
+		state = next
+		mkl.logger().Debug("MKL iteration", "iteration", mkl.nIters, "objective", state.objective, "weights", state.weights)
+	}
+	mkl.logger().Info("MKL finished", "iterations", mkl.nIters, "objective", state.objective, "weights", state.weights)
+
+	mkl.weights = state.weights
+	mkl.model = state.model
+	mkl.nSamples = len(x)
+
+	// Для предсказания нужны только объекты, которые являются опорными хотя бы для одного бинарного классификатора.
+	isSupport := make([]bool, len(x))
+	for _, svc := range state.machines {
+		for _, i := range svc.SupportIndices() {
+			isSupport[i] = true
+		}
+	}
+	mkl.supportVectorsIdx = nil
+	mkl.supportVectors = nil
+	for i := range isSupport {
+		if isSupport[i] {
+			row := make([]float64, len(x[i]))
+			copy(row, x[i])
+			mkl.supportVectorsIdx = append(mkl.supportVectorsIdx, i)
+			mkl.supportVectors = append(mkl.supportVectors, row)
+		}
+	}
+
+	return nil
+}
+
+// mklState описывает решение задачи SVM при фиксированных весах ядер.
+type mklState struct {
+	weights []float64
+	model   *MultiSVC
+	// Бинарные классификаторы модели в детерминированном порядке.
+	machines []*SVC
+	// Значение двойственной задачи, просуммированное по бинарным классификаторам.
+	objective float64
+	// Производные значения двойственной задачи по весам ядер.
+	gradient []float64
+}
+
+// solve обучает классификатор на взвешенной сумме матриц Грама grams и вычисляет значение
+// двойственной задачи J(d) = sum(alpha) - 1/2 * sum(alpha_i * alpha_j * y_i * y_j * K(x_i, x_j))
+// и его производные по весам dJ/dd_m = -1/2 * sum(alpha_i * alpha_j * y_i * y_j * K_m(x_i, x_j)).
+func (mkl *MKL) solve(ctx context.Context, grams [][][]float64, weights []float64, y []int) (*mklState, error) {
+	gram := make([][]float64, len(y))
+	for i := range gram {
+		gram[i] = make([]float64, len(y))
+		for k, g := range grams {
+			if weights[k] == 0 {
+				continue
+			}
+			for j := range gram[i] {
+				gram[i][j] += weights[k] * g[i][j]
+			}
+		}
+	}
+
+	model := NewMultiSVC()
+	model.kernelName = Precomputed
+	model.Kernel = nil
+	model.Strategy = mkl.Strategy
+	model.C = mkl.C
+	model.Tol = mkl.Tol
+	model.MaxIters = mkl.MaxIters
+	model.ClassWeight = mkl.ClassWeight
+	model.BalancedClassWeight = mkl.BalancedClassWeight
+	model.Logger = mkl.Logger
+	if err := model.FitContext(ctx, gram, y); err != nil {
+		return nil, fmt.Errorf("error in fitting SVM with kernel weights %v: %w", weights, err)
+	}
+
+	state := &mklState{
+		weights:  weights,
+		model:    model,
+		machines: mklMachines(model),
+		gradient: make([]float64, len(grams)),
+	}
+	for _, svc := range state.machines {
+		idx, coef := svc.SupportIndices(), svc.DualCoef()
+		for k := range coef {
+			state.objective += math.Abs(coef[k])
+		}
+		for m, g := range grams {
+			quad := 0.0
+			for k, i := range idx {
+				for l, j := range idx {
+					quad += coef[k] * coef[l] * g[i][j]
+				}
+			}
+			state.gradient[m] -= quad / 2
+		}
+	}
+	state.objective += vector_operations.ScalarProduct(weights, state.gradient)
+	return state, nil
+}
+
+// lineSearch ищет шаг вдоль направления direction, не больший maxStep, при котором значение двойственной задачи
+// уменьшается достаточно (правило Армихо). slope - производная по направлению.
+// Возвращает nil, если такой шаг не найден.
+func (mkl *MKL) lineSearch(ctx context.Context, grams [][][]float64, y []int, state *mklState, direction []float64,
+	maxStep, slope float64) (*mklState, error) {
+	const (
+		armijo      = 1e-4
+		maxHalvings = 20
+	)
+	step := maxStep
+	for k := 0; k < maxHalvings; k++ {
+		weights := make([]float64, len(direction))
+		sum := 0.0
+		for m := range weights {
+			weights[m] = state.weights[m] + step*direction[m]
+			// Вес, который обнулился на максимальном шаге, обнулим точно.
+			if weights[m] < 1e-12 {
+				weights[m] = 0
+			}
+			sum += weights[m]
+		}
+		for m := range weights {
+			weights[m] /= sum
+		}
+
+		next, err := mkl.solve(ctx, grams, weights, y)
+		if err != nil {
+			return nil, err
+		}
+		if next.objective <= state.objective+armijo*step*slope {
+			return next, nil
+		}
+		step /= 2
+	}
+	return nil, nil
+}
+
+// violation возвращает нарушение условий оптимальности по весам ядер:
+// разность между наибольшей производной по ненулевым весам и наименьшей производной по всем весам.
+func (mkl *MKL) violation(state *mklState) float64 {
+	maxActive, minAll := math.Inf(-1), math.Inf(1)
+	for k, g := range state.gradient {
+		if state.weights[k] > 0 {
+			maxActive = math.Max(maxActive, g)
+		}
+		minAll = math.Min(minAll, g)
+	}
+	return maxActive - minAll
+}
+
+// reducedGradient возвращает направление спуска по приведенному градиенту на симплексе весов:
+// сумма компонент направления равна нулю, а нулевые веса с большей производной не меняются.
+func reducedGradient(weights, gradient []float64) []float64 {
+	// Опорной выбирается переменная с наибольшим весом.
+	mu := 0
+	for k := range weights {
+		if weights[k] > weights[mu] {
+			mu = k
+		}
+	}
+
+	direction := make([]float64, len(weights))
+	for k := range weights {
+		if k == mu || (weights[k] == 0 && gradient[k] > gradient[mu]) {
+			continue
+		}
+		direction[k] = gradient[mu] - gradient[k]
+		direction[mu] -= direction[k]
+	}
+	return direction
+}
+
+// mklMachines возвращает бинарные классификаторы модели в порядке меток классов.
+func mklMachines(model *MultiSVC) []*SVC {
+	if model.PairMachines != nil {
+		res := make([]*SVC, 0, len(model.PairMachines))
+		for _, pair := range model.labelPairs() {
+			res = append(res, model.PairMachines[pair])
+		}
+		return res
+	}
+	res := make([]*SVC, 0, len(model.Machines))
+	for _, label := range model.labels {
+		res = append(res, model.Machines[label])
+	}
+	return res
+}
+
+// kernelGrams вычисляет матрицы Грама ядер kernels на объектах x, каждую в отдельной горутине.
+func kernelGrams(ctx context.Context, kernels []Kernel, x [][]float64) ([][][]float64, error) {
+	grams := make([][][]float64, len(kernels))
+	eg, ctx := errgroup.WithContext(ctx)
+	for k := range kernels {
+		k := k
+		eg.Go(func() error {
+			gram := make([][]float64, len(x))
+			for i := range x {
+				gram[i] = make([]float64, len(x))
+			}
+			for i := range x {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				for j := 0; j <= i; j++ {
+					value := kernels[k].Calculate(x[i], x[j])
+					if math.IsNaN(value) || math.IsInf(value, 0) {
+						return fmt.Errorf("kernel %d returned %v for samples %d and %d", k, value, i, j)
+					}
+					gram[i][j] = value
+					gram[j][i] = value
+				}
+			}
+			grams[k] = gram
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return grams, nil
+}
+
+// validateInput проверяет валидность входных данных и параметров для обучения.
+func (mkl *MKL) validateInput(x [][]float64, y []int) error {
+	if len(mkl.Kernels) == 0 {
+		return fmt.Errorf("at least one kernel is required")
+	}
+	for k, kernel := range mkl.Kernels {
+		if kernel == nil {
+			return fmt.Errorf("kernel %d is nil", k)
+		}
+	}
+	if mkl.MKLTol <= 0 {
+		return fmt.Errorf("MKL tolerance must be positive, actual: %v", mkl.MKLTol)
+	}
+
+	// Проверим, что матрица признаков является прямоугольной.
+	if !vector_operations.IsMatrixRectangular(x) {
+		return fmt.Errorf("feature matrix must be rectangular")
+	}
+
+	// Проверим, что все данные размечены
+	if len(x) != len(y) {
+		return fmt.Errorf("not all data is labeled")
+	}
+
+	// Проверим индексы признаков составных ядер.
+	if len(x) > 0 {
+		for k, kernel := range mkl.Kernels {
+			if err := validateKernelFeatures(kernel, len(x[0])); err != nil {
+				return fmt.Errorf("invalid kernel %d: %w", k, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// logger возвращает логгер классификатора или, если он не задан, логгер, который ничего не выводит.
+func (mkl *MKL) logger() svm.Logger {
+	return loggerOrNop(mkl.Logger)
+}
+
+// KernelWeights возвращает обученные веса базовых ядер в порядке Kernels. Сумма весов равна 1.
+func (mkl *MKL) KernelWeights() []float64 {
+	return mkl.weights
+}
+
+// NIters возвращает число итераций подбора весов ядер.
+func (mkl *MKL) NIters() int {
+	return mkl.nIters
+}
+
+// Classes возвращает метки классов в том порядке, в котором они идут в результатах DecisionFunction.
+func (mkl *MKL) Classes() []int {
+	if mkl.model == nil {
+		return nil
+	}
+	return mkl.model.Classes()
+}
+
+// Predict классифицирует новые входные данные на основе обученной модели.
+// Если модель не обучена, возвращает nil и пишет ошибку в лог.
+// x - матрица признаков.
+func (mkl *MKL) Predict(x [][]float64) []int {
+	if mkl.model == nil {
+		mkl.logger().Error("model is not fitted")
+		return nil
+	}
+	return mkl.model.Predict(mkl.kernelRows(x))
+}
+
+// DecisionFunction возвращает значения решающей функции для входных данных
+// в том же формате, что и MultiSVC.DecisionFunction.
+// Если модель не обучена, возвращает nil и пишет ошибку в лог.
+// x - матрица признаков.
+func (mkl *MKL) DecisionFunction(x [][]float64) [][]float64 {
+	if mkl.model == nil {
+		mkl.logger().Error("model is not fitted")
+		return nil
+	}
+	return mkl.model.DecisionFunction(mkl.kernelRows(x))
+}
+
+// kernelRows возвращает строки значений взвешенного ядра между объектами x и объектами обучающей выборки.
+// Значения вычисляются только для опорных векторов, остальные элементы строк равны нулю и не используются.
+func (mkl *MKL) kernelRows(x [][]float64) [][]float64 {
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = make([]float64, mkl.nSamples)
+		for k, j := range mkl.supportVectorsIdx {
+			for m, kernel := range mkl.Kernels {
+				if mkl.weights[m] > 0 {
+					res[i][j] += mkl.weights[m] * kernel.Calculate(x[i], mkl.supportVectors[k])
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Clone возвращает копию классификатора.
+func (mkl *MKL) Clone() (svm.Classifier, error) {
+	res := &MKL{}
+	if err := copier.Copy(res, mkl); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,119 @@
+package svc
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ziyadovea/svm/pkg/classification_metrics"
+	"github.com/ziyadovea/svm/pkg/cross_validation"
+)
+
+func TestMKL_Fit(t *testing.T) {
+	x, y := loadIris(t)
+
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			mkl := NewMKL(&LinearKernel{}, &RbfKernel{Gamma: 0.5}, &PolyKernel{Degree: 2, Coef0: 1})
+			mkl.Strategy = strategy
+			if err := mkl.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			weights := mkl.KernelWeights()
+			if len(weights) != 3 {
+				t.Fatalf("KernelWeights() = %v, want 3 weights", weights)
+			}
+			sum := 0.0
+			for _, w := range weights {
+				if w < 0 {
+					t.Errorf("KernelWeights() = %v, want non-negative weights", weights)
+				}
+				sum += w
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("sum of KernelWeights() = %v, want 1", sum)
+			}
+
+			correct := 0
+			for i, label := range mkl.Predict(x) {
+				if label == y[i] {
+					correct++
+				}
+			}
+			if accuracy := float64(correct) / float64(len(y)); accuracy < 0.95 {
+				t.Errorf("accuracy = %v, want at least 0.95", accuracy)
+			}
+			if got := mkl.DecisionFunction(x[:1]); len(got[0]) != len(mkl.Classes()) {
+				t.Errorf("DecisionFunction() = %v, want %d values", got, len(mkl.Classes()))
+			}
+		})
+	}
+}
+
+func TestMKL_FitSelectsInformativeKernel(t *testing.T) {
+	// Класс определяется первым признаком, второй признак - шум.
+	r := rand.New(rand.NewSource(1))
+	x := make([][]float64, 100)
+	y := make([]int, len(x))
+	for i := range x {
+		y[i] = 2*(i%2) - 1
+		x[i] = []float64{float64(y[i]) + 0.5*r.NormFloat64(), 5 * r.NormFloat64()}
+	}
+
+	mkl := NewMKL(
+		&FeatureSubsetKernel{Features: []int{0}, Kernel: &RbfKernel{Gamma: 1}},
+		&FeatureSubsetKernel{Features: []int{1}, Kernel: &RbfKernel{Gamma: 1}},
+	)
+	if err := mkl.Fit(x, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if weights := mkl.KernelWeights(); weights[0] <= weights[1] {
+		t.Errorf("KernelWeights() = %v, want larger weight of the informative kernel", weights)
+	}
+}
+
+func TestMKL_FitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		mkl  *MKL
+	}{
+		{
+			name: "Test no kernels",
+			mkl:  NewMKL(),
+		},
+		{
+			name: "Test nil kernel",
+			mkl:  NewMKL(&LinearKernel{}, nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mkl.Fit(separableX, separableY); err == nil {
+				t.Errorf("Fit() expected error")
+			}
+		})
+	}
+}
+
+func TestMKL_PredictNotFitted(t *testing.T) {
+	mkl := NewMKL(&LinearKernel{})
+	if got := mkl.Predict(separableX); got != nil {
+		t.Errorf("Predict() = %v, want nil", got)
+	}
+	if got := mkl.DecisionFunction(separableX); got != nil {
+		t.Errorf("DecisionFunction() = %v, want nil", got)
+	}
+}
+
+func TestKFoldCVScoreMKL(t *testing.T) {
+	x, y := loadIris(t)
+	mkl := NewMKL(&LinearKernel{}, &RbfKernel{Gamma: 0.5})
+	scores, err := cross_validation.KFoldCVScore(mkl, x, y, 5, classification_metrics.Accuracy)
+	if err != nil {
+		t.Fatalf("KFoldCVScore() error = %v", err)
+	}
+	if got := len(scores[classification_metrics.Accuracy]); got != 5 {
+		t.Errorf("KFoldCVScore() returned %d scores, want 5", got)
+	}
+}
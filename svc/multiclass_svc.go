@@ -1,8 +1,9 @@
 package svc
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"math"
 	"sync"
 
 	"github.com/jinzhu/copier"
@@ -11,19 +12,51 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-// Проверим, что структура MultiSVC удовлетворяет интерфейсу Classifier.
-var _ svm.Classifier = (*MultiSVC)(nil)
+// Проверим, что структура MultiSVC удовлетворяет интерфейсам Classifier, Scorer и ProbabilisticClassifier.
+var (
+	_ svm.Classifier              = (*MultiSVC)(nil)
+	_ svm.Scorer                  = (*MultiSVC)(nil)
+	_ svm.ProbabilisticClassifier = (*MultiSVC)(nil)
+	_ svm.ContextClassifier       = (*MultiSVC)(nil)
+)
+
+// Strategy тип для стратегии сведения многоклассовой задачи к бинарным.
+type Strategy string
+
+// Определяем в константах существующие стратегии.
+const (
+	// OvR (One-vs-Rest) - по одному классификатору "класс против остальных" на каждый класс.
+	OvR Strategy = "ovr"
+	// OvO (One-vs-One) - по одному классификатору на каждую пару классов, итоговый класс определяется голосованием.
+	OvO Strategy = "ovo"
+)
+
+// LabelPair описывает пару меток классов для бинарного классификатора стратегии OvO.
+// Объекты класса Positive помечаются как +1, объекты класса Negative - как -1.
+type LabelPair struct {
+	Positive int
+	Negative int
+}
 
 // MultiSVC (англ. Multiclass Support Vector Classifier) - структура для представления
 // мультиклассового классификатора методом опорных векторов.
-// Реализуется метод One-vs-All.
+// Реализуются методы One-vs-Rest (One-vs-All) и One-vs-One.
+// Параметры обученных бинарных классификаторов (опорные вектора, двойственные коэффициенты и т.д.)
+// доступны через поля Machines и PairMachines.
 type MultiSVC struct {
 	SVC
 
-	// Карта SVM-ов для каждого бинарного случая.
+	// Стратегия сведения многоклассовой задачи к бинарным.
+	Strategy Strategy
+
+	// Карта SVM-ов для каждого бинарного случая стратегии OvR.
 	// Ключ - метка класса, значение - классификатор.
 	Machines map[int]*SVC
 
+	// Карта SVM-ов для каждого бинарного случая стратегии OvO.
+	// Ключ - пара меток классов, значение - классификатор.
+	PairMachines map[LabelPair]*SVC
+
 	// Слайс уникальных меток обучающего набора.
 	labels []int
 }
@@ -32,67 +65,137 @@ type MultiSVC struct {
 func NewMultiSVC() *MultiSVC {
 	return &MultiSVC{
 		SVC: SVC{
-			kernelName:        "rbf",
-			Kernel:            &RbfKernel{Gamma: 1.0},
-			kernelCache:       nil,
-			C:                 1.0,
-			Degree:            3,
-			Coef0:             0.0,
-			Gamma:             1.0,
-			Tol:               0.001,
-			MaxIters:          10000,
-			supportVectorsIdx: nil,
-			x:                 nil,
-			y:                 nil,
-			b:                 0.0,
-			nSamples:          0,
-			nFeatures:         0,
-			nClasses:          0,
-			alphas:            nil,
+			kernelName:          "rbf",
+			Kernel:              &RbfKernel{Gamma: 1.0},
+			kernelCache:         nil,
+			CacheSizeMB:         defaultCacheSizeMB,
+			Shrinking:           true,
+			Formulation:         CSVC,
+			C:                   1.0,
+			Nu:                  0.5,
+			Degree:              3,
+			Coef0:               0.0,
+			Gamma:               1.0,
+			GammaMode:           GammaValue,
+			KernelAlpha:         1.0,
+			Tol:                 0.001,
+			MaxIters:            0,
+			Solver:              SMO,
+			Seed:                1,
+			Probability:         false,
+			ClassWeight:         nil,
+			BalancedClassWeight: false,
+			Logger:              nil,
+			Callback:            nil,
+			supportVectorsIdx:   nil,
+			x:                   nil,
+			y:                   nil,
+			supportVectors:      nil,
+			dualCoef:            nil,
+			nSupport:            nil,
+			b:                   0.0,
+			fittedGamma:         0.0,
+			platt:               nil,
+			nSamples:            0,
+			nFeatures:           0,
+			nClasses:            0,
+			alphas:              nil,
+			boxC:                nil,
+			rnd:                 nil,
 		},
-		Machines: nil,
-		labels:   nil,
-	}
-}
-
-// SetKernelByName устанавливает ядро по его имени.
-// Возвращает ошибку в случае неизвестного ядра.
-func (m *MultiSVC) SetKernelByName(kernelName string) error {
-	switch KernelName(strings.ToLower(kernelName)) {
-	case Linear:
-		m.kernelName = Linear
-		m.Kernel = &LinearKernel{}
-	case Poly:
-		m.kernelName = Poly
-		m.Kernel = &PolyKernel{
-			Coef0:  m.Coef0,
-			Degree: m.Degree,
-		}
-	case Rbf:
-		m.kernelName = Rbf
-		m.Kernel = &RbfKernel{Gamma: m.Gamma}
-	default:
-		return fmt.Errorf("unknown kernel name")
+		Strategy:     OvR,
+		Machines:     nil,
+		PairMachines: nil,
+		labels:       nil,
 	}
-	return nil
 }
 
 // Fit обучает алгоритм на обучающей выборке.
 // x - матрица признаков.
 // y - слайс меток.
 func (m *MultiSVC) Fit(x [][]float64, y []int) error {
+	return m.FitContext(context.Background(), x, y)
+}
+
+// FitContext обучает алгоритм на обучающей выборке.
+// Если контекст отменен или истек его срок, то обучение всех бинарных классификаторов прерывается
+// и возвращается ошибка контекста.
+// x - матрица признаков.
+// y - слайс меток.
+func (m *MultiSVC) FitContext(ctx context.Context, x [][]float64, y []int) error {
+	return m.fitWeighted(ctx, x, y, nil)
+}
+
+// FitWeighted обучает алгоритм на обучающей выборке с весами объектов.
+// Веса классов задаются по меткам исходной разметки и передаются бинарным классификаторам
+// в виде весов объектов.
+// x - матрица признаков.
+// y - слайс меток.
+// sampleWeight - слайс неотрицательных весов объектов, nil означает единичные веса.
+func (m *MultiSVC) FitWeighted(x [][]float64, y []int, sampleWeight []float64) error {
+	return m.fitWeighted(context.Background(), x, y, sampleWeight)
+}
+
+// fitWeighted обучает алгоритм на обучающей выборке с весами объектов с учетом контекста ctx.
+func (m *MultiSVC) fitWeighted(ctx context.Context, x [][]float64, y []int, sampleWeight []float64) error {
+	// Метод решения проверим до вычисления общего кэша ядра.
+	if err := m.validateSolver(); err != nil {
+		return err
+	}
+
 	// Проверим валидность входных данных.
-	if err := m.validateInput(x, y); err != nil {
+	if err := m.validateInput(x, y, sampleWeight); err != nil {
 		return fmt.Errorf("invalid input data: %w", err)
 	}
 
-	// Выделим память под все бинарные классификаторы. Их число равно количеству классов.
+	// Ядро, заданное по имени, построим из текущих значений гиперпараметров.
+	// Бинарные классификаторы получают его вместе с остальными гиперпараметрами,
+	// а gamma вычисляется по всей обучающей выборке, а не по выборкам подзадач.
+	gamma, err := resolveGamma(m.GammaMode, m.Gamma, x)
+	if err != nil {
+		return fmt.Errorf("invalid gamma: %w", err)
+	}
+	params := m.kernelParams()
+	params.Gamma = gamma
+	kernel, err := resolveKernel(m.kernelName, params, m.Kernel, len(x[0]))
+	if err != nil {
+		return fmt.Errorf("invalid kernel: %w", err)
+	}
+	m.Kernel = kernel
+	m.fittedGamma = gamma
+
 	m.labels = vector_operations.GetUniques(y)
 	m.nClasses = len(m.labels)
+	m.nFeatures = len(x[0])
+	m.Machines = nil
+	m.PairMachines = nil
+
+	// В бинарных подзадачах метки другие, поэтому веса классов переводятся в веса объектов.
+	weights := m.effectiveWeights(y, sampleWeight)
+
+	// Все бинарные классификаторы обучаются на объектах x, поэтому разделяют один кэш ядра.
+	cache := newKernelRowCache(m.Kernel, m.Pairwise(), x, m.CacheSizeMB)
+
+	switch m.Strategy {
+	case OvR, "":
+		return m.fitOvR(ctx, x, y, weights, cache)
+	case OvO:
+		return m.fitOvO(ctx, x, y, weights, cache)
+	default:
+		return fmt.Errorf("unknown strategy: %s", m.Strategy)
+	}
+}
+
+// fitOvR обучает по одному классификатору "класс против остальных" на каждый класс.
+// weights - итоговые веса объектов, cache - кэш ядра объектов x.
+// Ошибка или отмена контекста в одном классификаторе прерывает обучение остальных.
+func (m *MultiSVC) fitOvR(ctx context.Context, x [][]float64, y []int, weights []float64, cache *kernelRowCache) error {
+	// Выделим память под все бинарные классификаторы. Их число равно количеству классов.
 	m.Machines = make(map[int]*SVC, m.nClasses)
+	callback := synchronizedCallback(m.progressCallback())
 
 	// Создаем errgroup.Group для обучения каждого бинарного классификатора в отдельной горутине.
-	eg := new(errgroup.Group)
+	eg, ctx := errgroup.WithContext(ctx)
 	// Создаем мьютекс для добавления элементов в мапу, так как мапа в Go потоко-небезопасный тип.
 	mu := sync.Mutex{}
 
@@ -111,18 +214,11 @@ func (m *MultiSVC) Fit(x [][]float64, y []int) error {
 			}
 
 			// Создаем очередной бинарный классификатор
-			svc := NewSVC()
-			svc.kernelName = m.kernelName
-			svc.Kernel = m.Kernel
-			svc.C = m.C
-			svc.Degree = m.Degree
-			svc.Coef0 = m.Coef0
-			svc.Gamma = m.Gamma
-			svc.Tol = m.Tol
-			svc.MaxIters = m.MaxIters
+			svc := m.fittedParamsCopy()
+			svc.Callback = callback
 
 			// Обучаем очередной бинарный классификатор
-			if err := svc.Fit(x, yTmp); err != nil {
+			if err := svc.fit(ctx, x, yTmp, weights, cache); err != nil {
 				return fmt.Errorf("error in fitting a binary classifier: %w", err)
 			}
 
@@ -142,8 +238,77 @@ func (m *MultiSVC) Fit(x [][]float64, y []int) error {
 	return nil
 }
 
+// fitOvO обучает по одному классификатору на каждую пару классов - всего k(k-1)/2 классификаторов.
+// Каждый классификатор обучается только на объектах своей пары классов.
+// weights - итоговые веса объектов, cache - кэш ядра объектов x.
+// Ошибка или отмена контекста в одном классификаторе прерывает обучение остальных.
+func (m *MultiSVC) fitOvO(ctx context.Context, x [][]float64, y []int, weights []float64, cache *kernelRowCache) error {
+	m.PairMachines = make(map[LabelPair]*SVC, m.nClasses*(m.nClasses-1)/2)
+	callback := synchronizedCallback(m.progressCallback())
+
+	// Создаем errgroup.Group для обучения каждого бинарного классификатора в отдельной горутине.
+	eg, ctx := errgroup.WithContext(ctx)
+	// Создаем мьютекс для добавления элементов в мапу, так как мапа в Go потоко-небезопасный тип.
+	mu := sync.Mutex{}
+
+	// Классификация методом One-vs-One
+	for _, pair := range m.labelPairs() {
+		pair := pair
+		eg.Go(func() error {
+			// Отбираем объекты текущей пары классов: первый класс помечаем как +1, второй - как -1
+			idx := make([]int, 0, len(y))
+			yTmp := make([]int, 0, len(y))
+			wTmp := make([]float64, 0, len(y))
+			for i := range y {
+				switch y[i] {
+				case pair.Positive:
+					idx = append(idx, i)
+					yTmp = append(yTmp, +1)
+					wTmp = append(wTmp, weights[i])
+				case pair.Negative:
+					idx = append(idx, i)
+					yTmp = append(yTmp, -1)
+					wTmp = append(wTmp, weights[i])
+				}
+			}
+
+			// Создаем и обучаем очередной бинарный классификатор
+			svc := m.fittedParamsCopy()
+			svc.Callback = callback
+			if err := svc.fitSubset(ctx, x, idx, yTmp, wTmp, cache); err != nil {
+				return fmt.Errorf("error in fitting a binary classifier for labels %d and %d: %w",
+					pair.Positive, pair.Negative, err)
+			}
+
+			// Добавляем в мапу машин уже обученный экземпляр SVC
+			mu.Lock()
+			m.PairMachines[pair] = svc
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// labelPairs возвращает все пары меток классов (labels[i], labels[j]), i < j.
+func (m *MultiSVC) labelPairs() []LabelPair {
+	pairs := make([]LabelPair, 0, len(m.labels)*(len(m.labels)-1)/2)
+	for i := 0; i < len(m.labels); i++ {
+		for j := i + 1; j < len(m.labels); j++ {
+			pairs = append(pairs, LabelPair{Positive: m.labels[i], Negative: m.labels[j]})
+		}
+	}
+	return pairs
+}
+
 // validateInput проверяет валидность входных данных для обучения - массива меток и матрицы признаков.
-func (m *MultiSVC) validateInput(x [][]float64, y []int) error {
+func (m *MultiSVC) validateInput(x [][]float64, y []int, sampleWeight []float64) error {
 	// Проверим, что матрица признаков является прямоугольной.
 	if !vector_operations.IsMatrixRectangular(x) {
 		return fmt.Errorf("feature matrix must be rectangular")
@@ -154,12 +319,34 @@ func (m *MultiSVC) validateInput(x [][]float64, y []int) error {
 		return fmt.Errorf("not all data is labeled")
 	}
 
+	// Для предвычисленного ядра проверим матрицу Грама.
+	if m.Pairwise() {
+		if err := validateGram(x); err != nil {
+			return err
+		}
+	}
+
+	// Для классификации нужно хотя бы 2 класса.
+	if nClasses := vector_operations.CountOfUniques(y); nClasses < 2 {
+		return fmt.Errorf("incorrect number of class labels: expected at least 2, actual: %d", nClasses)
+	}
+
+	// Проверим веса классов и объектов.
+	if err := m.validateWeights(y, sampleWeight); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // Predict классифицирует новые входные данные на основе обученной моодели.
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
 // x - матрица признаков.
 func (m *MultiSVC) Predict(x [][]float64) []int {
+	if err := m.ValidatePredictInput(x); err != nil {
+		m.logger().Error("invalid input data", "error", err)
+		return nil
+	}
 	res := make([]int, len(x))
 	for i := range x {
 		res[i] = m.predictOne(x[i])
@@ -169,16 +356,221 @@ func (m *MultiSVC) Predict(x [][]float64) []int {
 
 // Возвращает метку класса, к которой обученный классификатор отнес объект с признаковым описанием x.
 func (m *MultiSVC) predictOne(x []float64) int {
-	results := make(map[int]float64, len(m.labels))
+	scores := m.decisionOne(x)
+
+	// Найдем класс с наибольшим значением решающей функции
+	best := 0
+	for k := range scores {
+		if scores[k] > scores[best] {
+			best = k
+		}
+	}
+	return m.labels[best]
+}
+
+// Возвращает значения решающей функции для объекта x в порядке меток m.labels.
+func (m *MultiSVC) decisionOne(x []float64) []float64 {
+	if m.PairMachines != nil {
+		return m.decisionOneOvO(x)
+	}
+
+	scores := make([]float64, len(m.labels))
+	for k, label := range m.labels {
+		scores[k] = m.Machines[label].f(x)
+	}
+	return scores
+}
+
+// Возвращает значения решающей функции стратегии OvO для объекта x в порядке меток m.labels.
+// Значение для класса равно числу голосов, отданных за него парными классификаторами,
+// плюс сумма значений их решающих функций, сжатая в интервал (-1/3, 1/3).
+// Поэтому наибольшее значение имеет класс с наибольшим числом голосов, а при равенстве голосов -
+// класс с наибольшей суммой значений решающих функций.
+func (m *MultiSVC) decisionOneOvO(x []float64) []float64 {
+	index := make(map[int]int, len(m.labels))
+	for k, label := range m.labels {
+		index[label] = k
+	}
+
+	votes := make([]float64, len(m.labels))
+	confidences := make([]float64, len(m.labels))
+	for _, pair := range m.labelPairs() {
+		value := m.PairMachines[pair].f(x)
+		i, j := index[pair.Positive], index[pair.Negative]
+		if value >= 0 {
+			votes[i]++
+		} else {
+			votes[j]++
+		}
+		confidences[i] += value
+		confidences[j] -= value
+	}
+
+	scores := make([]float64, len(m.labels))
+	for k := range scores {
+		scores[k] = votes[k] + confidences[k]/(3*(math.Abs(confidences[k])+1))
+	}
+	return scores
+}
+
+// Classes возвращает метки классов в том порядке, в котором они идут
+// в результатах DecisionFunction и PredictProba.
+func (m *MultiSVC) Classes() []int {
+	return m.labels
+}
+
+// DecisionFunction возвращает значения решающей функции для входных данных.
+// Каждая строка результата содержит значения для каждого класса в порядке меток Classes:
+// для стратегии OvR - значения решающих функций классификаторов "класс против остальных",
+// для стратегии OvO - число голосов за класс, уточненное суммой значений решающих функций парных классификаторов.
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
+// x - матрица признаков.
+func (m *MultiSVC) DecisionFunction(x [][]float64) [][]float64 {
+	if err := m.ValidatePredictInput(x); err != nil {
+		m.logger().Error("invalid input data", "error", err)
+		return nil
+	}
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = m.decisionOne(x[i])
+	}
+	return res
+}
+
+// PredictProba возвращает вероятности классов для входных данных в порядке меток Classes.
+// Для стратегии OvR вероятности классификаторов "класс против остальных" нормируются так, чтобы их сумма была равна 1,
+// для стратегии OvO вероятности получаются попарным объединением (pairwise coupling) вероятностей парных классификаторов.
+// Возвращает nil, если модель обучена без оценки вероятностей (Probability = false).
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
+// x - матрица признаков.
+func (m *MultiSVC) PredictProba(x [][]float64) [][]float64 {
+	if len(m.labels) == 0 {
+		return nil
+	}
+	if err := m.ValidatePredictInput(x); err != nil {
+		m.logger().Error("invalid input data", "error", err)
+		return nil
+	}
+	if m.PairMachines != nil {
+		return m.predictProbaOvO(x)
+	}
 
-	// Начинем классификацию с самого частого класса
 	for _, label := range m.labels {
-		results[label] = m.Machines[label].f(x)
+		if m.Machines[label].platt == nil {
+			return nil
+		}
 	}
 
-	// Найдем класс с наиболее вероятным результатом
-	pl := vector_operations.SortByValue(results)
-	return pl[0].Key
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = make([]float64, len(m.labels))
+		sum := 0.0
+		for k, label := range m.labels {
+			machine := m.Machines[label]
+			res[i][k] = machine.platt.predict(machine.f(x[i]))
+			sum += res[i][k]
+		}
+		for k := range res[i] {
+			if sum > 0 {
+				res[i][k] /= sum
+			} else {
+				res[i][k] = 1 / float64(len(m.labels))
+			}
+		}
+	}
+	return res
+}
+
+// predictProbaOvO возвращает вероятности классов для стратегии OvO.
+func (m *MultiSVC) predictProbaOvO(x [][]float64) [][]float64 {
+	for _, machine := range m.PairMachines {
+		if machine.platt == nil {
+			return nil
+		}
+	}
+
+	// Ограничиваем парные вероятности, чтобы избежать вырожденных случаев.
+	const minProb = 1e-7
+
+	k := len(m.labels)
+	res := make([][]float64, len(x))
+	for n := range x {
+		// r[i][j] - вероятность класса i при условии, что объект принадлежит классу i или j.
+		r := make([][]float64, k)
+		for i := range r {
+			r[i] = make([]float64, k)
+		}
+		for i := 0; i < k; i++ {
+			for j := i + 1; j < k; j++ {
+				machine := m.PairMachines[LabelPair{Positive: m.labels[i], Negative: m.labels[j]}]
+				p := machine.platt.predict(machine.f(x[n]))
+				p = math.Min(math.Max(p, minProb), 1-minProb)
+				r[i][j] = p
+				r[j][i] = 1 - p
+			}
+		}
+		res[n] = pairwiseCoupling(r)
+	}
+	return res
+}
+
+// pairwiseCoupling восстанавливает вероятности k классов по попарным вероятностям r
+// (второй метод из Wu, Lin, Weng, "Probability estimates for multi-class classification by pairwise coupling", 2004).
+func pairwiseCoupling(r [][]float64) []float64 {
+	k := len(r)
+	maxIters := 100
+	if k > maxIters {
+		maxIters = k
+	}
+	eps := 0.005 / float64(k)
+
+	p := make([]float64, k)
+	qp := make([]float64, k)
+	q := make([][]float64, k)
+	for t := 0; t < k; t++ {
+		p[t] = 1 / float64(k)
+		q[t] = make([]float64, k)
+	}
+	for t := 0; t < k; t++ {
+		for j := 0; j < k; j++ {
+			if j == t {
+				continue
+			}
+			q[t][t] += r[j][t] * r[j][t]
+			q[t][j] = -r[j][t] * r[t][j]
+		}
+	}
+
+	for iter := 0; iter < maxIters; iter++ {
+		// Проверяем условие оптимальности.
+		pQp := 0.0
+		for t := 0; t < k; t++ {
+			qp[t] = 0
+			for j := 0; j < k; j++ {
+				qp[t] += q[t][j] * p[j]
+			}
+			pQp += p[t] * qp[t]
+		}
+		maxError := 0.0
+		for t := 0; t < k; t++ {
+			maxError = math.Max(maxError, math.Abs(qp[t]-pQp))
+		}
+		if maxError < eps {
+			break
+		}
+
+		// Покоординатно обновляем вероятности, сохраняя их сумму равной 1.
+		for t := 0; t < k; t++ {
+			diff := (-qp[t] + pQp) / q[t][t]
+			p[t] += diff
+			pQp = (pQp + diff*(diff*q[t][t]+2*qp[t])) / (1 + diff) / (1 + diff)
+			for j := 0; j < k; j++ {
+				qp[j] = (qp[j] + diff*q[t][j]) / (1 + diff)
+				p[j] /= 1 + diff
+			}
+		}
+	}
+	return p
 }
 
 // Clone возвращает копию мультиклассового SVM.
@@ -187,5 +579,6 @@ func (m *MultiSVC) Clone() (svm.Classifier, error) {
 	if err := copier.Copy(res, m); err != nil {
 		return nil, err
 	}
+	res.callbackMu = m.sharedCallbackMu()
 	return res, nil
 }
@@ -0,0 +1,398 @@
+package svc
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/classification_metrics"
+	"github.com/ziyadovea/svm/pkg/classification_metrics/multiclass_metrics"
+	"github.com/ziyadovea/svm/pkg/cross_validation"
+)
+
+func TestMultiSVC_Fit(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     Strategy
+		formulation  Formulation
+		wantMachines int
+	}{
+		{
+			name:         "Test OvR",
+			strategy:     OvR,
+			wantMachines: 3,
+		},
+		{
+			name:         "Test OvO",
+			strategy:     OvO,
+			wantMachines: 3,
+		},
+		{
+			name:         "Test OvR nu-SVC",
+			strategy:     OvR,
+			formulation:  NuSVC,
+			wantMachines: 3,
+		},
+		{
+			name:         "Test OvO nu-SVC",
+			strategy:     OvO,
+			formulation:  NuSVC,
+			wantMachines: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := loadIris(t)
+
+			m := NewMultiSVC()
+			m.Strategy = tt.strategy
+			if tt.formulation != "" {
+				m.Formulation = tt.formulation
+				m.Nu = 0.1
+			}
+			if err := m.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			if got := len(m.Machines) + len(m.PairMachines); got != tt.wantMachines {
+				t.Errorf("number of binary classifiers = %d, want %d", got, tt.wantMachines)
+			}
+			if accuracy := multiclass_metrics.Accuracy(y, m.Predict(x)); accuracy < 0.95 {
+				t.Errorf("training accuracy = %v, want at least 0.95", accuracy)
+			}
+		})
+	}
+}
+
+func TestMultiSVC_FitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		y        []int
+	}{
+		{
+			name:     "Test unknown strategy",
+			strategy: "unknown",
+			y:        []int{1, 1, 2, 2, 3, 3},
+		},
+		{
+			name:     "Test single class",
+			strategy: OvO,
+			y:        []int{1, 1, 1, 1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMultiSVC()
+			m.Strategy = tt.strategy
+			x := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+			if err := m.Fit(x, tt.y); err == nil {
+				t.Errorf("Fit() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMultiSVC_DecisionFunction(t *testing.T) {
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			x, y := loadIris(t)
+
+			m := NewMultiSVC()
+			m.Strategy = strategy
+			if err := m.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			classes := m.Classes()
+			pred := m.Predict(x)
+			for i, row := range m.DecisionFunction(x) {
+				if len(row) != len(classes) {
+					t.Fatalf("len(DecisionFunction()[%d]) = %d, want %d", i, len(row), len(classes))
+				}
+				best := 0
+				for k := range row {
+					if row[k] > row[best] {
+						best = k
+					}
+				}
+				if classes[best] != pred[i] {
+					t.Errorf("argmax of DecisionFunction()[%d] = %d, Predict() = %d", i, classes[best], pred[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMultiSVC_decisionOneOvOTieBreaking(t *testing.T) {
+	// Три класса, каждый из которых выигрывает ровно одну пару:
+	// голоса равны, поэтому побеждает класс с наибольшей суммой значений решающих функций.
+	constant := func(value float64) *SVC {
+		svc := NewSVC()
+		svc.supportVectors = [][]float64{}
+		svc.dualCoef = []float64{}
+		svc.b = value
+		return svc
+	}
+
+	m := NewMultiSVC()
+	m.labels = []int{1, 2, 3}
+	m.PairMachines = map[LabelPair]*SVC{
+		{Positive: 1, Negative: 2}: constant(0.5),  // голос за 1
+		{Positive: 1, Negative: 3}: constant(-2.0), // голос за 3
+		{Positive: 2, Negative: 3}: constant(0.1),  // голос за 2
+	}
+
+	scores := m.decisionOneOvO([]float64{0})
+	for k, score := range scores {
+		if math.Floor(score+0.5) != 1 {
+			t.Errorf("score of class %d = %v, want about 1 vote", m.labels[k], score)
+		}
+	}
+	// Суммы значений решающих функций: класс 1: 0.5 - 2.0 = -1.5, класс 2: -0.5 + 0.1 = -0.4, класс 3: 2.0 - 0.1 = 1.9.
+	if got := m.predictOne([]float64{0}); got != 3 {
+		t.Errorf("predictOne() = %d, want 3", got)
+	}
+}
+
+func TestMultiSVC_PredictProba(t *testing.T) {
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			x, y := loadIris(t)
+
+			m := NewMultiSVC()
+			m.Strategy = strategy
+			m.Probability = true
+			if err := m.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			proba := m.PredictProba(x)
+			if len(proba) != len(x) {
+				t.Fatalf("len(PredictProba()) = %d, want %d", len(proba), len(x))
+			}
+
+			correct := 0
+			for i := range proba {
+				sum := 0.0
+				best := 0
+				for k, p := range proba[i] {
+					if p < 0 || p > 1 {
+						t.Errorf("PredictProba()[%d][%d] = %v is out of [0, 1]", i, k, p)
+					}
+					sum += p
+					if p > proba[i][best] {
+						best = k
+					}
+				}
+				if math.Abs(sum-1) > 1e-9 {
+					t.Errorf("sum(PredictProba()[%d]) = %v, want 1", i, sum)
+				}
+				if m.Classes()[best] == y[i] {
+					correct++
+				}
+			}
+			if accuracy := float64(correct) / float64(len(y)); accuracy < 0.9 {
+				t.Errorf("accuracy of the most probable class = %v, want at least 0.9", accuracy)
+			}
+		})
+	}
+}
+
+func TestMultiSVC_PredictProbaWithoutProbability(t *testing.T) {
+	x, y := loadIris(t)
+
+	m := NewMultiSVC()
+	if err := m.Fit(x, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if got := m.PredictProba(x); got != nil {
+		t.Errorf("PredictProba() = %v, want nil", got)
+	}
+}
+
+func Test_pairwiseCoupling(t *testing.T) {
+	tests := []struct {
+		name string
+		p    []float64
+	}{
+		{
+			name: "Test 3 classes",
+			p:    []float64{0.2, 0.5, 0.3},
+		},
+		{
+			name: "Test 4 classes",
+			p:    []float64{0.1, 0.1, 0.7, 0.1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Согласованные попарные вероятности r[i][j] = p[i] / (p[i] + p[j]).
+			r := make([][]float64, len(tt.p))
+			for i := range r {
+				r[i] = make([]float64, len(tt.p))
+				for j := range r[i] {
+					if i != j {
+						r[i][j] = tt.p[i] / (tt.p[i] + tt.p[j])
+					}
+				}
+			}
+
+			got := pairwiseCoupling(r)
+			for i := range tt.p {
+				if math.Abs(got[i]-tt.p[i]) > 1e-3 {
+					t.Errorf("pairwiseCoupling() = %v, want %v", got, tt.p)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMultiSVC_FitGammaScale(t *testing.T) {
+	x, y := loadIris(t)
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			m := NewMultiSVC()
+			m.Strategy = strategy
+			m.GammaMode = GammaScale
+			if err := m.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			want, _ := resolveGamma(GammaScale, 0, x)
+			if m.FittedGamma() != want {
+				t.Errorf("FittedGamma() = %v, want %v", m.FittedGamma(), want)
+			}
+			// Бинарные классификаторы используют gamma всей обучающей выборки.
+			machines := make([]*SVC, 0)
+			for _, svc := range m.Machines {
+				machines = append(machines, svc)
+			}
+			for _, svc := range m.PairMachines {
+				machines = append(machines, svc)
+			}
+			for _, svc := range machines {
+				if svc.FittedGamma() != want {
+					t.Errorf("FittedGamma() of a binary classifier = %v, want %v", svc.FittedGamma(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestKFoldCVScoreROCAUC(t *testing.T) {
+	x, y := loadIris(t)
+	xBin, yBin := loadIrisBinary(t)
+
+	// Объекты выборки ирисов упорядочены по классам, поэтому перед разбиением их надо перемешать.
+	kFold := cross_validation.NewKFold(5)
+	kFold.Shuffle = true
+
+	tests := []struct {
+		name    string
+		cls     svm.Classifier
+		x       [][]float64
+		y       []int
+		metrics []classification_metrics.ClassificationMetric
+	}{
+		{
+			name: "Test SVC",
+			cls:  NewSVC(),
+			x:    xBin,
+			y:    yBin,
+			metrics: []classification_metrics.ClassificationMetric{
+				classification_metrics.ROCAUC,
+				classification_metrics.AveragePrecision,
+			},
+		},
+		{
+			name: "Test MultiSVC OvR",
+			cls:  NewMultiSVC(),
+			x:    x,
+			y:    y,
+			metrics: []classification_metrics.ClassificationMetric{
+				classification_metrics.ROCAUCOvR,
+				classification_metrics.ROCAUCOvO,
+				classification_metrics.ROCAUCOvRWeighted,
+				classification_metrics.ROCAUCOvOWeighted,
+			},
+		},
+		{
+			name: "Test LinearSVC",
+			cls:  NewLinearSVC(),
+			x:    x,
+			y:    y,
+			metrics: []classification_metrics.ClassificationMetric{
+				classification_metrics.ROCAUCOvR,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores, err := kFold.Score(context.Background(), tt.cls, tt.x, tt.y, tt.metrics...)
+			if err != nil {
+				t.Fatalf("Score() error = %v", err)
+			}
+			if len(scores) == 0 {
+				t.Fatalf("Score() returned no metrics")
+			}
+			for metric := range scores {
+				if !classification_metrics.IsScoreBased(metric) || len(scores[metric]) != kFold.NSplits {
+					t.Fatalf("Score()[%s] = %v, want %d values", metric, scores[metric], kFold.NSplits)
+				}
+				for _, score := range scores[metric] {
+					if score < 0.9 || score > 1 {
+						t.Errorf("Score()[%s] = %v, want values in [0.9, 1]", metric, scores[metric])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestKFoldCVScoreProbabilistic(t *testing.T) {
+	x, y := loadIris(t)
+	xBin, yBin := loadIrisBinary(t)
+
+	kFold := cross_validation.NewKFold(5)
+	kFold.Shuffle = true
+
+	svc := NewSVC()
+	svc.Probability = true
+	multiSVC := NewMultiSVC()
+	multiSVC.Probability = true
+
+	tests := []struct {
+		name string
+		cls  svm.Classifier
+		x    [][]float64
+		y    []int
+	}{
+		{name: "Test SVC", cls: svc, x: xBin, y: yBin},
+		{name: "Test MultiSVC", cls: multiSVC, x: x, y: y},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores, err := kFold.Score(context.Background(), tt.cls, tt.x, tt.y,
+				classification_metrics.LogLoss, classification_metrics.BrierScore,
+				classification_metrics.MCC, classification_metrics.CohenKappa)
+			if err != nil {
+				t.Fatalf("Score() error = %v", err)
+			}
+			for metric, values := range scores {
+				for _, value := range values {
+					// Для log-loss и оценки Брайера чем меньше значение, тем лучше.
+					good := value > 0.7
+					if classification_metrics.IsProbabilistic(metric) {
+						good = value >= 0 && value < 0.3
+					}
+					if !good {
+						t.Errorf("Score()[%s] = %v", metric, values)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jinzhu/copier"
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// OneClassSVM - структура для представления одноклассового SVM (Schölkopf et al., 2001)
+// для поиска аномалий и новизны в данных без разметки.
+// Модель отделяет область, в которой сосредоточены обучающие данные, от начала координат в пространстве ядра.
+type OneClassSVM struct {
+	// Название ядра.
+	kernelName KernelName
+
+	// Ядро.
+	// Ядро, заданное по имени, строится заново из полей Degree, Coef0, Gamma и KernelAlpha при каждом вызове Fit.
+	// Собственный объект ядра задается методом SetKernel.
+	Kernel Kernel
+
+	// Кэш строк матрицы ядра.
+	// Используется только во время обучения.
+	kernelCache *kernelRowCache
+
+	// Объем памяти под кэш строк матрицы ядра в мегабайтах.
+	// Если строки не помещаются в кэш, то давно не использованные строки вытесняются и при необходимости
+	// вычисляются заново.
+	CacheSizeMB float64
+
+	// Использовать ли эвристику сжатия (shrinking) метода SMO: переменные, которые находятся на границах
+	// и, скорее всего, там и останутся, временно исключаются из оптимизации.
+	Shrinking bool
+
+	// Параметр nu из (0, 1]: верхняя граница доли выбросов в обучающей выборке
+	// и нижняя граница доли опорных векторов.
+	Nu float64
+
+	// Степень многочлена для полиномиального ядра.
+	Degree int
+
+	// Свободный член для полиномиального ядра.
+	Coef0 float64
+
+	// Масштаб для ядер 'rbf', 'sigmoid', 'laplacian', 'chi2' и 'rational_quadratic'.
+	// Используется, если GammaMode равен GammaValue.
+	Gamma float64
+
+	// Способ выбора gamma: значение из поля Gamma или вычисление по обучающей выборке
+	// ('scale' или 'auto') при вызове Fit.
+	GammaMode GammaMode
+
+	// Параметр alpha для ядра 'rational_quadratic'.
+	KernelAlpha float64
+
+	// Точность.
+	Tol float64
+
+	// Максимальное количество итераций SMO - оптимизаций одной пары параметров альфа.
+	// Значение 0 (по умолчанию) означает max(10000000, 100 * nSamples), как в LIBSVM.
+	MaxIters int
+
+	// Логгер для сообщений о ходе обучения. Если равен nil, то сообщения не выводятся.
+	Logger svm.Logger
+
+	// Функция, которую солвер вызывает после каждой итерации. Если равна nil, то не вызывается.
+	Callback Callback
+
+	// Опорные вектора обученной модели.
+	supportVectors [][]float64
+
+	// Двойственные коэффициенты опорных векторов.
+	dualCoef []float64
+
+	// Порог решающей функции: f(x) = sum(dualCoef[i] * K(sv[i], x)) - rho.
+	rho float64
+
+	// Значение gamma, с которым обучена модель.
+	fittedGamma float64
+
+	// Доля объектов обучающей выборки, которые модель считает выбросами.
+	trainingOutlierFraction float64
+	// Доля объектов обучающей выборки со значением решающей функции меньше -Tol.
+	trainingStrictOutlierFraction float64
+
+	// Число образцов обучающей выборки
+	nSamples int
+	// Число характеристик обучающей выборки
+	nFeatures int
+}
+
+// NewOneClassSVM возвращает экземпляр OneClassSVM с параметрами по умолчанию.
+func NewOneClassSVM() *OneClassSVM {
+	return &OneClassSVM{
+		kernelName:                    "rbf",
+		Kernel:                        &RbfKernel{Gamma: 1.0},
+		kernelCache:                   nil,
+		CacheSizeMB:                   defaultCacheSizeMB,
+		Shrinking:                     true,
+		Nu:                            0.5,
+		Degree:                        3,
+		Coef0:                         0.0,
+		Gamma:                         1.0,
+		GammaMode:                     GammaValue,
+		KernelAlpha:                   1.0,
+		Tol:                           0.001,
+		MaxIters:                      0,
+		Logger:                        nil,
+		Callback:                      nil,
+		supportVectors:                nil,
+		dualCoef:                      nil,
+		rho:                           0.0,
+		fittedGamma:                   0.0,
+		trainingOutlierFraction:       0.0,
+		trainingStrictOutlierFraction: 0.0,
+		nSamples:                      0,
+		nFeatures:                     0,
+	}
+}
+
+// SetKernelByName устанавливает ядро по его имени из реестра ядер.
+// Гиперпараметры ядра берутся из полей Degree, Coef0, Gamma и KernelAlpha.
+// Возвращает ошибку в случае неизвестного ядра.
+func (oc *OneClassSVM) SetKernelByName(kernelName string) error {
+	name, kernel, err := kernelByName(kernelName, oc.kernelParams())
+	if err != nil {
+		return err
+	}
+	oc.kernelName = name
+	oc.Kernel = kernel
+	return nil
+}
+
+// SetKernel устанавливает собственный объект ядра, который используется при обучении как есть.
+func (oc *OneClassSVM) SetKernel(kernel Kernel) {
+	oc.kernelName = ""
+	oc.Kernel = kernel
+}
+
+// kernelParams возвращает гиперпараметры ядра из полей структуры.
+func (oc *OneClassSVM) kernelParams() KernelParams {
+	return KernelParams{
+		Degree: oc.Degree,
+		Coef0:  oc.Coef0,
+		Gamma:  oc.Gamma,
+		Alpha:  oc.KernelAlpha,
+	}
+}
+
+// Fit обучает алгоритм на обучающей выборке без разметки.
+// x - матрица признаков.
+func (oc *OneClassSVM) Fit(x [][]float64) error {
+	return oc.FitContext(context.Background(), x)
+}
+
+// FitContext обучает алгоритм на обучающей выборке без разметки.
+// Если контекст отменен или истек его срок, то обучение прерывается и возвращается ошибка контекста.
+// x - матрица признаков.
+func (oc *OneClassSVM) FitContext(ctx context.Context, x [][]float64) error {
+	// Проверим валидность входных данных.
+	if err := oc.validateInput(x); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+
+	// Ядро, заданное по имени, построим из текущих значений гиперпараметров.
+	gamma, err := resolveGamma(oc.GammaMode, oc.Gamma, x)
+	if err != nil {
+		return fmt.Errorf("invalid gamma: %w", err)
+	}
+	params := oc.kernelParams()
+	params.Gamma = gamma
+	kernel, err := resolveKernel(oc.kernelName, params, oc.Kernel, len(x[0]))
+	if err != nil {
+		return fmt.Errorf("invalid kernel: %w", err)
+	}
+	oc.Kernel = kernel
+	oc.fittedGamma = gamma
+
+	oc.nSamples = len(x)
+	oc.nFeatures = len(x[0])
+
+	// Закэшируем произведения ядра.
+	oc.kernelCache = newKernelRowCache(oc.Kernel, false, x, oc.CacheSizeMB)
+
+	loggerOrNop(oc.Logger).Info("One-class SVM fitting started", "samples", oc.nSamples, "features", oc.nFeatures)
+	err = oc.smo(ctx, x)
+
+	// Кэш ядра нужен только во время обучения.
+	oc.kernelCache = nil
+
+	return err
+}
+
+// validateInput проверяет валидность входных данных и параметров для обучения.
+func (oc *OneClassSVM) validateInput(x [][]float64) error {
+	if oc.Nu <= 0 || oc.Nu > 1 {
+		return fmt.Errorf("nu must be in (0, 1], actual: %v", oc.Nu)
+	}
+
+	if len(x) == 0 {
+		return fmt.Errorf("training set is empty")
+	}
+
+	// Проверим, что матрица признаков является прямоугольной.
+	if !vector_operations.IsMatrixRectangular(x) {
+		return fmt.Errorf("feature matrix must be rectangular")
+	}
+
+	return nil
+}
+
+// smo решает двойственную задачу одноклассового SVM методом SMO:
+// min 0.5 * a^T * K * a, e^T * a = nu * nSamples, 0 <= a[i] <= 1.
+// Начальное допустимое решение: первые floor(nu * nSamples) параметров равны 1,
+// следующий параметр равен остатку.
+func (oc *OneClassSVM) smo(ctx context.Context, x [][]float64) error {
+	l := oc.nSamples
+	p := make([]float64, l)
+	y := make([]float64, l)
+	c := make([]float64, l)
+	alpha := make([]float64, l)
+	for i := 0; i < l; i++ {
+		y[i] = 1
+		c[i] = 1
+	}
+	n := int(oc.Nu * float64(l))
+	for i := 0; i < n; i++ {
+		alpha[i] = 1
+	}
+	if n < l {
+		alpha[n] = oc.Nu*float64(l) - float64(n)
+	}
+
+	q := &svcQMatrix{y: y, kernelCache: oc.kernelCache}
+	solver := newSMOSolver(q, p, y, c, alpha, oc.Tol, oc.MaxIters)
+	solver.shrinking = oc.Shrinking
+	solver.ctx = ctx
+	solver.callback = oc.Callback
+	res, err := solver.solve()
+	if err != nil {
+		return err
+	}
+	logSMOResult(loggerOrNop(oc.Logger), res, solver.maxIters)
+
+	oc.rho = res.rho
+
+	// Оставим только опорные вектора.
+	oc.supportVectors = make([][]float64, 0)
+	oc.dualCoef = make([]float64, 0)
+	for i := 0; i < l; i++ {
+		if res.alpha[i] == 0 {
+			continue
+		}
+		sv := make([]float64, oc.nFeatures)
+		copy(sv, x[i])
+		oc.supportVectors = append(oc.supportVectors, sv)
+		oc.dualCoef = append(oc.dualCoef, res.alpha[i])
+	}
+
+	// Долю выбросов в обучающей выборке посчитаем тем же правилом, что и в Predict,
+	// чтобы она совпадала с долей меток -1 в предсказании для обучающей выборки.
+	oc.trainingOutlierFraction = OutlierFraction(oc.Predict(x))
+	// Долю выбросов, которую ограничивает Nu, посчитаем с учетом точности решения Tol.
+	nOutside := 0
+	for _, value := range oc.DecisionFunction(x) {
+		if value < -oc.Tol {
+			nOutside++
+		}
+	}
+	oc.trainingStrictOutlierFraction = float64(nOutside) / float64(len(x))
+	return nil
+}
+
+// DecisionFunction возвращает значения решающей функции для входных данных:
+// положительное значение соответствует нормальным объектам, отрицательное - выбросам.
+// x - матрица признаков.
+func (oc *OneClassSVM) DecisionFunction(x [][]float64) []float64 {
+	res := make([]float64, len(x))
+	for i := range x {
+		res[i] = oc.f(x[i])
+	}
+	return res
+}
+
+// Predict определяет для новых входных данных, являются ли они нормальными объектами (+1) или выбросами (-1).
+// x - матрица признаков.
+func (oc *OneClassSVM) Predict(x [][]float64) []int {
+	labels := make([]int, len(x))
+	for i := range x {
+		if oc.f(x[i]) >= 0 {
+			labels[i] = 1
+		} else {
+			labels[i] = -1
+		}
+	}
+	return labels
+}
+
+// Вычисления f(x) по опорным векторам обученной модели.
+func (oc *OneClassSVM) f(x []float64) float64 {
+	result := 0.0
+	for i := range oc.supportVectors {
+		result += oc.dualCoef[i] * oc.Kernel.Calculate(oc.supportVectors[i], x)
+	}
+	return result - oc.rho
+}
+
+// TrainingOutlierFraction возвращает долю объектов обучающей выборки, которые лежат вне найденной области,
+// то есть долю меток -1 в результате Predict для обучающей выборки.
+// Опорные вектора на границе области могут получить значение решающей функции чуть меньше нуля,
+// поэтому доля может быть немного больше Nu. Долю, ограниченную Nu, возвращает TrainingStrictOutlierFraction.
+func (oc *OneClassSVM) TrainingOutlierFraction() float64 {
+	return oc.trainingOutlierFraction
+}
+
+// TrainingStrictOutlierFraction возвращает долю объектов обучающей выборки, которые лежат вне найденной области
+// дальше, чем на точность решения Tol, то есть со значением решающей функции меньше -Tol.
+// По свойствам nu-параметризации эта доля не превосходит Nu.
+func (oc *OneClassSVM) TrainingStrictOutlierFraction() float64 {
+	return oc.trainingStrictOutlierFraction
+}
+
+// SupportVectors возвращает опорные вектора обученной модели.
+func (oc *OneClassSVM) SupportVectors() [][]float64 {
+	return oc.supportVectors
+}
+
+// DualCoef возвращает двойственные коэффициенты опорных векторов.
+func (oc *OneClassSVM) DualCoef() []float64 {
+	return oc.dualCoef
+}
+
+// Intercept возвращает свободный член решающей функции (-rho).
+func (oc *OneClassSVM) Intercept() float64 {
+	return -oc.rho
+}
+
+// FittedGamma возвращает значение gamma, с которым обучена модель.
+// Для режимов 'scale' и 'auto' это значение, вычисленное по обучающей выборке.
+func (oc *OneClassSVM) FittedGamma() float64 {
+	return oc.fittedGamma
+}
+
+// Clone возвращает копию одноклассового SVM.
+func (oc *OneClassSVM) Clone() (*OneClassSVM, error) {
+	res := &OneClassSVM{}
+	if err := copier.Copy(res, oc); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// OutlierFraction возвращает долю выбросов (меток -1) в результате Predict.
+func OutlierFraction(labels []int) float64 {
+	if len(labels) == 0 {
+		return 0.0
+	}
+	return float64(vector_operations.Count(labels, -1)) / float64(len(labels))
+}
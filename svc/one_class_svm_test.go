@@ -0,0 +1,103 @@
+package svc
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// gaussianBlob возвращает n точек из двумерного нормального распределения с центром в начале координат.
+func gaussianBlob(n int, seed int64) [][]float64 {
+	r := rand.New(rand.NewSource(seed))
+	x := make([][]float64, n)
+	for i := range x {
+		x[i] = []float64{r.NormFloat64(), r.NormFloat64()}
+	}
+	return x
+}
+
+func TestOneClassSVM_Fit(t *testing.T) {
+	x := gaussianBlob(200, 1)
+
+	for _, nu := range []float64{0.05, 0.2, 0.5} {
+		oc := NewOneClassSVM()
+		oc.Nu = nu
+		oc.Gamma = 1.0
+		if err := oc.Fit(x); err != nil {
+			t.Fatalf("Fit() error = %v", err)
+		}
+
+		// Доля выбросов не превосходит nu, а доля опорных векторов не меньше nu.
+		const slack = 0.02
+		if got := oc.TrainingStrictOutlierFraction(); got > nu {
+			t.Errorf("nu = %v: TrainingStrictOutlierFraction() = %v, want at most nu", nu, got)
+		}
+		if got := float64(len(oc.SupportVectors())) / float64(len(x)); got < nu-slack {
+			t.Errorf("nu = %v: fraction of support vectors = %v, want at least nu", nu, got)
+		}
+		if got, want := OutlierFraction(oc.Predict(x)), oc.TrainingOutlierFraction(); got != want {
+			t.Errorf("nu = %v: OutlierFraction(Predict()) = %v, want %v", nu, got, want)
+		}
+
+		sum := 0.0
+		for _, coef := range oc.DualCoef() {
+			sum += coef
+		}
+		if math.Abs(sum-nu*float64(len(x))) > 1e-9 {
+			t.Errorf("nu = %v: sum(DualCoef()) = %v, want %v", nu, sum, nu*float64(len(x)))
+		}
+
+		// Центр распределения - нормальный объект, далекие точки - выбросы.
+		got := oc.Predict([][]float64{{0, 0}, {6, 6}, {-8, 0}})
+		want := []int{1, -1, -1}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("nu = %v: Predict() = %v, want %v", nu, got, want)
+				break
+			}
+		}
+
+		dec := oc.DecisionFunction([][]float64{{0, 0}, {6, 6}})
+		if dec[0] <= dec[1] {
+			t.Errorf("nu = %v: DecisionFunction() = %v, want larger value for the center", nu, dec)
+		}
+	}
+}
+
+func TestOneClassSVM_FitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		nu   float64
+		x    [][]float64
+	}{
+		{
+			name: "Test nu = 0",
+			nu:   0,
+			x:    [][]float64{{1}, {2}},
+		},
+		{
+			name: "Test nu > 1",
+			nu:   1.5,
+			x:    [][]float64{{1}, {2}},
+		},
+		{
+			name: "Test empty",
+			nu:   0.5,
+			x:    [][]float64{},
+		},
+		{
+			name: "Test not rectangular",
+			nu:   0.5,
+			x:    [][]float64{{1, 2}, {3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oc := NewOneClassSVM()
+			oc.Nu = tt.nu
+			if err := oc.Fit(tt.x); err == nil {
+				t.Errorf("Fit() error = nil, want error")
+			}
+		})
+	}
+}
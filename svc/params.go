@@ -0,0 +1,287 @@
+package svc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Params возвращает гиперпараметры классификатора в виде карты "имя параметра : значение".
+// Имена параметров совпадают с теми, которые принимает SetParams.
+// У ядра, заданного методом SetKernel, нет имени, поэтому параметр "kernel" в этом случае не возвращается,
+// и SetParams(Params()) оставляет такое ядро без изменений.
+func (svc *SVC) Params() map[string]any {
+	res := map[string]any{
+		"formulation":           string(svc.Formulation),
+		"C":                     svc.C,
+		"nu":                    svc.Nu,
+		"degree":                svc.Degree,
+		"coef0":                 svc.Coef0,
+		"gamma":                 svc.Gamma,
+		"gamma_mode":            string(svc.GammaMode),
+		"kernel_alpha":          svc.KernelAlpha,
+		"tol":                   svc.Tol,
+		"max_iters":             svc.MaxIters,
+		"seed":                  svc.Seed,
+		"solver":                string(svc.Solver),
+		"probability":           svc.Probability,
+		"cache_size_mb":         svc.CacheSizeMB,
+		"shrinking":             svc.Shrinking,
+		"class_weight":          svc.ClassWeight,
+		"balanced_class_weight": svc.BalancedClassWeight,
+	}
+	if svc.kernelName != "" {
+		res["kernel"] = string(svc.kernelName)
+	}
+	return res
+}
+
+// SetParams устанавливает гиперпараметры классификатора из карты "имя параметра : значение".
+// Значения проверяются до изменения классификатора: при ошибке ни один параметр не меняется.
+// Числовые параметры принимаются как любые целые или вещественные числа.
+// Ядро, заданное по имени, строится заново из новых гиперпараметров.
+func (svc *SVC) SetParams(params map[string]any) error {
+	res := *svc
+	for name, value := range params {
+		if err := res.setParam(name, value); err != nil {
+			return fmt.Errorf("invalid parameter %s: %w", name, err)
+		}
+	}
+
+	// Проверим, что ядро с новыми гиперпараметрами строится.
+	if res.kernelName != "" && res.kernelName != Precomputed {
+		kernel, err := NewKernel(res.kernelName, res.kernelParams())
+		if err != nil {
+			return fmt.Errorf("invalid parameter kernel: %w", err)
+		}
+		res.Kernel = kernel
+	}
+
+	*svc = res
+	return nil
+}
+
+// setParam устанавливает один гиперпараметр с проверкой значения.
+func (svc *SVC) setParam(name string, value any) error {
+	var err error
+	switch name {
+	case "kernel":
+		var kernelName string
+		if kernelName, err = toString(value); err == nil {
+			name := KernelName(strings.ToLower(kernelName))
+			if !kernelRegistered(name) && name != Precomputed {
+				return fmt.Errorf("unknown kernel name: %s", kernelName)
+			}
+			svc.kernelName = name
+		}
+	case "formulation":
+		var formulation string
+		if formulation, err = toString(value); err == nil {
+			switch Formulation(formulation) {
+			case CSVC, NuSVC:
+				svc.Formulation = Formulation(formulation)
+			default:
+				err = fmt.Errorf("unknown formulation: %s", formulation)
+			}
+		}
+	case "C":
+		svc.C, err = toPositiveFloat(value)
+	case "nu":
+		if svc.Nu, err = toFloat(value); err == nil && (svc.Nu <= 0 || svc.Nu > 1) {
+			err = fmt.Errorf("must be in (0, 1], actual: %v", svc.Nu)
+		}
+	case "degree":
+		if svc.Degree, err = toInt(value); err == nil && svc.Degree < 1 {
+			err = fmt.Errorf("must be at least 1, actual: %d", svc.Degree)
+		}
+	case "coef0":
+		svc.Coef0, err = toFloat(value)
+	case "gamma":
+		svc.Gamma, err = toPositiveFloat(value)
+	case "gamma_mode":
+		var mode string
+		if mode, err = toString(value); err == nil {
+			switch GammaMode(mode) {
+			case GammaValue, GammaScale, GammaAuto:
+				svc.GammaMode = GammaMode(mode)
+			default:
+				err = fmt.Errorf("unknown gamma mode: %s", mode)
+			}
+		}
+	case "kernel_alpha":
+		svc.KernelAlpha, err = toPositiveFloat(value)
+	case "tol":
+		svc.Tol, err = toPositiveFloat(value)
+	case "max_iters":
+		if svc.MaxIters, err = toInt(value); err == nil && svc.MaxIters < 0 {
+			err = fmt.Errorf("must be non-negative, actual: %d", svc.MaxIters)
+		}
+	case "seed":
+		svc.Seed, err = toInt64(value)
+	case "solver":
+		var solver string
+		if solver, err = toString(value); err == nil {
+			switch SolverName(solver) {
+			case SMO, SimplifiedSMO:
+				svc.Solver = SolverName(solver)
+			default:
+				err = fmt.Errorf("unknown solver name: %s", solver)
+			}
+		}
+	case "probability":
+		svc.Probability, err = toBool(value)
+	case "cache_size_mb":
+		if svc.CacheSizeMB, err = toFloat(value); err == nil && svc.CacheSizeMB < 0 {
+			err = fmt.Errorf("must be non-negative, actual: %v", svc.CacheSizeMB)
+		}
+	case "shrinking":
+		svc.Shrinking, err = toBool(value)
+	case "class_weight":
+		classWeight, ok := value.(map[int]float64)
+		if !ok && value != nil {
+			return fmt.Errorf("expected map[int]float64, actual: %T", value)
+		}
+		for label, w := range classWeight {
+			if w <= 0 {
+				return fmt.Errorf("class weight must be positive, actual: %v for label %d", w, label)
+			}
+		}
+		svc.ClassWeight = classWeight
+	case "balanced_class_weight":
+		svc.BalancedClassWeight, err = toBool(value)
+	default:
+		err = fmt.Errorf("unknown parameter")
+	}
+	return err
+}
+
+// Params возвращает гиперпараметры классификатора в виде карты "имя параметра : значение".
+// Помимо гиперпараметров бинарных классификаторов содержит стратегию "strategy".
+func (m *MultiSVC) Params() map[string]any {
+	res := m.SVC.Params()
+	res["strategy"] = string(m.Strategy)
+	return res
+}
+
+// SetParams устанавливает гиперпараметры классификатора из карты "имя параметра : значение".
+// Значения проверяются до изменения классификатора: при ошибке ни один параметр не меняется.
+func (m *MultiSVC) SetParams(params map[string]any) error {
+	strategy := m.Strategy
+	svcParams := make(map[string]any, len(params))
+	for name, value := range params {
+		if name != "strategy" {
+			svcParams[name] = value
+			continue
+		}
+		s, err := toString(value)
+		if err == nil {
+			switch Strategy(s) {
+			case OvR, OvO:
+				strategy = Strategy(s)
+			default:
+				err = fmt.Errorf("unknown strategy: %s", s)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid parameter %s: %w", name, err)
+		}
+	}
+
+	if err := m.SVC.SetParams(svcParams); err != nil {
+		return err
+	}
+	m.Strategy = strategy
+	return nil
+}
+
+// toFloat приводит целое или вещественное число к float64.
+func toFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("expected a number, actual: %T", value)
+	}
+}
+
+// toPositiveFloat приводит число к float64 и проверяет, что оно положительное.
+func toPositiveFloat(value any) (float64, error) {
+	res, err := toFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if res <= 0 {
+		return 0, fmt.Errorf("must be positive, actual: %v", res)
+	}
+	return res, nil
+}
+
+// toInt приводит целое число или вещественное число без дробной части к int.
+func toInt(value any) (int, error) {
+	res, err := toFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if res != math.Trunc(res) {
+		return 0, fmt.Errorf("expected an integer, actual: %v", res)
+	}
+	return int(res), nil
+}
+
+// toInt64 приводит целое число или вещественное число без дробной части к int64.
+// Целые числа приводятся без потери точности.
+func toInt64(value any) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	}
+	res, err := toFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if res != math.Trunc(res) {
+		return 0, fmt.Errorf("expected an integer, actual: %v", res)
+	}
+	return int64(res), nil
+}
+
+// toString приводит строку или строковый тип к string.
+func toString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case KernelName:
+		return string(v), nil
+	case Formulation:
+		return string(v), nil
+	case SolverName:
+		return string(v), nil
+	case Strategy:
+		return string(v), nil
+	case GammaMode:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("expected a string, actual: %T", value)
+	}
+}
+
+// toBool приводит значение к bool.
+func toBool(value any) (bool, error) {
+	res, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a bool, actual: %T", value)
+	}
+	return res, nil
+}
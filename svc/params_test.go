@@ -0,0 +1,235 @@
+package svc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSVC_SetParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:   "Test valid params",
+			params: map[string]any{"kernel": "poly", "C": 10, "degree": 5, "gamma": 0.5, "coef0": 1.0},
+			want:   map[string]any{"kernel": "poly", "C": 10.0, "degree": 5, "gamma": 0.5, "coef0": 1.0},
+		},
+		{
+			name:   "Test typed string params",
+			params: map[string]any{"kernel": Rbf, "solver": SimplifiedSMO, "formulation": NuSVC, "nu": 0.3, "gamma_mode": GammaScale},
+			want:   map[string]any{"kernel": "rbf", "solver": "simplified_smo", "formulation": "nu_svc", "nu": 0.3, "gamma_mode": "scale"},
+		},
+		{
+			name:   "Test seed",
+			params: map[string]any{"seed": int64(1) << 60},
+			want:   map[string]any{"seed": int64(1) << 60},
+		},
+		{
+			name:   "Test untyped seed",
+			params: map[string]any{"seed": 42},
+			want:   map[string]any{"seed": int64(42)},
+		},
+		{
+			name:   "Test default max_iters",
+			params: map[string]any{"max_iters": 0},
+			want:   map[string]any{"max_iters": 0},
+		},
+		{
+			name:    "Test negative max_iters",
+			params:  map[string]any{"max_iters": -1},
+			wantErr: true,
+		},
+		{
+			name:    "Test fractional seed",
+			params:  map[string]any{"seed": 0.5},
+			wantErr: true,
+		},
+		{
+			name:    "Test non-positive gamma",
+			params:  map[string]any{"gamma": 0.0},
+			wantErr: true,
+		},
+		{
+			name:    "Test zero degree",
+			params:  map[string]any{"degree": 0},
+			wantErr: true,
+		},
+		{
+			name:    "Test fractional degree",
+			params:  map[string]any{"degree": 2.5},
+			wantErr: true,
+		},
+		{
+			name:    "Test negative C",
+			params:  map[string]any{"C": -1},
+			wantErr: true,
+		},
+		{
+			name:    "Test nu greater than 1",
+			params:  map[string]any{"nu": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown kernel",
+			params:  map[string]any{"kernel": "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown parameter",
+			params:  map[string]any{"C": 2.0, "unknown": 1},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown gamma mode",
+			params:  map[string]any{"gamma_mode": "median"},
+			wantErr: true,
+		},
+		{
+			name:    "Test wrong type",
+			params:  map[string]any{"gamma": "0.5"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			before := svc.Params()
+			err := svc.SetParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := svc.Params()
+			if tt.wantErr {
+				// При ошибке классификатор не должен измениться.
+				if !reflect.DeepEqual(got, before) {
+					t.Errorf("Params() = %v, want %v", got, before)
+				}
+				return
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("Params()[%s] = %v, want %v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSVC_SetParamsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		kernel Kernel
+		params map[string]any
+	}{
+		{
+			name:   "Test named kernel",
+			params: map[string]any{"kernel": "rbf", "gamma": 0.1, "C": 5.0, "seed": 7},
+		},
+		{
+			name:   "Test kernel set by SetKernel",
+			kernel: &LaplacianKernel{Gamma: 0.3},
+			params: map[string]any{"C": 5.0, "seed": 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			if tt.kernel != nil {
+				svc.SetKernel(tt.kernel)
+			}
+			if err := svc.SetParams(tt.params); err != nil {
+				t.Fatalf("SetParams() error = %v", err)
+			}
+
+			// Параметры можно установить тому же классификатору, например, при переборе гиперпараметров.
+			params := svc.Params()
+			if err := svc.SetParams(params); err != nil {
+				t.Fatalf("SetParams(Params()) error = %v", err)
+			}
+			if !reflect.DeepEqual(svc.Params(), params) {
+				t.Errorf("Params() = %v, want %v", svc.Params(), params)
+			}
+
+			other := NewSVC()
+			if tt.kernel != nil {
+				other.SetKernel(tt.kernel)
+			}
+			if err := other.SetParams(params); err != nil {
+				t.Fatalf("SetParams() error = %v", err)
+			}
+			if !reflect.DeepEqual(other.Params(), params) {
+				t.Errorf("Params() = %v, want %v", other.Params(), params)
+			}
+			if !reflect.DeepEqual(other.Kernel, svc.Kernel) {
+				t.Errorf("Kernel = %v, want %v", other.Kernel, svc.Kernel)
+			}
+		})
+	}
+}
+
+func TestSVC_FitUsesCurrentKernelParams(t *testing.T) {
+	svc := NewSVC()
+	if err := svc.SetKernelByName("poly"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	// Гиперпараметры, заданные после выбора ядра, должны учитываться при обучении.
+	svc.Degree = 5
+	svc.Coef0 = 1
+	if err := svc.Fit(separableX, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	want := &PolyKernel{Gamma: 1, Degree: 5, Coef0: 1}
+	if !reflect.DeepEqual(svc.Kernel, want) {
+		t.Errorf("Kernel = %v, want %v", svc.Kernel, want)
+	}
+}
+
+func TestSVC_FitPolyGammaMode(t *testing.T) {
+	// У объектов separableX два признака, поэтому в режиме auto gamma = 1/2.
+	scale, err := resolveGamma(GammaScale, 0, separableX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for mode, want := range map[GammaMode]float64{GammaValue: 1, GammaAuto: 0.5, GammaScale: scale} {
+		svc := NewSVC()
+		if err := svc.SetKernelByName("poly"); err != nil {
+			t.Fatalf("SetKernelByName() error = %v", err)
+		}
+		svc.Degree = 2
+		svc.GammaMode = mode
+		if err := svc.Fit(separableX, separableY); err != nil {
+			t.Fatalf("%s: Fit() error = %v", mode, err)
+		}
+		if got := svc.Kernel.(*PolyKernel).Gamma; got != want {
+			t.Errorf("%s: Gamma = %v, want %v", mode, got, want)
+		}
+	}
+}
+
+func TestMultiSVC_SetParams(t *testing.T) {
+	m := NewMultiSVC()
+	if err := m.SetParams(map[string]any{"strategy": "ovo", "kernel": "linear", "C": 2}); err != nil {
+		t.Fatalf("SetParams() error = %v", err)
+	}
+	if m.Strategy != OvO || m.C != 2 {
+		t.Errorf("Strategy = %v, C = %v, want %v, %v", m.Strategy, m.C, OvO, 2)
+	}
+	if got := m.Params()["strategy"]; got != string(OvO) {
+		t.Errorf("Params()[strategy] = %v, want %v", got, OvO)
+	}
+
+	if err := m.SetParams(map[string]any{"strategy": "unknown"}); err == nil {
+		t.Errorf("SetParams() expected error for unknown strategy")
+	}
+	if err := m.SetParams(map[string]any{"strategy": "ovr", "gamma": -1}); err == nil {
+		t.Errorf("SetParams() expected error for negative gamma")
+	}
+	if m.Strategy != OvO {
+		t.Errorf("Strategy = %v, want %v", m.Strategy, OvO)
+	}
+}
@@ -0,0 +1,451 @@
+package svc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// ModelFormatVersion - текущая версия формата сохраненной модели.
+const ModelFormatVersion = 1
+
+// Название формата сохраненной модели.
+const modelFormatName = "svm-model"
+
+// Сигнатура, с которой начинается модель, сохраненная в бинарном формате.
+var binaryModelMagic = []byte("SVMB")
+
+// Типы сохраняемых моделей.
+const (
+	svcModelType      = "svc"
+	multiSVCModelType = "multi_svc"
+)
+
+// modelFile описывает файл сохраненной модели.
+type modelFile struct {
+	Format   string         `json:"format"`
+	Version  int            `json:"version"`
+	Type     string         `json:"type"`
+	SVC      *svcModel      `json:"svc,omitempty"`
+	MultiSVC *multiSVCModel `json:"multi_svc,omitempty"`
+}
+
+// kernelModel описывает сохраненное ядро - его имя и параметры,
+// а также способ выбора gamma и значение gamma, с которым обучена модель.
+type kernelModel struct {
+	Name        KernelName         `json:"name"`
+	Params      map[string]float64 `json:"params,omitempty"`
+	GammaMode   GammaMode          `json:"gamma_mode,omitempty"`
+	FittedGamma float64            `json:"fitted_gamma,omitempty"`
+}
+
+// svcModel описывает сохраненный бинарный классификатор.
+// Сохраняются только опорные вектора, двойственные коэффициенты alpha[i] * y[i], порог
+// и, если модель обучена с оценкой вероятностей, параметры сигмоиды Платта.
+type svcModel struct {
+	Kernel         kernelModel   `json:"kernel"`
+	SupportVectors [][]float64   `json:"support_vectors"`
+	DualCoef       []float64     `json:"dual_coef"`
+	Intercept      float64       `json:"intercept"`
+	Platt          *plattSigmoid `json:"platt,omitempty"`
+}
+
+// labeledSVCModel описывает сохраненный бинарный классификатор для метки класса.
+type labeledSVCModel struct {
+	Label   int      `json:"label"`
+	Machine svcModel `json:"machine"`
+}
+
+// pairSVCModel описывает сохраненный бинарный классификатор для пары меток классов.
+type pairSVCModel struct {
+	Positive int      `json:"positive"`
+	Negative int      `json:"negative"`
+	Machine  svcModel `json:"machine"`
+}
+
+// multiSVCModel описывает сохраненный многоклассовый классификатор.
+// Для стратегии OvR сохраняются классификаторы Machines, для стратегии OvO - PairMachines.
+type multiSVCModel struct {
+	Kernel       kernelModel       `json:"kernel"`
+	Strategy     Strategy          `json:"strategy,omitempty"`
+	Labels       []int             `json:"labels"`
+	Machines     []labeledSVCModel `json:"machines,omitempty"`
+	PairMachines []pairSVCModel    `json:"pair_machines,omitempty"`
+}
+
+// Save сохраняет обученную модель в формате JSON.
+func (svc *SVC) Save(w io.Writer) error {
+	model, err := svc.toModel()
+	if err != nil {
+		return err
+	}
+	return writeJSONModel(w, modelFile{Type: svcModelType, SVC: &model})
+}
+
+// SaveBinary сохраняет обученную модель в компактном бинарном формате.
+func (svc *SVC) SaveBinary(w io.Writer) error {
+	model, err := svc.toModel()
+	if err != nil {
+		return err
+	}
+	return writeBinaryModel(w, modelFile{Type: svcModelType, SVC: &model})
+}
+
+// Load загружает модель, сохраненную методом Save или SaveBinary.
+// Формат определяется автоматически.
+func (svc *SVC) Load(r io.Reader) error {
+	mf, err := readModel(r, svcModelType)
+	if err != nil {
+		return err
+	}
+	if mf.SVC == nil {
+		return fmt.Errorf("model data is missing")
+	}
+	return svc.fromModel(*mf.SVC)
+}
+
+// Save сохраняет обученную модель в формате JSON.
+func (m *MultiSVC) Save(w io.Writer) error {
+	model, err := m.toModel()
+	if err != nil {
+		return err
+	}
+	return writeJSONModel(w, modelFile{Type: multiSVCModelType, MultiSVC: &model})
+}
+
+// SaveBinary сохраняет обученную модель в компактном бинарном формате.
+func (m *MultiSVC) SaveBinary(w io.Writer) error {
+	model, err := m.toModel()
+	if err != nil {
+		return err
+	}
+	return writeBinaryModel(w, modelFile{Type: multiSVCModelType, MultiSVC: &model})
+}
+
+// Load загружает модель, сохраненную методом Save или SaveBinary.
+// Формат определяется автоматически.
+func (m *MultiSVC) Load(r io.Reader) error {
+	mf, err := readModel(r, multiSVCModelType)
+	if err != nil {
+		return err
+	}
+	if mf.MultiSVC == nil {
+		return fmt.Errorf("model data is missing")
+	}
+	return m.fromModel(*mf.MultiSVC)
+}
+
+// toModel возвращает представление обученного классификатора для сохранения.
+func (svc *SVC) toModel() (svcModel, error) {
+	if svc.dualCoef == nil {
+		return svcModel{}, fmt.Errorf("model is not fitted")
+	}
+
+	kernel, err := svc.kernelToModel()
+	if err != nil {
+		return svcModel{}, err
+	}
+
+	return svcModel{
+		Kernel:         kernel,
+		SupportVectors: svc.supportVectors,
+		DualCoef:       svc.dualCoef,
+		Intercept:      svc.b,
+		Platt:          svc.platt,
+	}, nil
+}
+
+// fromModel восстанавливает состояние обученного классификатора из сохраненного представления.
+func (svc *SVC) fromModel(model svcModel) error {
+	if len(model.SupportVectors) != len(model.DualCoef) {
+		return fmt.Errorf("number of support vectors (%d) does not match number of dual coefficients (%d)",
+			len(model.SupportVectors), len(model.DualCoef))
+	}
+	if len(model.SupportVectors) > 0 && !vector_operations.IsMatrixRectangular(model.SupportVectors) {
+		return fmt.Errorf("support vectors matrix must be rectangular")
+	}
+
+	if err := svc.setKernelFromModel(model.Kernel); err != nil {
+		return err
+	}
+
+	svc.supportVectors = model.SupportVectors
+	svc.dualCoef = model.DualCoef
+	svc.nSupport = make([]int, 2)
+	for _, coef := range model.DualCoef {
+		if coef > 0 {
+			svc.nSupport[1]++
+		} else {
+			svc.nSupport[0]++
+		}
+	}
+	svc.b = model.Intercept
+	svc.platt = model.Platt
+	svc.Probability = model.Platt != nil
+	svc.supportVectorsIdx = nil
+	svc.x = nil
+	svc.y = nil
+	svc.alphas = nil
+	svc.kernelCache = nil
+	svc.nSamples = 0
+	svc.nFeatures = 0
+	if len(model.SupportVectors) > 0 {
+		svc.nFeatures = len(model.SupportVectors[0])
+	}
+	svc.nClasses = 2
+	return nil
+}
+
+// setKernelFromModel устанавливает ядро и его гиперпараметры из сохраненного представления.
+// Поля структуры меняются только для сохраненных гиперпараметров.
+func (svc *SVC) setKernelFromModel(model kernelModel) error {
+	params := KernelParams{
+		Degree: int(model.Params["degree"]),
+		Coef0:  model.Params["coef0"],
+		Gamma:  model.Params["gamma"],
+		Alpha:  model.Params["alpha"],
+	}
+	kernel, err := NewKernel(model.Name, params)
+	if err != nil {
+		return err
+	}
+
+	svc.kernelName = model.Name
+	svc.Kernel = kernel
+	if _, ok := model.Params["degree"]; ok {
+		svc.Degree = params.Degree
+	}
+	if _, ok := model.Params["coef0"]; ok {
+		svc.Coef0 = params.Coef0
+	}
+	if _, ok := model.Params["gamma"]; ok {
+		svc.Gamma = params.Gamma
+	}
+	if _, ok := model.Params["alpha"]; ok {
+		svc.KernelAlpha = params.Alpha
+	}
+	if model.GammaMode != "" {
+		svc.GammaMode = model.GammaMode
+	}
+	// В моделях без сохраненного значения gamma обучения им является параметр ядра.
+	svc.fittedGamma = model.FittedGamma
+	if svc.fittedGamma == 0 {
+		svc.fittedGamma = params.Gamma
+	}
+	return nil
+}
+
+// toModel возвращает представление обученного классификатора для сохранения.
+func (m *MultiSVC) toModel() (multiSVCModel, error) {
+	if m.Machines == nil && m.PairMachines == nil {
+		return multiSVCModel{}, fmt.Errorf("model is not fitted")
+	}
+
+	kernel, err := m.kernelToModel()
+	if err != nil {
+		return multiSVCModel{}, err
+	}
+
+	model := multiSVCModel{
+		Kernel: kernel,
+		Labels: m.labels,
+	}
+
+	if m.PairMachines != nil {
+		model.Strategy = OvO
+		model.PairMachines = make([]pairSVCModel, 0, len(m.PairMachines))
+		for _, pair := range m.labelPairs() {
+			machine, err := m.PairMachines[pair].toModel()
+			if err != nil {
+				return multiSVCModel{}, fmt.Errorf("error in saving a binary classifier for labels %d and %d: %w",
+					pair.Positive, pair.Negative, err)
+			}
+			model.PairMachines = append(model.PairMachines, pairSVCModel{
+				Positive: pair.Positive,
+				Negative: pair.Negative,
+				Machine:  machine,
+			})
+		}
+		return model, nil
+	}
+
+	model.Strategy = OvR
+	model.Machines = make([]labeledSVCModel, 0, len(m.labels))
+	for _, label := range m.labels {
+		machine, err := m.Machines[label].toModel()
+		if err != nil {
+			return multiSVCModel{}, fmt.Errorf("error in saving a binary classifier for label %d: %w", label, err)
+		}
+		model.Machines = append(model.Machines, labeledSVCModel{Label: label, Machine: machine})
+	}
+	return model, nil
+}
+
+// fromModel восстанавливает состояние обученного классификатора из сохраненного представления.
+func (m *MultiSVC) fromModel(model multiSVCModel) error {
+	if err := m.setKernelFromModel(model.Kernel); err != nil {
+		return err
+	}
+
+	m.labels = model.Labels
+	m.nClasses = len(model.Labels)
+	m.Machines = nil
+	m.PairMachines = nil
+
+	var loaded []*SVC
+	switch model.Strategy {
+	case OvR, "":
+		machines := make(map[int]*SVC, len(model.Machines))
+		for _, lm := range model.Machines {
+			svc := NewSVC()
+			if err := svc.fromModel(lm.Machine); err != nil {
+				return fmt.Errorf("error in loading a binary classifier for label %d: %w", lm.Label, err)
+			}
+			machines[lm.Label] = svc
+			loaded = append(loaded, svc)
+		}
+		for _, label := range model.Labels {
+			if _, ok := machines[label]; !ok {
+				return fmt.Errorf("binary classifier for label %d is missing", label)
+			}
+		}
+		m.Strategy = OvR
+		m.Machines = machines
+	case OvO:
+		machines := make(map[LabelPair]*SVC, len(model.PairMachines))
+		for _, pm := range model.PairMachines {
+			svc := NewSVC()
+			if err := svc.fromModel(pm.Machine); err != nil {
+				return fmt.Errorf("error in loading a binary classifier for labels %d and %d: %w",
+					pm.Positive, pm.Negative, err)
+			}
+			machines[LabelPair{Positive: pm.Positive, Negative: pm.Negative}] = svc
+			loaded = append(loaded, svc)
+		}
+		for _, pair := range m.labelPairs() {
+			if _, ok := machines[pair]; !ok {
+				return fmt.Errorf("binary classifier for labels %d and %d is missing", pair.Positive, pair.Negative)
+			}
+		}
+		m.Strategy = OvO
+		m.PairMachines = machines
+	default:
+		return fmt.Errorf("unknown strategy: %s", model.Strategy)
+	}
+
+	m.Probability = len(loaded) > 0
+	for _, machine := range loaded {
+		m.Probability = m.Probability && machine.Probability
+	}
+	return nil
+}
+
+// kernelToModel возвращает представление ядра классификатора для сохранения: имя ядра в реестре
+// и его ненулевые гиперпараметры.
+// Ядро должно реализовывать интерфейс ParametrizedKernel и строиться из реестра по имени и гиперпараметрам,
+// иначе его нельзя восстановить при загрузке.
+func (svc *SVC) kernelToModel() (kernelModel, error) {
+	if svc.Pairwise() {
+		return kernelModel{}, fmt.Errorf("model with a precomputed kernel cannot be saved")
+	}
+	kernel, ok := svc.Kernel.(ParametrizedKernel)
+	if !ok {
+		return kernelModel{}, fmt.Errorf("kernel %T cannot be saved", svc.Kernel)
+	}
+	params := kernel.Params()
+	if restored, err := NewKernel(svc.kernelName, params); err != nil || !reflect.DeepEqual(restored, svc.Kernel) {
+		return kernelModel{}, fmt.Errorf("kernel %T cannot be restored from the registry by name %s", svc.Kernel, svc.kernelName)
+	}
+
+	model := kernelModel{
+		Name:        svc.kernelName,
+		Params:      make(map[string]float64),
+		GammaMode:   svc.GammaMode,
+		FittedGamma: svc.fittedGamma,
+	}
+	for name, value := range map[string]float64{
+		"degree": float64(params.Degree),
+		"coef0":  params.Coef0,
+		"gamma":  params.Gamma,
+		"alpha":  params.Alpha,
+	} {
+		if value != 0 {
+			model.Params[name] = value
+		}
+	}
+	return model, nil
+}
+
+// writeJSONModel записывает модель в формате JSON.
+func writeJSONModel(w io.Writer, mf modelFile) error {
+	mf.Format = modelFormatName
+	mf.Version = ModelFormatVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(mf); err != nil {
+		return fmt.Errorf("error in encoding model: %w", err)
+	}
+	return nil
+}
+
+// writeBinaryModel записывает модель в бинарном формате:
+// сигнатура, версия формата (uint16, big endian) и модель в кодировке gob.
+func writeBinaryModel(w io.Writer, mf modelFile) error {
+	mf.Format = modelFormatName
+	mf.Version = ModelFormatVersion
+	if _, err := w.Write(binaryModelMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(ModelFormatVersion)); err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(w).Encode(mf); err != nil {
+		return fmt.Errorf("error in encoding model: %w", err)
+	}
+	return nil
+}
+
+// readModel читает модель в формате JSON или в бинарном формате и проверяет ее версию и тип.
+func readModel(r io.Reader, modelType string) (modelFile, error) {
+	br := bufio.NewReader(r)
+	mf := modelFile{}
+
+	header, err := br.Peek(len(binaryModelMagic))
+	if err == nil && bytes.Equal(header, binaryModelMagic) {
+		if _, err := br.Discard(len(binaryModelMagic)); err != nil {
+			return mf, err
+		}
+		var version uint16
+		if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+			return mf, fmt.Errorf("error in reading model version: %w", err)
+		}
+		if int(version) > ModelFormatVersion {
+			return mf, fmt.Errorf("unsupported model format version: %d", version)
+		}
+		if err := gob.NewDecoder(br).Decode(&mf); err != nil {
+			return mf, fmt.Errorf("error in decoding model: %w", err)
+		}
+	} else {
+		if err := json.NewDecoder(br).Decode(&mf); err != nil {
+			return mf, fmt.Errorf("error in decoding model: %w", err)
+		}
+	}
+
+	if mf.Format != modelFormatName {
+		return mf, fmt.Errorf("unknown model format: %q", mf.Format)
+	}
+	if mf.Version < 1 || mf.Version > ModelFormatVersion {
+		return mf, fmt.Errorf("unsupported model format version: %d", mf.Version)
+	}
+	if mf.Type != modelType {
+		return mf, fmt.Errorf("unexpected model type: expected %s, actual: %s", modelType, mf.Type)
+	}
+	return mf, nil
+}
@@ -0,0 +1,263 @@
+package svc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// loadIris загружает набор данных iris из datasets/iris_headers.csv.
+// Метки классов кодируются числами 0, 1, 2 в порядке появления.
+func loadIris(t *testing.T) ([][]float64, []int) {
+	t.Helper()
+
+	f, err := os.Open("../datasets/iris_headers.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make(map[string]int)
+	x := make([][]float64, 0, len(records)-1)
+	y := make([]int, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make([]float64, len(record)-1)
+		for j := range row {
+			row[j], err = strconv.ParseFloat(strings.TrimSpace(record[j]), 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		species := strings.TrimSpace(record[len(record)-1])
+		if _, ok := labels[species]; !ok {
+			labels[species] = len(labels)
+		}
+		x = append(x, row)
+		y = append(y, labels[species])
+	}
+	return x, y
+}
+
+func TestSVC_SaveLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		kernelName string
+		gammaMode  GammaMode
+		binary     bool
+	}{
+		{
+			name:       "Test JSON rbf",
+			kernelName: "rbf",
+			binary:     false,
+		},
+		{
+			name:       "Test binary rbf",
+			kernelName: "rbf",
+			binary:     true,
+		},
+		{
+			name:       "Test JSON poly",
+			kernelName: "poly",
+			binary:     false,
+		},
+		{
+			name:       "Test binary linear",
+			kernelName: "linear",
+			binary:     true,
+		},
+		{
+			name:       "Test JSON sigmoid",
+			kernelName: "sigmoid",
+			binary:     false,
+		},
+		{
+			name:       "Test binary rational quadratic",
+			kernelName: "rational_quadratic",
+			binary:     true,
+		},
+		{
+			name:       "Test JSON rbf with gamma scale",
+			kernelName: "rbf",
+			gammaMode:  GammaScale,
+			binary:     false,
+		},
+		{
+			name:       "Test binary rbf with gamma auto",
+			kernelName: "rbf",
+			gammaMode:  GammaAuto,
+			binary:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			svc.Gamma = 0.1
+			svc.Degree = 2
+			if tt.gammaMode != "" {
+				svc.GammaMode = tt.gammaMode
+			}
+			if err := svc.SetKernelByName(tt.kernelName); err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.Fit(separableX, separableY); err != nil {
+				t.Fatal(err)
+			}
+
+			buf := &bytes.Buffer{}
+			save := svc.Save
+			if tt.binary {
+				save = svc.SaveBinary
+			}
+			if err := save(buf); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded := NewSVC()
+			if err := loaded.Load(buf); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			for _, x := range separableX {
+				if got, want := loaded.f(x), svc.f(x); got != want {
+					t.Errorf("f() of loaded model = %v, want %v", got, want)
+				}
+			}
+			if got, want := loaded.Predict(separableX), svc.Predict(separableX); !reflect.DeepEqual(got, want) {
+				t.Errorf("Predict() of loaded model = %v, want %v", got, want)
+			}
+			if loaded.GammaMode != svc.GammaMode || loaded.FittedGamma() != svc.FittedGamma() {
+				t.Errorf("loaded gamma = %v (%s), want %v (%s)",
+					loaded.FittedGamma(), loaded.GammaMode, svc.FittedGamma(), svc.GammaMode)
+			}
+		})
+	}
+}
+
+func TestMultiSVC_SaveLoad(t *testing.T) {
+	x, y := loadIris(t)
+
+	for _, strategy := range []Strategy{OvR, OvO} {
+		m := NewMultiSVC()
+		m.Strategy = strategy
+		if err := m.Fit(x, y); err != nil {
+			t.Fatal(err)
+		}
+		want := m.DecisionFunction(x)
+
+		for _, binary := range []bool{false, true} {
+			buf := &bytes.Buffer{}
+			save := m.Save
+			if binary {
+				save = m.SaveBinary
+			}
+			if err := save(buf); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded := NewMultiSVC()
+			if err := loaded.Load(buf); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if loaded.Strategy != strategy {
+				t.Errorf("Strategy of loaded model = %v, want %v", loaded.Strategy, strategy)
+			}
+			if got := loaded.DecisionFunction(x); !reflect.DeepEqual(got, want) {
+				t.Errorf("DecisionFunction() of loaded %s model differs from the original", strategy)
+			}
+		}
+	}
+}
+
+func TestSVC_LoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+	}{
+		{
+			name:  "Test unknown format",
+			model: `{"format": "other", "version": 1, "type": "svc", "svc": {}}`,
+		},
+		{
+			name:  "Test unsupported version",
+			model: `{"format": "svm-model", "version": 100, "type": "svc", "svc": {}}`,
+		},
+		{
+			name:  "Test unexpected type",
+			model: `{"format": "svm-model", "version": 1, "type": "multi_svc", "multi_svc": {}}`,
+		},
+		{
+			name:  "Test unknown kernel",
+			model: `{"format": "svm-model", "version": 1, "type": "svc", "svc": {"kernel": {"name": "unknown"}}}`,
+		},
+		{
+			name: "Test inconsistent coefficients",
+			model: `{"format": "svm-model", "version": 1, "type": "svc",
+				"svc": {"kernel": {"name": "linear"}, "support_vectors": [[1, 2]], "dual_coef": [1, -1]}}`,
+		},
+		{
+			name:  "Test invalid data",
+			model: `not a model`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewSVC().Load(strings.NewReader(tt.model)); err == nil {
+				t.Errorf("Load() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSVC_LoadPolyWithoutGamma(t *testing.T) {
+	// Модели с полиномиальным ядром без параметра gamma вычисляются с gamma = 1.
+	model := `{"format": "svm-model", "version": 1, "type": "svc",
+		"svc": {"kernel": {"name": "poly", "params": {"degree": 2, "coef0": 1}},
+		"support_vectors": [[1, 2]], "dual_coef": [1], "intercept": 0}}`
+	svc := NewSVC()
+	if err := svc.Load(strings.NewReader(model)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// (<x, y> + 1)^2 = (11 + 1)^2 = 144
+	if got := svc.Kernel.Calculate([]float64{1, 2}, []float64{3, 4}); fmt.Sprintf("%.3f", got) != "144.000" {
+		t.Errorf("Kernel.Calculate() = %v, want 144", got)
+	}
+}
+
+func TestSVC_SaveNotFitted(t *testing.T) {
+	if err := NewSVC().Save(&bytes.Buffer{}); err == nil {
+		t.Errorf("Save() error = nil, want error")
+	}
+}
+
+func TestSVC_SaveLoadProbability(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	svc := NewSVC()
+	svc.Probability = true
+	if err := svc.Fit(x, y); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := svc.Save(buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := NewSVC()
+	if err := loaded.Load(buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := loaded.PredictProba(x), svc.PredictProba(x); !reflect.DeepEqual(got, want) {
+		t.Errorf("PredictProba() of loaded model = %v, want %v", got, want)
+	}
+}
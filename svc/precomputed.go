@@ -0,0 +1,96 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Pairwise сообщает, что классификатор работает с предвычисленным ядром (Kernel = 'precomputed').
+// В этом режиме Fit принимает матрицу Грама n x n обучающей выборки,
+// а Predict - матрицу m x n значений ядра между новыми объектами и объектами обучающей выборки.
+func (svc *SVC) Pairwise() bool {
+	return svc.kernelName == Precomputed
+}
+
+// SupportIndices возвращает индексы опорных векторов в обучающей выборке.
+// Для предвычисленного ядра это номера столбцов матрицы ядра, которые использует Predict.
+func (svc *SVC) SupportIndices() []int {
+	return svc.supportVectorsIdx
+}
+
+// ValidatePredictInput проверяет входные данные для Predict, DecisionFunction и PredictProba.
+// Для предвычисленного ядра каждая строка x должна содержать значения ядра для всех объектов обучающей выборки,
+// число которых запоминается при обучении. Для остальных ядер проверок нет.
+// Predict, DecisionFunction и PredictProba при некорректных данных возвращают nil и пишут ошибку в лог.
+func (svc *SVC) ValidatePredictInput(x [][]float64) error {
+	if !svc.Pairwise() {
+		return nil
+	}
+	if svc.nFeatures == 0 {
+		return fmt.Errorf("model is not fitted")
+	}
+	for i := range x {
+		if len(x[i]) != svc.nFeatures {
+			return fmt.Errorf("precomputed kernel row %d must have %d columns (number of training samples), actual: %d",
+				i, svc.nFeatures, len(x[i]))
+		}
+	}
+	return nil
+}
+
+// validateGram проверяет, что матрица ядра обучающей выборки квадратная и симметричная.
+func validateGram(gram [][]float64) error {
+	for i := range gram {
+		if len(gram[i]) != len(gram) {
+			return fmt.Errorf("precomputed kernel matrix must be square: %d rows, row %d has %d columns",
+				len(gram), i, len(gram[i]))
+		}
+	}
+	for i := range gram {
+		for j := 0; j < i; j++ {
+			scale := math.Max(1, math.Max(math.Abs(gram[i][j]), math.Abs(gram[j][i])))
+			if math.Abs(gram[i][j]-gram[j][i]) > 1e-8*scale {
+				return fmt.Errorf("precomputed kernel matrix must be symmetric: K[%d][%d] = %v, K[%d][%d] = %v",
+					i, j, gram[i][j], j, i, gram[j][i])
+			}
+		}
+	}
+	return nil
+}
+
+// sliceGram возвращает подматрицу матрицы ядра из строк rows и столбцов cols.
+func sliceGram(gram [][]float64, rows, cols []int) [][]float64 {
+	res := make([][]float64, len(rows))
+	for k, i := range rows {
+		res[k] = make([]float64, len(cols))
+		for l, j := range cols {
+			res[k][l] = gram[i][j]
+		}
+	}
+	return res
+}
+
+// fitSubset обучает классификатор на объектах выборки x с индексами idx.
+// y и weights - метки и веса уже отобранных объектов, cache - кэш ядра объектов x.
+// Для предвычисленного ядра из матрицы Грама вырезаются строки и столбцы подвыборки,
+// а индексы опорных векторов переводятся в индексы исходной выборки. Поэтому обученный
+// классификатор принимает строки ядра относительно всей исходной выборки.
+func (svc *SVC) fitSubset(ctx context.Context, x [][]float64, idx []int, y []int, weights []float64, cache *kernelRowCache) error {
+	if !svc.Pairwise() {
+		xSubset := make([][]float64, len(idx))
+		for k, i := range idx {
+			xSubset[k] = x[i]
+		}
+		return svc.fit(ctx, xSubset, y, weights, cache.subset(idx))
+	}
+
+	if err := svc.fit(ctx, sliceGram(x, idx, idx), y, weights, cache.subset(idx)); err != nil {
+		return err
+	}
+	for k, i := range svc.supportVectorsIdx {
+		svc.supportVectorsIdx[k] = idx[i]
+	}
+	svc.nFeatures = len(x)
+	return nil
+}
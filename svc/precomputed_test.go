@@ -0,0 +1,213 @@
+package svc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ziyadovea/svm/pkg/classification_metrics"
+	"github.com/ziyadovea/svm/pkg/cross_validation"
+)
+
+// gramMatrix возвращает матрицу значений ядра между объектами a и b.
+func gramMatrix(kernel Kernel, a, b [][]float64) [][]float64 {
+	res := make([][]float64, len(a))
+	for i := range a {
+		res[i] = make([]float64, len(b))
+		for j := range b {
+			res[i][j] = kernel.Calculate(a[i], b[j])
+		}
+	}
+	return res
+}
+
+func TestSVC_FitPrecomputed(t *testing.T) {
+	kernel := &RbfKernel{Gamma: 0.1}
+
+	want := NewSVC()
+	want.SetKernel(kernel)
+	if err := want.Fit(separableX, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	svc := NewSVC()
+	if err := svc.SetKernelByName("precomputed"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	if !svc.Pairwise() {
+		t.Fatalf("Pairwise() = false, want true")
+	}
+	if err := svc.Fit(gramMatrix(kernel, separableX, separableX), separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	// Модель на матрице Грама совпадает с моделью на том же ядре.
+	xTest := [][]float64{{0, 0}, {5, 5}, {10, 10}}
+	got := svc.DecisionFunction(gramMatrix(kernel, xTest, separableX))
+	for i, row := range want.DecisionFunction(xTest) {
+		if math.Abs(got[i][0]-row[0]) > 1e-9 {
+			t.Errorf("DecisionFunction()[%d] = %v, want %v", i, got[i][0], row[0])
+		}
+	}
+	if svc.SupportVectors() != nil {
+		t.Errorf("SupportVectors() = %v, want nil", svc.SupportVectors())
+	}
+}
+
+func TestMultiSVC_FitPrecomputed(t *testing.T) {
+	x, y := loadIris(t)
+	kernel := &RbfKernel{Gamma: 0.5}
+	gram := gramMatrix(kernel, x, x)
+
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			want := NewMultiSVC()
+			want.Strategy = strategy
+			want.Probability = true
+			want.SetKernel(kernel)
+			if err := want.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			m := NewMultiSVC()
+			m.Strategy = strategy
+			m.Probability = true
+			if err := m.SetKernelByName("precomputed"); err != nil {
+				t.Fatalf("SetKernelByName() error = %v", err)
+			}
+			if err := m.Fit(gram, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			if got := m.Predict(gram); !reflect.DeepEqual(got, want.Predict(x)) {
+				t.Errorf("Predict() = %v, want %v", got, want.Predict(x))
+			}
+			gotProba, wantProba := m.PredictProba(gram), want.PredictProba(x)
+			for i := range wantProba {
+				for k := range wantProba[i] {
+					if math.Abs(gotProba[i][k]-wantProba[i][k]) > 1e-6 {
+						t.Fatalf("PredictProba()[%d] = %v, want %v", i, gotProba[i], wantProba[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSVC_FitPrecomputedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		gram [][]float64
+		y    []int
+	}{
+		{
+			name: "Test non-square matrix",
+			gram: [][]float64{{1, 0, 0}, {0, 1, 0}},
+			y:    []int{-1, 1},
+		},
+		{
+			name: "Test asymmetric matrix",
+			gram: [][]float64{{1, 0.5}, {0.4, 1}},
+			y:    []int{-1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			if err := svc.SetKernelByName("precomputed"); err != nil {
+				t.Fatalf("SetKernelByName() error = %v", err)
+			}
+			if err := svc.Fit(tt.gram, tt.y); err == nil {
+				t.Errorf("Fit() expected error")
+			}
+		})
+	}
+}
+
+func TestSVC_PredictPrecomputedShape(t *testing.T) {
+	kernel := &RbfKernel{Gamma: 0.1}
+	gram := gramMatrix(kernel, separableX, separableX)
+	// Строка ядра короче числа объектов обучающей выборки.
+	short := [][]float64{gram[0][:len(gram[0])-1]}
+
+	svc := NewSVC()
+	svc.Probability = true
+	if err := svc.SetKernelByName("precomputed"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	if err := svc.ValidatePredictInput(gram); err == nil {
+		t.Errorf("ValidatePredictInput() of not fitted model error = nil, want error")
+	}
+	if err := svc.Fit(gram, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	if err := svc.ValidatePredictInput(gram); err != nil {
+		t.Errorf("ValidatePredictInput() error = %v", err)
+	}
+	if err := svc.ValidatePredictInput(short); err == nil {
+		t.Errorf("ValidatePredictInput() error = nil, want error")
+	}
+	if got := svc.Predict(short); got != nil {
+		t.Errorf("Predict() = %v, want nil", got)
+	}
+	if got := svc.DecisionFunction(short); got != nil {
+		t.Errorf("DecisionFunction() = %v, want nil", got)
+	}
+	if got := svc.PredictProba(short); got != nil {
+		t.Errorf("PredictProba() = %v, want nil", got)
+	}
+
+	x, y := loadIris(t)
+	irisGram := gramMatrix(kernel, x, x)
+	for _, strategy := range []Strategy{OvR, OvO} {
+		m := NewMultiSVC()
+		m.Strategy = strategy
+		if err := m.SetKernelByName("precomputed"); err != nil {
+			t.Fatalf("SetKernelByName() error = %v", err)
+		}
+		if err := m.Fit(irisGram, y); err != nil {
+			t.Fatalf("Fit() error = %v", err)
+		}
+		if got := m.Predict(short); got != nil {
+			t.Errorf("%s: Predict() = %v, want nil", strategy, got)
+		}
+		if got := m.DecisionFunction(short); got != nil {
+			t.Errorf("%s: DecisionFunction() = %v, want nil", strategy, got)
+		}
+	}
+}
+
+func TestKFoldCVScorePrecomputed(t *testing.T) {
+	x, y := loadIris(t)
+	kernel := &RbfKernel{Gamma: 0.5}
+
+	want := NewMultiSVC()
+	want.SetKernel(kernel)
+	wantScores, err := cross_validation.KFoldCVScore(want, x, y, 5, classification_metrics.Accuracy)
+	if err != nil {
+		t.Fatalf("KFoldCVScore() error = %v", err)
+	}
+
+	m := NewMultiSVC()
+	if err := m.SetKernelByName("precomputed"); err != nil {
+		t.Fatalf("SetKernelByName() error = %v", err)
+	}
+	gotScores, err := cross_validation.KFoldCVScore(m, gramMatrix(kernel, x, x), y, 5, classification_metrics.Accuracy)
+	if err != nil {
+		t.Fatalf("KFoldCVScore() error = %v", err)
+	}
+
+	// Порядок фолдов в результате не определен, поэтому сравниваем суммы.
+	sum := func(v []float64) float64 {
+		res := 0.0
+		for _, s := range v {
+			res += s
+		}
+		return res
+	}
+	got, wantSum := sum(gotScores[classification_metrics.Accuracy]), sum(wantScores[classification_metrics.Accuracy])
+	if math.Abs(got-wantSum) > 1e-9 {
+		t.Errorf("KFoldCVScore() = %v, want %v", gotScores, wantScores)
+	}
+}
@@ -0,0 +1,212 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Количество фолдов внутренней кросс-валидации для обучения сигмоиды Платта.
+const plattCVFolds = 5
+
+// plattSigmoid описывает сигмоиду Платта P(y = +1 | f) = 1 / (1 + exp(A * f + B)),
+// которая переводит значение решающей функции f в вероятность положительного класса.
+type plattSigmoid struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// predict возвращает вероятность положительного класса для значения решающей функции f.
+func (s plattSigmoid) predict(f float64) float64 {
+	fApB := f*s.A + s.B
+	// Вычисляем так, чтобы избежать переполнения экспоненты.
+	if fApB >= 0 {
+		return math.Exp(-fApB) / (1 + math.Exp(-fApB))
+	}
+	return 1 / (1 + math.Exp(fApB))
+}
+
+// fitPlatt обучает сигмоиду Платта на значениях решающей функции,
+// полученных на внутренней кросс-валидации.
+// x - матрица признаков.
+// y - слайс меток, y = +1 или -1.
+// weights - итоговые веса объектов, cache - кэш ядра объектов x.
+func (svc *SVC) fitPlatt(ctx context.Context, x [][]float64, y []int, weights []float64, cache *kernelRowCache) (plattSigmoid, error) {
+	decValues, err := svc.crossValDecision(ctx, x, y, weights, cache)
+	if err != nil {
+		return plattSigmoid{}, err
+	}
+	return trainPlattSigmoid(decValues, y), nil
+}
+
+// crossValDecision возвращает значения решающей функции для каждого объекта обучающей выборки,
+// вычисленные классификатором, который обучался без этого объекта.
+// Объект с индексом i попадает в фолд i % plattCVFolds.
+// Фолды разделяют кэш ядра cache объектов x. Ошибка в одном фолде отменяет обучение остальных.
+func (svc *SVC) crossValDecision(ctx context.Context, x [][]float64, y []int, weights []float64, cache *kernelRowCache) ([]float64, error) {
+	decValues := make([]float64, len(y))
+	nFolds := plattCVFolds
+	if len(y) < nFolds {
+		nFolds = len(y)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for fold := 0; fold < nFolds; fold++ {
+		fold := fold
+		eg.Go(func() error {
+			trainIdx := make([]int, 0, len(y))
+			yTrain := make([]int, 0, len(y))
+			wTrain := make([]float64, 0, len(y))
+			testIdx := make([]int, 0, len(y)/nFolds+1)
+			nPositive := 0
+			for i := range y {
+				if i%nFolds == fold {
+					testIdx = append(testIdx, i)
+					continue
+				}
+				trainIdx = append(trainIdx, i)
+				yTrain = append(yTrain, y[i])
+				wTrain = append(wTrain, weights[i])
+				if y[i] > 0 {
+					nPositive++
+				}
+			}
+
+			// Если в обучающей части фолда представлен только один класс,
+			// то решающая функция равна +1 или -1 для всех объектов фолда.
+			if nPositive == 0 || nPositive == len(yTrain) {
+				value := -1.0
+				if nPositive > 0 {
+					value = 1.0
+				}
+				for _, i := range testIdx {
+					decValues[i] = value
+				}
+				return nil
+			}
+
+			svc := svc.fittedParamsCopy()
+			svc.Probability = false
+			// Ход обучения внутренних классификаторов не сообщается.
+			svc.Callback = nil
+			if err := svc.fitSubset(ctx, x, trainIdx, yTrain, wTrain, cache); err != nil {
+				return fmt.Errorf("error in fitting a classifier for probability estimates: %w", err)
+			}
+			for _, i := range testIdx {
+				decValues[i] = svc.f(x[i])
+			}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return decValues, nil
+}
+
+// trainPlattSigmoid подбирает параметры сигмоиды Платта методом Ньютона с линейным поиском
+// (Lin, Lin, Weng, "A note on Platt's probabilistic outputs for support vector machines", 2007).
+// decValues - значения решающей функции, y - слайс меток, y = +1 или -1.
+func trainPlattSigmoid(decValues []float64, y []int) plattSigmoid {
+	const (
+		maxIters = 100
+		minStep  = 1e-10
+		sigma    = 1e-12
+		eps      = 1e-5
+	)
+
+	prior1, prior0 := 0.0, 0.0
+	for _, label := range y {
+		if label > 0 {
+			prior1++
+		} else {
+			prior0++
+		}
+	}
+
+	// Целевые значения вероятностей с поправкой Байеса для избежания переобучения.
+	hiTarget := (prior1 + 1) / (prior1 + 2)
+	loTarget := 1 / (prior0 + 2)
+	t := make([]float64, len(y))
+	for i, label := range y {
+		if label > 0 {
+			t[i] = hiTarget
+		} else {
+			t[i] = loTarget
+		}
+	}
+
+	// Значение отрицательного логарифма правдоподобия.
+	objective := func(a, b float64) float64 {
+		res := 0.0
+		for i, f := range decValues {
+			fApB := f*a + b
+			if fApB >= 0 {
+				res += t[i]*fApB + math.Log(1+math.Exp(-fApB))
+			} else {
+				res += (t[i]-1)*fApB + math.Log(1+math.Exp(fApB))
+			}
+		}
+		return res
+	}
+
+	a := 0.0
+	b := math.Log((prior0 + 1) / (prior1 + 1))
+	fval := objective(a, b)
+
+	for iter := 0; iter < maxIters; iter++ {
+		// Градиент и гессиан (с регуляризацией sigma для положительной определенности).
+		h11, h22, h21 := sigma, sigma, 0.0
+		g1, g2 := 0.0, 0.0
+		for i, f := range decValues {
+			fApB := f*a + b
+			var p, q float64
+			if fApB >= 0 {
+				p = math.Exp(-fApB) / (1 + math.Exp(-fApB))
+				q = 1 / (1 + math.Exp(-fApB))
+			} else {
+				p = 1 / (1 + math.Exp(fApB))
+				q = math.Exp(fApB) / (1 + math.Exp(fApB))
+			}
+			d2 := p * q
+			h11 += f * f * d2
+			h22 += d2
+			h21 += f * d2
+			d1 := t[i] - p
+			g1 += f * d1
+			g2 += d1
+		}
+
+		// Критерий остановки.
+		if math.Abs(g1) < eps && math.Abs(g2) < eps {
+			break
+		}
+
+		// Направление Ньютона.
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		// Линейный поиск.
+		step := 1.0
+		for step >= minStep {
+			newA := a + step*dA
+			newB := b + step*dB
+			newF := objective(newA, newB)
+			if newF < fval+0.0001*step*gd {
+				a, b, fval = newA, newB, newF
+				break
+			}
+			step /= 2
+		}
+		if step < minStep {
+			break
+		}
+	}
+
+	return plattSigmoid{A: a, B: b}
+}
@@ -0,0 +1,740 @@
+package svc
+
+import (
+	"context"
+	"math"
+)
+
+// SolverName тип для имени метода решения задачи QP.
+type SolverName string
+
+// Определяем в константах существующие методы решения задачи QP.
+const (
+	// SMO - полноценный метод SMO (Platt) с выбором рабочего набора
+	// по информации второго порядка (WSS3, Fan, Chen, Lin, 2005).
+	SMO SolverName = "smo"
+	// SimplifiedSMO - упрощенный метод SMO, в котором второй индекс выбирается случайно.
+	SimplifiedSMO SolverName = "simplified_smo"
+)
+
+// Максимальное количество итераций SMO по умолчанию для задач до 100000 переменных.
+// Для больших задач, как и в LIBSVM, ограничение равно 100 итерациям на переменную.
+const defaultSMOMaxIters = 10000000
+
+// Максимальное количество проходов упрощенного метода SMO по умолчанию.
+const defaultSimplifiedSMOMaxIters = 10000
+
+// Максимальное число итераций между попытками сжатия активного множества.
+const shrinkingInterval = 1000
+
+// tau - малая положительная константа, которой заменяется неположительное значение
+// знаменателя при выборе рабочего набора и обновлении параметров альфа.
+const tau = 1e-12
+
+// qMatrix предоставляет солверу доступ к матрице Q задачи QP,
+// где Q[i][j] = y[i] * y[j] * K(x[i], x[j]).
+type qMatrix interface {
+	// getQ возвращает i-ую строку матрицы Q.
+	getQ(i int) []float64
+	// getQD возвращает диагональ матрицы Q.
+	getQD() []float64
+}
+
+// smoSolver решает задачу QP общего вида
+//
+//	min 0.5 * a^T * Q * a + p^T * a,
+//	y^T * a = const, 0 <= a[i] <= c[i],
+//
+// методом SMO с выбором рабочего набора по информации второго порядка.
+// Градиент целевой функции поддерживается инкрементально,
+// критерий остановки - нарушение условий ККТ не больше eps.
+type smoSolver struct {
+	// Число переменных задачи.
+	l int
+
+	// Матрица Q и ее диагональ.
+	q  qMatrix
+	qd []float64
+
+	// Линейный член целевой функции.
+	p []float64
+
+	// Знаки переменных (+1 или -1).
+	y []float64
+
+	// Верхние границы переменных.
+	c []float64
+
+	// Текущие значения переменных и градиент целевой функции.
+	alpha []float64
+	grad  []float64
+
+	// Точность по условиям ККТ.
+	eps float64
+
+	// Максимальное количество итераций.
+	maxIters int
+
+	// Вариант солвера для nu-SVC: рабочий набор выбирается среди переменных одного знака,
+	// так как для каждого класса выполняется отдельное линейное ограничение.
+	nu bool
+
+	// Использовать ли эвристику сжатия (shrinking): переменные на границах, которые, скорее всего,
+	// там и останутся, временно исключаются из выбора рабочего набора и обновления градиента.
+	shrinking bool
+
+	// Индексы активных переменных и признак активности каждой переменной.
+	active   []int
+	isActive []bool
+
+	// Вклад переменных на верхней границе в градиент: gradBar[k] = sum(c[j] * Q[j][k]) по j на верхней границе.
+	// Нужен, чтобы восстановить градиент исключенных переменных без полного пересчета.
+	gradBar []float64
+
+	// Были ли уже восстановлены все переменные при приближении к решению.
+	unshrink bool
+
+	// Контекст обучения: если он отменен, то солвер прерывает работу.
+	ctx context.Context
+
+	// Функция, которая вызывается после каждой итерации; nil означает, что она не задана.
+	callback Callback
+
+	// Нарушение условий ККТ при последнем выборе рабочего набора.
+	violation float64
+}
+
+// smoResult описывает решение задачи QP.
+type smoResult struct {
+	// Значения переменных.
+	alpha []float64
+	// Порог решающей функции: f(x) = sum(alpha[i] * y[i] * K(x[i], x)) - rho.
+	rho float64
+	// Значение целевой функции.
+	obj float64
+	// Количество выполненных итераций.
+	iters int
+	// Выполнены ли условия ККТ. Равно false, если достигнуто максимальное количество итераций.
+	converged bool
+	// Для nu-SVC: параметр r, на который нужно разделить решение, чтобы получить решение C-SVC.
+	r float64
+}
+
+// newSMOSolver возвращает солвер для задачи QP.
+// alpha - начальное допустимое решение, оно будет изменено солвером.
+// Неположительное значение maxIters означает ограничение по умолчанию: max(10000000, 100 * l).
+func newSMOSolver(q qMatrix, p, y, c, alpha []float64, eps float64, maxIters int) *smoSolver {
+	if maxIters <= 0 {
+		maxIters = defaultSMOMaxIters
+		if l := len(p); l > maxIters/100 {
+			maxIters = 100 * l
+		}
+	}
+	return &smoSolver{
+		l:        len(p),
+		q:        q,
+		qd:       q.getQD(),
+		p:        p,
+		y:        y,
+		c:        c,
+		alpha:    alpha,
+		eps:      eps,
+		maxIters: maxIters,
+		ctx:      context.Background(),
+	}
+}
+
+// newNuSMOSolver возвращает солвер для задачи QP nu-SVC, в которой помимо y^T * a = const
+// выполняется ограничение e^T * a = const.
+// alpha - начальное допустимое решение, оно будет изменено солвером.
+func newNuSMOSolver(q qMatrix, p, y, c, alpha []float64, eps float64, maxIters int) *smoSolver {
+	s := newSMOSolver(q, p, y, c, alpha, eps, maxIters)
+	s.nu = true
+	return s
+}
+
+// solve решает задачу QP.
+// Возвращает ошибку контекста, если контекст отменен до завершения решения.
+func (s *smoSolver) solve() (smoResult, error) {
+	s.initGradient()
+
+	selectWorkingSet := s.selectWorkingSet
+	if s.nu {
+		selectWorkingSet = s.selectWorkingSetNu
+	}
+
+	iter := 0
+	converged := false
+	counter := shrinkingInterval
+	if s.l < counter {
+		counter = s.l
+	}
+	for iter < s.maxIters {
+		if err := s.ctx.Err(); err != nil {
+			return smoResult{}, err
+		}
+
+		if s.shrinking {
+			counter--
+			if counter <= 0 {
+				counter = shrinkingInterval
+				if s.l < counter {
+					counter = s.l
+				}
+				s.doShrinking()
+			}
+		}
+
+		i, j, ok := selectWorkingSet()
+		if !ok {
+			// Условия ККТ выполнены на активном множестве.
+			// Восстановим исключенные переменные и проверим условия ККТ на всех переменных.
+			if len(s.active) == s.l {
+				converged = true
+				break
+			}
+			s.reconstructGradient()
+			s.activateAll()
+			if i, j, ok = selectWorkingSet(); !ok {
+				converged = true
+				break
+			}
+			counter = 1
+		}
+		iter++
+		s.update(i, j)
+
+		if s.callback != nil {
+			s.callback(s.progress(iter))
+		}
+	}
+
+	// Если достигнуто максимальное количество итераций, то часть переменных может быть исключена.
+	if len(s.active) < s.l {
+		s.reconstructGradient()
+		s.activateAll()
+	}
+
+	res := smoResult{
+		alpha:     s.alpha,
+		obj:       s.objective(),
+		iters:     iter,
+		converged: converged,
+	}
+	if s.nu {
+		res.rho, res.r = s.calculateRhoNu()
+	} else {
+		res.rho = s.calculateRho()
+	}
+	return res, nil
+}
+
+// progress возвращает состояние солвера после итерации iter.
+func (s *smoSolver) progress(iter int) Progress {
+	nSupport := 0
+	for i := 0; i < s.l; i++ {
+		if s.alpha[i] > 0 {
+			nSupport++
+		}
+	}
+	return Progress{
+		Iter:         iter,
+		KKTViolation: math.Max(0, s.violation),
+		Objective:    s.objective(),
+		NSupport:     nSupport,
+	}
+}
+
+// initGradient вычисляет градиент целевой функции в начальной точке: grad = Q * alpha + p,
+// и вклад переменных на верхней границе gradBar. Все переменные становятся активными.
+func (s *smoSolver) initGradient() {
+	s.grad = make([]float64, s.l)
+	s.gradBar = make([]float64, s.l)
+	copy(s.grad, s.p)
+	for i := 0; i < s.l; i++ {
+		if s.alpha[i] == 0 {
+			continue
+		}
+		qi := s.q.getQ(i)
+		for k := 0; k < s.l; k++ {
+			s.grad[k] += s.alpha[i] * qi[k]
+		}
+		if s.isUpperBound(i) {
+			for k := 0; k < s.l; k++ {
+				s.gradBar[k] += s.c[i] * qi[k]
+			}
+		}
+	}
+
+	s.isActive = make([]bool, s.l)
+	s.activateAll()
+}
+
+// activateAll делает активными все переменные.
+func (s *smoSolver) activateAll() {
+	s.active = make([]int, s.l)
+	for i := range s.active {
+		s.active[i] = i
+		s.isActive[i] = true
+	}
+}
+
+// reconstructGradient восстанавливает градиент исключенных переменных:
+// grad[k] = gradBar[k] + p[k] + sum(alpha[j] * Q[j][k]) по свободным переменным j.
+// Исключаются только переменные на границах, поэтому все свободные переменные активны.
+func (s *smoSolver) reconstructGradient() {
+	if len(s.active) == s.l {
+		return
+	}
+	for k := 0; k < s.l; k++ {
+		if !s.isActive[k] {
+			s.grad[k] = s.gradBar[k] + s.p[k]
+		}
+	}
+	for _, j := range s.active {
+		if s.isUpperBound(j) || s.isLowerBound(j) {
+			continue
+		}
+		qj := s.q.getQ(j)
+		for k := 0; k < s.l; k++ {
+			if !s.isActive[k] {
+				s.grad[k] += s.alpha[j] * qj[k]
+			}
+		}
+	}
+}
+
+// doShrinking исключает из активного множества переменные на границах, которые не могут войти
+// в рабочий набор при текущих максимальных нарушениях условий ККТ.
+// Когда решение близко к оптимальному, все переменные один раз восстанавливаются,
+// чтобы исключить ошибочно исключенные переменные.
+func (s *smoSolver) doShrinking() {
+	if s.nu {
+		s.doShrinkingNu()
+		return
+	}
+
+	// gMax1 = max(-y[i] * grad[i]) по I_up, gMax2 = max(y[i] * grad[i]) по I_low.
+	gMax1, gMax2 := math.Inf(-1), math.Inf(-1)
+	for _, i := range s.active {
+		if s.inUpSet(i) {
+			gMax1 = math.Max(gMax1, -s.y[i]*s.grad[i])
+		}
+		if s.inLowSet(i) {
+			gMax2 = math.Max(gMax2, s.y[i]*s.grad[i])
+		}
+	}
+
+	if !s.unshrink && gMax1+gMax2 <= s.eps*10 {
+		s.unshrink = true
+		s.reconstructGradient()
+		s.activateAll()
+	}
+
+	s.shrinkActive(func(i int) bool {
+		switch {
+		case s.isUpperBound(i):
+			if s.y[i] > 0 {
+				return -s.grad[i] > gMax1
+			}
+			return -s.grad[i] > gMax2
+		case s.isLowerBound(i):
+			if s.y[i] > 0 {
+				return s.grad[i] > gMax2
+			}
+			return s.grad[i] > gMax1
+		default:
+			return false
+		}
+	})
+}
+
+// doShrinkingNu выполняет сжатие активного множества для задачи nu-SVC,
+// в которой условия ККТ проверяются отдельно для переменных каждого знака.
+func (s *smoSolver) doShrinkingNu() {
+	// gMax1 и gMax2 - максимальные нарушения для переменных со знаком +1 (I_up и I_low),
+	// gMax3 и gMax4 - для переменных со знаком -1 (I_low и I_up).
+	gMax1, gMax2 := math.Inf(-1), math.Inf(-1)
+	gMax3, gMax4 := math.Inf(-1), math.Inf(-1)
+	for _, i := range s.active {
+		if !s.isUpperBound(i) {
+			if s.y[i] > 0 {
+				gMax1 = math.Max(gMax1, -s.grad[i])
+			} else {
+				gMax4 = math.Max(gMax4, -s.grad[i])
+			}
+		}
+		if !s.isLowerBound(i) {
+			if s.y[i] > 0 {
+				gMax2 = math.Max(gMax2, s.grad[i])
+			} else {
+				gMax3 = math.Max(gMax3, s.grad[i])
+			}
+		}
+	}
+
+	if !s.unshrink && math.Max(gMax1+gMax2, gMax3+gMax4) <= s.eps*10 {
+		s.unshrink = true
+		s.reconstructGradient()
+		s.activateAll()
+	}
+
+	s.shrinkActive(func(i int) bool {
+		switch {
+		case s.isUpperBound(i):
+			if s.y[i] > 0 {
+				return -s.grad[i] > gMax1
+			}
+			return -s.grad[i] > gMax4
+		case s.isLowerBound(i):
+			if s.y[i] > 0 {
+				return s.grad[i] > gMax2
+			}
+			return s.grad[i] > gMax3
+		default:
+			return false
+		}
+	})
+}
+
+// shrinkActive исключает из активного множества переменные, для которых shrink возвращает true.
+func (s *smoSolver) shrinkActive(shrink func(i int) bool) {
+	active := s.active[:0]
+	for _, i := range s.active {
+		if shrink(i) {
+			s.isActive[i] = false
+			continue
+		}
+		active = append(active, i)
+	}
+	s.active = active
+}
+
+// isUpperBound проверяет, что переменная находится на верхней границе.
+func (s *smoSolver) isUpperBound(i int) bool {
+	return s.alpha[i] >= s.c[i]
+}
+
+// isLowerBound проверяет, что переменная находится на нижней границе.
+func (s *smoSolver) isLowerBound(i int) bool {
+	return s.alpha[i] <= 0
+}
+
+// inUpSet проверяет, что переменная может быть увеличена вдоль направления y (множество I_up).
+func (s *smoSolver) inUpSet(t int) bool {
+	return (s.y[t] > 0 && !s.isUpperBound(t)) || (s.y[t] < 0 && !s.isLowerBound(t))
+}
+
+// inLowSet проверяет, что переменная может быть уменьшена вдоль направления y (множество I_low).
+func (s *smoSolver) inLowSet(t int) bool {
+	return (s.y[t] > 0 && !s.isLowerBound(t)) || (s.y[t] < 0 && !s.isUpperBound(t))
+}
+
+// selectWorkingSet выбирает рабочий набор (i, j).
+// Индекс i - максимально нарушающая условия ККТ переменная,
+// индекс j выбирается по информации второго порядка (WSS3).
+// Возвращает false, если условия ККТ выполнены с точностью eps.
+func (s *smoSolver) selectWorkingSet() (int, int, bool) {
+	gMax := math.Inf(-1)
+	gMax2 := math.Inf(-1)
+	i := -1
+	for _, t := range s.active {
+		if s.inUpSet(t) && -s.y[t]*s.grad[t] >= gMax {
+			gMax = -s.y[t] * s.grad[t]
+			i = t
+		}
+	}
+	if i == -1 {
+		s.violation = 0
+		return 0, 0, false
+	}
+
+	qi := s.q.getQ(i)
+	j := -1
+	objDiffMin := math.Inf(1)
+	for _, t := range s.active {
+		if !s.inLowSet(t) {
+			continue
+		}
+		gradDiff := gMax + s.y[t]*s.grad[t]
+		if s.y[t]*s.grad[t] >= gMax2 {
+			gMax2 = s.y[t] * s.grad[t]
+		}
+		if gradDiff <= 0 {
+			continue
+		}
+		quadCoef := s.qd[i] + s.qd[t] - 2*s.y[i]*s.y[t]*qi[t]
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		if objDiff := -(gradDiff * gradDiff) / quadCoef; objDiff <= objDiffMin {
+			objDiffMin = objDiff
+			j = t
+		}
+	}
+
+	s.violation = gMax + gMax2
+	if gMax+gMax2 < s.eps || j == -1 {
+		return 0, 0, false
+	}
+	return i, j, true
+}
+
+// selectWorkingSetNu выбирает рабочий набор (i, j) для задачи nu-SVC.
+// В отличие от selectWorkingSet обе переменные имеют один знак y,
+// иначе обновление нарушило бы ограничение e^T * a = const.
+// Возвращает false, если условия ККТ выполнены с точностью eps.
+func (s *smoSolver) selectWorkingSetNu() (int, int, bool) {
+	gMaxP, gMaxP2 := math.Inf(-1), math.Inf(-1)
+	gMaxN, gMaxN2 := math.Inf(-1), math.Inf(-1)
+	ip, in := -1, -1
+	for _, t := range s.active {
+		if s.y[t] > 0 {
+			if !s.isUpperBound(t) && -s.grad[t] >= gMaxP {
+				gMaxP = -s.grad[t]
+				ip = t
+			}
+		} else {
+			if !s.isLowerBound(t) && s.grad[t] >= gMaxN {
+				gMaxN = s.grad[t]
+				in = t
+			}
+		}
+	}
+
+	var qip, qin []float64
+	if ip != -1 {
+		qip = s.q.getQ(ip)
+	}
+	if in != -1 {
+		qin = s.q.getQ(in)
+	}
+
+	j := -1
+	objDiffMin := math.Inf(1)
+	for _, t := range s.active {
+		var gradDiff, quadCoef float64
+		if s.y[t] > 0 {
+			if s.isLowerBound(t) {
+				continue
+			}
+			gradDiff = gMaxP + s.grad[t]
+			if s.grad[t] >= gMaxP2 {
+				gMaxP2 = s.grad[t]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.qd[ip] + s.qd[t] - 2*qip[t]
+		} else {
+			if s.isUpperBound(t) {
+				continue
+			}
+			gradDiff = gMaxN - s.grad[t]
+			if -s.grad[t] >= gMaxN2 {
+				gMaxN2 = -s.grad[t]
+			}
+			if gradDiff <= 0 {
+				continue
+			}
+			quadCoef = s.qd[in] + s.qd[t] - 2*qin[t]
+		}
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		if objDiff := -(gradDiff * gradDiff) / quadCoef; objDiff <= objDiffMin {
+			objDiffMin = objDiff
+			j = t
+		}
+	}
+
+	s.violation = math.Max(gMaxP+gMaxP2, gMaxN+gMaxN2)
+	if math.Max(gMaxP+gMaxP2, gMaxN+gMaxN2) < s.eps || j == -1 {
+		return 0, 0, false
+	}
+	if s.y[j] > 0 {
+		return ip, j, true
+	}
+	return in, j, true
+}
+
+// update решает подзадачу QP для пары переменных (i, j) и обновляет градиент.
+func (s *smoSolver) update(i, j int) {
+	qi := s.q.getQ(i)
+	qj := s.q.getQ(j)
+	ci, cj := s.c[i], s.c[j]
+	oldAi, oldAj := s.alpha[i], s.alpha[j]
+	upperI, upperJ := s.isUpperBound(i), s.isUpperBound(j)
+
+	if s.y[i] != s.y[j] {
+		quadCoef := s.qd[i] + s.qd[j] + 2*qi[j]
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		delta := (-s.grad[i] - s.grad[j]) / quadCoef
+		diff := s.alpha[i] - s.alpha[j]
+		s.alpha[i] += delta
+		s.alpha[j] += delta
+
+		if diff > 0 {
+			if s.alpha[j] < 0 {
+				s.alpha[j] = 0
+				s.alpha[i] = diff
+			}
+		} else {
+			if s.alpha[i] < 0 {
+				s.alpha[i] = 0
+				s.alpha[j] = -diff
+			}
+		}
+		if diff > ci-cj {
+			if s.alpha[i] > ci {
+				s.alpha[i] = ci
+				s.alpha[j] = ci - diff
+			}
+		} else {
+			if s.alpha[j] > cj {
+				s.alpha[j] = cj
+				s.alpha[i] = cj + diff
+			}
+		}
+	} else {
+		quadCoef := s.qd[i] + s.qd[j] - 2*qi[j]
+		if quadCoef <= 0 {
+			quadCoef = tau
+		}
+		delta := (s.grad[i] - s.grad[j]) / quadCoef
+		sum := s.alpha[i] + s.alpha[j]
+		s.alpha[i] -= delta
+		s.alpha[j] += delta
+
+		if sum > ci {
+			if s.alpha[i] > ci {
+				s.alpha[i] = ci
+				s.alpha[j] = sum - ci
+			}
+		} else {
+			if s.alpha[j] < 0 {
+				s.alpha[j] = 0
+				s.alpha[i] = sum
+			}
+		}
+		if sum > cj {
+			if s.alpha[j] > cj {
+				s.alpha[j] = cj
+				s.alpha[i] = sum - cj
+			}
+		} else {
+			if s.alpha[i] < 0 {
+				s.alpha[i] = 0
+				s.alpha[j] = sum
+			}
+		}
+	}
+
+	// Инкрементально обновляем градиент активных переменных.
+	deltaAi := s.alpha[i] - oldAi
+	deltaAj := s.alpha[j] - oldAj
+	for _, k := range s.active {
+		s.grad[k] += qi[k]*deltaAi + qj[k]*deltaAj
+	}
+
+	// Если переменная достигла верхней границы или ушла с нее, обновим gradBar.
+	s.updateGradBar(i, upperI, qi)
+	s.updateGradBar(j, upperJ, qj)
+}
+
+// updateGradBar обновляет gradBar после изменения переменной i.
+// wasUpper - находилась ли переменная на верхней границе до изменения, qi - i-ая строка матрицы Q.
+func (s *smoSolver) updateGradBar(i int, wasUpper bool, qi []float64) {
+	if wasUpper == s.isUpperBound(i) {
+		return
+	}
+	sign := 1.0
+	if wasUpper {
+		sign = -1
+	}
+	for k := 0; k < s.l; k++ {
+		s.gradBar[k] += sign * s.c[i] * qi[k]
+	}
+}
+
+// calculateRho вычисляет порог rho решающей функции.
+// Для свободных переменных rho = y[i] * grad[i], если таких нет - берется середина допустимого интервала.
+func (s *smoSolver) calculateRho() float64 {
+	nFree := 0
+	sumFree := 0.0
+	ub := math.Inf(1)
+	lb := math.Inf(-1)
+	for i := 0; i < s.l; i++ {
+		// Переменные с нулевой верхней границей зафиксированы и не влияют на порог.
+		if s.c[i] == 0 {
+			continue
+		}
+		yG := s.y[i] * s.grad[i]
+		switch {
+		case s.isUpperBound(i):
+			if s.y[i] < 0 {
+				ub = math.Min(ub, yG)
+			} else {
+				lb = math.Max(lb, yG)
+			}
+		case s.isLowerBound(i):
+			if s.y[i] > 0 {
+				ub = math.Min(ub, yG)
+			} else {
+				lb = math.Max(lb, yG)
+			}
+		default:
+			nFree++
+			sumFree += yG
+		}
+	}
+
+	if nFree > 0 {
+		return sumFree / float64(nFree)
+	}
+	return (ub + lb) / 2
+}
+
+// calculateRhoNu вычисляет порог rho и параметр r для задачи nu-SVC.
+// Пороги r1 и r2 вычисляются отдельно для переменных со знаком +1 и -1,
+// тогда rho = (r1 - r2) / 2 и r = (r1 + r2) / 2.
+func (s *smoSolver) calculateRhoNu() (float64, float64) {
+	var r [2]float64
+	for k, sign := range []float64{+1, -1} {
+		nFree := 0
+		sumFree := 0.0
+		ub := math.Inf(1)
+		lb := math.Inf(-1)
+		for i := 0; i < s.l; i++ {
+			if s.y[i] != sign || s.c[i] == 0 {
+				continue
+			}
+			switch {
+			case s.isUpperBound(i):
+				lb = math.Max(lb, s.grad[i])
+			case s.isLowerBound(i):
+				ub = math.Min(ub, s.grad[i])
+			default:
+				nFree++
+				sumFree += s.grad[i]
+			}
+		}
+		if nFree > 0 {
+			r[k] = sumFree / float64(nFree)
+		} else {
+			r[k] = (ub + lb) / 2
+		}
+	}
+	return (r[0] - r[1]) / 2, (r[0] + r[1]) / 2
+}
+
+// objective вычисляет значение целевой функции: 0.5 * a^T * Q * a + p^T * a = 0.5 * a^T * (grad + p).
+func (s *smoSolver) objective() float64 {
+	res := 0.0
+	for i := 0; i < s.l; i++ {
+		res += s.alpha[i] * (s.grad[i] + s.p[i])
+	}
+	return res / 2
+}
@@ -1,20 +1,37 @@
 package svc
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/jinzhu/copier"
 	"github.com/ziyadovea/svm"
 	"github.com/ziyadovea/svm/pkg/vector_operations"
 )
 
-// Проверим, что структура SVC удовлетворяет интерфейсу Classifier.
-var _ svm.Classifier = (*SVC)(nil)
+// Проверим, что структура SVC удовлетворяет интерфейсам Classifier, Scorer и ProbabilisticClassifier.
+var (
+	_ svm.Classifier              = (*SVC)(nil)
+	_ svm.Scorer                  = (*SVC)(nil)
+	_ svm.ProbabilisticClassifier = (*SVC)(nil)
+	_ svm.ContextClassifier       = (*SVC)(nil)
+)
+
+// Formulation тип для постановки задачи классификации методом опорных векторов.
+type Formulation string
+
+// Определяем в константах существующие постановки задачи.
+const (
+	// CSVC - классическая постановка с параметром регуляризации C.
+	CSVC Formulation = "c_svc"
+	// NuSVC - постановка с параметром nu (Schölkopf et al., 2000), который ограничивает сверху
+	// долю ошибок на отступе и снизу долю опорных векторов.
+	NuSVC Formulation = "nu_svc"
+)
 
 // SVC (англ. Support Vector Classifier) - структура для представления
 // классификатора методом опорных векторов.
@@ -24,114 +41,290 @@ type SVC struct {
 	kernelName KernelName
 
 	// Ядро.
+	// Ядро, заданное по имени, строится заново из полей Degree, Coef0, Gamma и KernelAlpha при каждом вызове Fit.
+	// Собственный объект ядра задается методом SetKernel.
 	Kernel Kernel
 
-	// Кэш ядра - матрица скалярных произведений.
-	kernelCache [][]float64
+	// Кэш строк матрицы ядра.
+	// Используется только во время обучения.
+	kernelCache *kernelRowCache
+
+	// Объем памяти под кэш строк матрицы ядра в мегабайтах.
+	// Если строки не помещаются в кэш, то давно не использованные строки вытесняются и при необходимости
+	// вычисляются заново.
+	CacheSizeMB float64
+
+	// Использовать ли эвристику сжатия (shrinking) метода SMO: переменные, которые находятся на границах
+	// и, скорее всего, там и останутся, временно исключаются из оптимизации.
+	Shrinking bool
+
+	// Постановка задачи: C-SVC или nu-SVC.
+	Formulation Formulation
 
-	// Параметр регуляризации.
+	// Параметр регуляризации для постановки C-SVC.
 	C float64
 
+	// Параметр nu из (0, 1] для постановки nu-SVC.
+	// Должен удовлетворять условию nu <= 2 * min(n+, n-) / n, где n+ и n- - размеры классов.
+	Nu float64
+
 	// Степень многочлена для полиномиального ядра.
 	Degree int
 
 	// Свободный член для полиномиального ядра.
 	Coef0 float64
 
-	// Параметр для ядра 'rbf'.
+	// Масштаб для ядер 'rbf', 'sigmoid', 'laplacian', 'chi2' и 'rational_quadratic'.
+	// Используется, если GammaMode равен GammaValue.
 	Gamma float64
 
+	// Способ выбора gamma: значение из поля Gamma или вычисление по обучающей выборке
+	// ('scale' или 'auto') при вызове Fit.
+	GammaMode GammaMode
+
+	// Параметр alpha для ядра 'rational_quadratic'.
+	KernelAlpha float64
+
 	// Точность.
 	Tol float64
 
 	// Максимальное количество итераций.
+	// Для метода SMO итерацией считается оптимизация одной пары параметров альфа,
+	// для упрощенного метода SMO - проход по всей обучающей выборке.
+	// Значение 0 (по умолчанию) означает max(10000000, 100 * nSamples) итераций метода SMO, как в LIBSVM,
+	// и 10000 проходов упрощенного метода SMO.
+	// Если решение не сошлось за MaxIters итераций, то в Logger выводится предупреждение.
 	MaxIters int
 
-	// Вектор с индексами опорных векторов.
+	// Метод решения задачи QP.
+	Solver SolverName
+
+	// Зерно генератора случайных чисел, которым упрощенный метод SMO выбирает второй индекс пары.
+	// Каждое обучение создает собственный генератор, поэтому при одинаковом зерне и одинаковых данных
+	// получается одна и та же модель, в том числе при параллельном обучении нескольких классификаторов.
+	Seed int64
+
+	// Оценивать ли вероятности классов.
+	// Вероятности вычисляются сигмоидой Платта, которая обучается на внутренней кросс-валидации,
+	// поэтому обучение становится заметно дольше.
+	Probability bool
+
+	// Веса классов: верхняя граница параметра альфа объекта класса c равна C * ClassWeight[c].
+	// Для классов, отсутствующих в карте, вес равен 1.
+	ClassWeight map[int]float64
+
+	// Вычислять ли веса классов автоматически, обратно пропорционально их размерам:
+	// w[c] = n / (k * n[c]). Если задано, ClassWeight игнорируется.
+	BalancedClassWeight bool
+
+	// Логгер для сообщений о ходе обучения. Если равен nil, то сообщения не выводятся.
+	Logger svm.Logger
+
+	// Функция, которую солвер вызывает после каждой итерации (для упрощенного метода SMO - после каждого прохода
+	// по выборке). Если равна nil, то не вызывается.
+	// Копии, созданные Clone, вызывают ее под общим мьютексом, поэтому при параллельном обучении копий
+	// (например, в кросс-валидации) вызовы не пересекаются.
+	Callback Callback
+
+	// Мьютекс для вызовов Callback, общий для классификатора и его копий.
+	// Создается при первом вызове Clone.
+	callbackMu *sync.Mutex
+
+	// Вектор с индексами опорных векторов в обучающей выборке.
 	supportVectorsIdx []int
 
 	// Матрица признаков обучающей выборки.
+	// Используется только во время обучения.
 	x [][]float64
 
 	// Метки классов обучающей выборки.
+	// Используется только во время обучения.
 	y []int
 
+	// Опорные вектора обученной модели.
+	supportVectors [][]float64
+
+	// Двойственные коэффициенты опорных векторов: alpha[i] * y[i].
+	dualCoef []float64
+
+	// Количество опорных векторов для каждого класса: [-1, +1].
+	nSupport []int
+
 	// Порог для SVM.
 	b float64
 
+	// Значение gamma, с которым обучена модель.
+	fittedGamma float64
+
+	// Сигмоида Платта для оценки вероятностей.
+	// Равна nil, если модель обучена без оценки вероятностей.
+	platt *plattSigmoid
+
 	// Число образцов обучающей выборки
 	nSamples int
-	// Число характеристик обучающей выборки
+	// Число характеристик обучающей выборки.
+	// Для предвычисленного ядра - число объектов обучающей выборки, то есть число столбцов строк ядра в Predict.
 	nFeatures int
 	// Число классов обучающей выборки
 	nClasses int
 
 	// Параметры для решения QP методом SMO.
-	alphas []float64 // Альфа-параметры опорных векторов.
+	// Используются только во время обучения.
+	alphas []float64  // Альфа-параметры опорных векторов.
+	boxC   []float64  // Верхние границы альфа-параметров с учетом весов классов и объектов.
+	rnd    *rand.Rand // Генератор случайных чисел упрощенного метода SMO.
 }
 
 // NewSVC возвращает экземпляр SVC с параметрами по умолчанию.
 func NewSVC() *SVC {
 	return &SVC{
-		kernelName:        "rbf",
-		Kernel:            &RbfKernel{Gamma: 1.0},
-		kernelCache:       nil,
-		C:                 1.0,
-		Degree:            3,
-		Coef0:             0.0,
-		Gamma:             1.0,
-		Tol:               0.001,
-		MaxIters:          10000,
-		supportVectorsIdx: nil,
-		x:                 nil,
-		y:                 nil,
-		b:                 0.0,
-		nSamples:          0,
-		nFeatures:         0,
-		nClasses:          0,
-		alphas:            nil,
-	}
-}
-
-// SetKernelByName устанавливает ядро по его имени.
+		kernelName:          "rbf",
+		Kernel:              &RbfKernel{Gamma: 1.0},
+		kernelCache:         nil,
+		CacheSizeMB:         defaultCacheSizeMB,
+		Shrinking:           true,
+		Formulation:         CSVC,
+		C:                   1.0,
+		Nu:                  0.5,
+		Degree:              3,
+		Coef0:               0.0,
+		Gamma:               1.0,
+		GammaMode:           GammaValue,
+		KernelAlpha:         1.0,
+		Tol:                 0.001,
+		MaxIters:            0,
+		Solver:              SMO,
+		Seed:                1,
+		Probability:         false,
+		ClassWeight:         nil,
+		BalancedClassWeight: false,
+		Logger:              nil,
+		Callback:            nil,
+		supportVectorsIdx:   nil,
+		x:                   nil,
+		y:                   nil,
+		supportVectors:      nil,
+		dualCoef:            nil,
+		nSupport:            nil,
+		b:                   0.0,
+		fittedGamma:         0.0,
+		platt:               nil,
+		nSamples:            0,
+		nFeatures:           0,
+		nClasses:            0,
+		alphas:              nil,
+		boxC:                nil,
+		rnd:                 nil,
+	}
+}
+
+// SetKernelByName устанавливает ядро по его имени из реестра ядер.
+// Гиперпараметры ядра берутся из полей Degree, Coef0, Gamma и KernelAlpha.
+// Имя 'precomputed' включает режим предвычисленного ядра (см. Pairwise).
 // Возвращает ошибку в случае неизвестного ядра.
 func (svc *SVC) SetKernelByName(kernelName string) error {
-	switch KernelName(strings.ToLower(kernelName)) {
-	case Linear:
-		svc.kernelName = Linear
-		svc.Kernel = &LinearKernel{}
-	case Poly:
-		svc.kernelName = Poly
-		svc.Kernel = &PolyKernel{
-			Coef0:  svc.Coef0,
-			Degree: svc.Degree,
-		}
-	case Rbf:
-		svc.kernelName = Rbf
-		svc.Kernel = &RbfKernel{Gamma: svc.Gamma}
-	default:
-		return fmt.Errorf("unknown kernel name")
+	if KernelName(strings.ToLower(kernelName)) == Precomputed {
+		svc.kernelName = Precomputed
+		svc.Kernel = nil
+		return nil
 	}
+	name, kernel, err := kernelByName(kernelName, svc.kernelParams())
+	if err != nil {
+		return err
+	}
+	svc.kernelName = name
+	svc.Kernel = kernel
 	return nil
 }
 
+// SetKernel устанавливает собственный объект ядра, который используется при обучении как есть.
+func (svc *SVC) SetKernel(kernel Kernel) {
+	svc.kernelName = ""
+	svc.Kernel = kernel
+}
+
+// kernelParams возвращает гиперпараметры ядра из полей структуры.
+func (svc *SVC) kernelParams() KernelParams {
+	return KernelParams{
+		Degree: svc.Degree,
+		Coef0:  svc.Coef0,
+		Gamma:  svc.Gamma,
+		Alpha:  svc.KernelAlpha,
+	}
+}
+
 // Fit обучает алгоритм на обучающей выборке.
-// x - матрица признаков.
+// x - матрица признаков или, для предвычисленного ядра, матрица Грама обучающей выборки.
 // y - слайс меток, y = +1 или -1.
 func (svc *SVC) Fit(x [][]float64, y []int) error {
+	return svc.FitContext(context.Background(), x, y)
+}
+
+// FitContext обучает алгоритм на обучающей выборке.
+// Если контекст отменен или истек его срок, то обучение прерывается и возвращается ошибка контекста.
+// x - матрица признаков или, для предвычисленного ядра, матрица Грама обучающей выборки.
+// y - слайс меток, y = +1 или -1.
+func (svc *SVC) FitContext(ctx context.Context, x [][]float64, y []int) error {
+	return svc.fit(ctx, x, y, nil, nil)
+}
+
+// FitWeighted обучает алгоритм на обучающей выборке с весами объектов.
+// Верхняя граница параметра альфа каждого объекта равна C * вес класса * вес объекта.
+// x - матрица признаков.
+// y - слайс меток, y = +1 или -1.
+// sampleWeight - слайс неотрицательных весов объектов, nil означает единичные веса.
+func (svc *SVC) FitWeighted(x [][]float64, y []int, sampleWeight []float64) error {
+	return svc.fit(context.Background(), x, y, sampleWeight, nil)
+}
+
+// fit обучает алгоритм на обучающей выборке с весами объектов.
+// cache - кэш ядра, разделяемый с другими классификаторами, которые обучаются на тех же объектах;
+// nil означает, что классификатор создает собственный кэш.
+func (svc *SVC) fit(ctx context.Context, x [][]float64, y []int, sampleWeight []float64, cache *kernelRowCache) error {
+	// Метод решения проверим до вычисления ядра, чтобы не строить кэш напрасно.
+	if err := svc.validateSolver(); err != nil {
+		return err
+	}
+
 	// Проверим валидность входных данных.
-	if err := svc.validateInput(x, y); err != nil {
+	if err := svc.validateInput(x, y, sampleWeight); err != nil {
 		return fmt.Errorf("invalid input data: %w", err)
 	}
 
+	// Ядро, заданное по имени, построим из текущих значений гиперпараметров.
+	gamma, err := resolveGamma(svc.GammaMode, svc.Gamma, x)
+	if err != nil {
+		return fmt.Errorf("invalid gamma: %w", err)
+	}
+	params := svc.kernelParams()
+	params.Gamma = gamma
+	kernel, err := resolveKernel(svc.kernelName, params, svc.Kernel, len(x[0]))
+	if err != nil {
+		return fmt.Errorf("invalid kernel: %w", err)
+	}
+	svc.Kernel = kernel
+	svc.fittedGamma = gamma
+
 	// Запишем в поля структуры необходимые данные.
 	svc.nSamples = len(x)
 	svc.nFeatures = len(x[0])
 	svc.x = x
 	svc.y = y
 
+	// Вычислим верхние границы параметров альфа. Для nu-SVC параметр C не используется.
+	weights := svc.effectiveWeights(y, sampleWeight)
+	c := svc.C
+	if svc.Formulation == NuSVC {
+		c = 1
+	}
+	svc.boxC = make([]float64, svc.nSamples)
+	for i := range weights {
+		svc.boxC[i] = c * weights[i]
+	}
+
 	// Закэшируем произведения ядра.
-	svc.cacheKernel()
+	svc.cacheKernel(cache)
+	cache = svc.kernelCache
 
 	// Сначала опорными являются все вектора.
 	svc.supportVectorsIdx = make([]int, svc.nSamples)
@@ -142,15 +335,169 @@ func (svc *SVC) Fit(x [][]float64, y []int) error {
 	// Начнем обучение алгоритма.
 	// Для обучения алгоритма необходимо решение задачи QP.
 	// Воспользуемся популярным и эффективным методом решения этой задачи - SMO.
-	log.Println("SVM fitting started...")
-	svc.smo()
+	svc.logger().Info("SVM fitting started", "samples", svc.nSamples, "features", svc.nFeatures)
+	// Формулировка и метод решения уже проверены в validateSolver.
+	switch {
+	case svc.Formulation == NuSVC:
+		err = svc.nuSMO(ctx)
+	case svc.Solver == SimplifiedSMO:
+		err = svc.simplifiedSMO(ctx)
+	default:
+		err = svc.smo(ctx)
+	}
+	if err != nil {
+		// Данные, нужные только во время обучения, освободим и при прерванном обучении.
+		svc.kernelCache = nil
+		svc.rnd = nil
+		return err
+	}
+
+	// Оставим в модели только то, что нужно для предсказания.
+	svc.compact()
+
+	// Обучим сигмоиду Платта для оценки вероятностей.
+	svc.platt = nil
+	if svc.Probability {
+		platt, err := svc.fitPlatt(ctx, x, y, weights, cache)
+		if err != nil {
+			return err
+		}
+		svc.platt = &platt
+	}
+
+	return nil
+}
+
+// validateSolver проверяет, что формулировка задачи и метод решения QP известны и совместимы друг с другом.
+func (svc *SVC) validateSolver() error {
+	switch svc.Solver {
+	case SMO, SimplifiedSMO, "":
+	default:
+		return fmt.Errorf("unknown solver name: %s", svc.Solver)
+	}
+
+	switch svc.Formulation {
+	case CSVC, "":
+	case NuSVC:
+		// Упрощенный метод SMO не поддерживает ограничения nu-SVC.
+		if svc.Solver == SimplifiedSMO {
+			return fmt.Errorf("nu-SVC is supported only by the %s solver, actual: %s", SMO, svc.Solver)
+		}
+	default:
+		return fmt.Errorf("unknown formulation: %s", svc.Formulation)
+	}
 
 	return nil
 }
 
+// paramsCopy возвращает новый необученный классификатор с теми же гиперпараметрами.
+// Веса классов не копируются: вызывающий код передает их в FitWeighted в виде весов объектов.
+func (svc *SVC) paramsCopy() *SVC {
+	res := NewSVC()
+	res.kernelName = svc.kernelName
+	res.Kernel = svc.Kernel
+	res.Formulation = svc.Formulation
+	res.C = svc.C
+	res.Nu = svc.Nu
+	res.Degree = svc.Degree
+	res.Coef0 = svc.Coef0
+	res.Gamma = svc.Gamma
+	res.GammaMode = svc.GammaMode
+	res.KernelAlpha = svc.KernelAlpha
+	res.Tol = svc.Tol
+	res.MaxIters = svc.MaxIters
+	res.Solver = svc.Solver
+	res.Seed = svc.Seed
+	res.Probability = svc.Probability
+	res.CacheSizeMB = svc.CacheSizeMB
+	res.Shrinking = svc.Shrinking
+	res.Logger = svc.Logger
+	res.Callback = svc.Callback
+	return res
+}
+
+// logger возвращает логгер классификатора или, если он не задан, логгер, который ничего не выводит.
+func (svc *SVC) logger() svm.Logger {
+	return loggerOrNop(svc.Logger)
+}
+
+// fittedParamsCopy возвращает paramsCopy, в которой gamma зафиксирована значением,
+// вычисленным при обучении. Используется для вложенных классификаторов, которые обучаются
+// на части выборки, но должны использовать gamma всей обучающей выборки.
+func (svc *SVC) fittedParamsCopy() *SVC {
+	res := svc.paramsCopy()
+	res.Gamma = svc.fittedGamma
+	res.GammaMode = GammaValue
+	return res
+}
+
+// FittedGamma возвращает значение gamma, с которым обучена модель.
+// Для режимов 'scale' и 'auto' это значение, вычисленное по обучающей выборке.
+func (svc *SVC) FittedGamma() float64 {
+	return svc.fittedGamma
+}
+
+// compact сохраняет опорные вектора и их двойственные коэффициенты в плотные массивы
+// и освобождает данные, которые нужны только во время обучения.
+func (svc *SVC) compact() {
+	nSV := len(svc.supportVectorsIdx)
+	svc.supportVectors = make([][]float64, nSV)
+	svc.dualCoef = make([]float64, nSV)
+	svc.nSupport = make([]int, 2)
+	// Для предвычисленного ядра признаков у объектов нет: Predict использует индексы опорных векторов.
+	if svc.Pairwise() {
+		svc.supportVectors = nil
+	}
+	for k, i := range svc.supportVectorsIdx {
+		if !svc.Pairwise() {
+			svc.supportVectors[k] = make([]float64, svc.nFeatures)
+			copy(svc.supportVectors[k], svc.x[i])
+		}
+		svc.dualCoef[k] = svc.alphas[i] * float64(svc.y[i])
+		if svc.y[i] > 0 {
+			svc.nSupport[1]++
+		} else {
+			svc.nSupport[0]++
+		}
+	}
+
+	svc.x = nil
+	svc.y = nil
+	svc.alphas = nil
+	svc.boxC = nil
+	svc.kernelCache = nil
+	svc.rnd = nil
+}
+
+// SupportVectors возвращает опорные вектора обученной модели.
+// Для предвычисленного ядра возвращает nil, индексы опорных векторов возвращает SupportIndices.
+func (svc *SVC) SupportVectors() [][]float64 {
+	return svc.supportVectors
+}
+
+// DualCoef возвращает двойственные коэффициенты опорных векторов - alpha[i] * y[i].
+func (svc *SVC) DualCoef() []float64 {
+	return svc.dualCoef
+}
+
+// Intercept возвращает порог (свободный член) решающей функции.
+func (svc *SVC) Intercept() float64 {
+	return svc.b
+}
+
+// NSupport возвращает количество опорных векторов для каждого класса в порядке [-1, +1].
+func (svc *SVC) NSupport() []int {
+	return svc.nSupport
+}
+
 // Predict классифицирует новые входные данные на основе обученной моодели.
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
 // x - матрица признаков.
 func (svc *SVC) Predict(x [][]float64) []int {
+	if err := svc.ValidatePredictInput(x); err != nil {
+		svc.logger().Error("invalid input data", "error", err)
+		return nil
+	}
 	labels := make([]int, len(x))
 	for i := range x {
 		value := svc.f(x[i])
@@ -163,17 +510,77 @@ func (svc *SVC) Predict(x [][]float64) []int {
 	return labels
 }
 
-// Вычисления f(x).
+// Classes возвращает метки классов в том порядке, в котором они идут
+// в результатах DecisionFunction и PredictProba.
+func (svc *SVC) Classes() []int {
+	return []int{-1, 1}
+}
+
+// DecisionFunction возвращает значения решающей функции для входных данных.
+// Каждая строка результата состоит из одного значения: положительное значение соответствует классу +1.
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
+// x - матрица признаков.
+func (svc *SVC) DecisionFunction(x [][]float64) [][]float64 {
+	if err := svc.ValidatePredictInput(x); err != nil {
+		svc.logger().Error("invalid input data", "error", err)
+		return nil
+	}
+	res := make([][]float64, len(x))
+	for i := range x {
+		res[i] = []float64{svc.f(x[i])}
+	}
+	return res
+}
+
+// PredictProba возвращает вероятности классов -1 и +1 для входных данных.
+// Возвращает nil, если модель обучена без оценки вероятностей (Probability = false).
+// Для предвычисленного ядра возвращает nil, если строки x не соответствуют обучающей выборке (см. ValidatePredictInput).
+// x - матрица признаков.
+func (svc *SVC) PredictProba(x [][]float64) [][]float64 {
+	if svc.platt == nil {
+		return nil
+	}
+	if err := svc.ValidatePredictInput(x); err != nil {
+		svc.logger().Error("invalid input data", "error", err)
+		return nil
+	}
+	res := make([][]float64, len(x))
+	for i := range x {
+		p := svc.platt.predict(svc.f(x[i]))
+		res[i] = []float64{1 - p, p}
+	}
+	return res
+}
+
+// Вычисления f(x) по опорным векторам обученной модели.
+// Для предвычисленного ядра x - строка значений ядра между объектом и объектами обучающей выборки.
 func (svc *SVC) f(x []float64) float64 {
 	result := 0.0
-	for i := range svc.supportVectorsIdx {
-		result += svc.alphas[i] * float64(svc.y[i]) * svc.Kernel.Calculate(svc.x[i], x)
+	if svc.Pairwise() {
+		for k, i := range svc.supportVectorsIdx {
+			result += svc.dualCoef[k] * x[i]
+		}
+		return result + svc.b
+	}
+	for i := range svc.supportVectors {
+		result += svc.dualCoef[i] * svc.Kernel.Calculate(svc.supportVectors[i], x)
+	}
+	return result + svc.b
+}
+
+// Вычисления f(x[i]) для i-ого экземпляра обучающей выборки во время обучения.
+// Матрица ядра симметрична, поэтому значения K(x[j], x[i]) берутся из i-ой строки кэша ядра.
+func (svc *SVC) trainF(i int) float64 {
+	kernelRow := svc.kernelCache.row(i)
+	result := 0.0
+	for _, j := range svc.supportVectorsIdx {
+		result += svc.alphas[j] * float64(svc.y[j]) * kernelRow[j]
 	}
 	return result + svc.b
 }
 
 // validateInput проверяет валидность входных данных для обучения - массива меток и матрицы признаков.
-func (svc *SVC) validateInput(x [][]float64, y []int) error {
+func (svc *SVC) validateInput(x [][]float64, y []int, sampleWeight []float64) error {
 	// Базовый SVM является бинарным - он работает только с 2 классами.
 	// Если в исходной разметке классов больше - надо выдать ошибку.
 	svc.nClasses = vector_operations.CountOfUniques(y)
@@ -191,30 +598,191 @@ func (svc *SVC) validateInput(x [][]float64, y []int) error {
 		return fmt.Errorf("not all data is labeled")
 	}
 
+	// Для предвычисленного ядра проверим матрицу Грама.
+	if svc.Pairwise() {
+		if err := validateGram(x); err != nil {
+			return err
+		}
+	}
+
+	// Проверим веса классов и объектов.
+	if err := svc.validateWeights(y, sampleWeight); err != nil {
+		return err
+	}
+
+	// Для nu-SVC проверим, что задача с заданным nu имеет допустимое решение.
+	if svc.Formulation == NuSVC {
+		if err := svc.validateNu(y, svc.effectiveWeights(y, sampleWeight)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateNu проверяет допустимость параметра nu для распределения меток:
+// 0 < nu <= 2 * min(n+, n-) / n, где n+ и n- - суммарные веса объектов классов.
+// weights - итоговые веса объектов.
+func (svc *SVC) validateNu(y []int, weights []float64) error {
+	if svc.Nu <= 0 || svc.Nu > 1 {
+		return fmt.Errorf("nu must be in (0, 1], actual: %v", svc.Nu)
+	}
+
+	nPositive, nNegative := 0.0, 0.0
+	for i := range y {
+		if y[i] > 0 {
+			nPositive += weights[i]
+		} else {
+			nNegative += weights[i]
+		}
+	}
+	if maxNu := 2 * math.Min(nPositive, nNegative) / float64(len(y)); svc.Nu > maxNu {
+		return fmt.Errorf("specified nu is infeasible: nu must be at most %v for the given labels, actual: %v",
+			maxNu, svc.Nu)
+	}
+
 	return nil
 }
 
-// smo представляет реализацию метода SMO для решения задачи QP.
-func (svc *SVC) smo() {
-	log.Println("Started solving the QP problem by the SMO")
+// smo решает задачу QP полноценным методом SMO с выбором рабочего набора
+// по информации второго порядка. В терминах солвера двойственная задача C-SVC имеет вид
+// min 0.5 * a^T * Q * a - e^T * a, y^T * a = 0, 0 <= a[i] <= C.
+func (svc *SVC) smo(ctx context.Context) error {
+	svc.logger().Debug("started solving the QP problem by the SMO")
+
+	p := make([]float64, svc.nSamples)
+	y := make([]float64, svc.nSamples)
+	c := make([]float64, svc.nSamples)
+	for i := 0; i < svc.nSamples; i++ {
+		p[i] = -1
+		y[i] = float64(svc.y[i])
+		c[i] = svc.boxC[i]
+	}
+
+	q := &svcQMatrix{y: y, kernelCache: svc.kernelCache}
+	solver := newSMOSolver(q, p, y, c, make([]float64, svc.nSamples), svc.Tol, svc.MaxIters)
+	solver.shrinking = svc.Shrinking
+	solver.ctx = ctx
+	solver.callback = svc.progressCallback()
+	res, err := solver.solve()
+	if err != nil {
+		return err
+	}
+	logSMOResult(svc.logger(), res, solver.maxIters)
+
+	svc.alphas = res.alpha
+	svc.b = -res.rho
+
+	// Теперь надо сохранить индексы опорных векторов
+	svc.supportVectorsIdx = make([]int, 0)
+	for i := 0; i < svc.nSamples; i++ {
+		if svc.alphas[i] > 0 {
+			svc.supportVectorsIdx = append(svc.supportVectorsIdx, i)
+		}
+	}
+	return nil
+}
+
+// nuSMO решает двойственную задачу nu-SVC методом SMO:
+// min 0.5 * a^T * Q * a, y^T * a = 0, e^T * a = nu * nSamples, 0 <= a[i] <= w[i],
+// где w[i] - итоговый вес объекта.
+// Решение, деленное на параметр r, совпадает с решением C-SVC при C = 1 / r,
+// поэтому после обучения модель хранится в том же виде, что и для C-SVC.
+func (svc *SVC) nuSMO(ctx context.Context) error {
+	svc.logger().Debug("started solving the nu-SVC QP problem by the SMO")
+
+	p := make([]float64, svc.nSamples)
+	y := make([]float64, svc.nSamples)
+	c := make([]float64, svc.nSamples)
+	alpha := make([]float64, svc.nSamples)
+
+	// Начальное допустимое решение: сумма параметров альфа каждого класса равна nu * nSamples / 2.
+	sumPositive := svc.Nu * float64(svc.nSamples) / 2
+	sumNegative := sumPositive
+	for i := 0; i < svc.nSamples; i++ {
+		y[i] = float64(svc.y[i])
+		c[i] = svc.boxC[i]
+		if svc.y[i] > 0 {
+			alpha[i] = math.Min(c[i], sumPositive)
+			sumPositive -= alpha[i]
+		} else {
+			alpha[i] = math.Min(c[i], sumNegative)
+			sumNegative -= alpha[i]
+		}
+	}
+
+	q := &svcQMatrix{y: y, kernelCache: svc.kernelCache}
+	solver := newNuSMOSolver(q, p, y, c, alpha, svc.Tol, svc.MaxIters)
+	solver.shrinking = svc.Shrinking
+	solver.ctx = ctx
+	solver.callback = svc.progressCallback()
+	res, err := solver.solve()
+	if err != nil {
+		return err
+	}
+	logSMOResult(svc.logger(), res, solver.maxIters)
+
+	// При r = 0 решение вырождено (например, ядро тождественно равно нулю) и не масштабируется
+	// к решению C-SVC, поэтому вместо бесконечных коэффициентов вернем ошибку.
+	if res.r == 0 || math.IsNaN(res.r) {
+		return fmt.Errorf("degenerate nu-SVC problem: margin r = %v", res.r)
+	}
+
+	svc.alphas = res.alpha
+	for i := range svc.alphas {
+		svc.alphas[i] /= res.r
+	}
+	svc.b = -res.rho / res.r
+
+	// Теперь надо сохранить индексы опорных векторов
+	svc.supportVectorsIdx = make([]int, 0)
+	for i := 0; i < svc.nSamples; i++ {
+		if svc.alphas[i] > 0 {
+			svc.supportVectorsIdx = append(svc.supportVectorsIdx, i)
+		}
+	}
+	return nil
+}
+
+// simplifiedSMO представляет реализацию упрощенного метода SMO для решения задачи QP.
+func (svc *SVC) simplifiedSMO(ctx context.Context) error {
+	svc.logger().Debug("started solving the QP problem by the simplified SMO")
 
 	// Изначально alphas - массив размером nSamples из нулей.
 	svc.alphas = make([]float64, svc.nSamples)
+	svc.rnd = rand.New(rand.NewSource(svc.Seed))
+	callback := svc.progressCallback()
+	diag := svc.kernelCache.diagonal()
+	maxIters := svc.MaxIters
+	if maxIters <= 0 {
+		maxIters = defaultSimplifiedSMOMaxIters
+	}
 
 	iterCounter := 0
 	// Главный цикл. Он завершится раньше, если решение сойдется меньше, чем за
 	// максимальное количество итераций.
-	for iterCounter < svc.MaxIters {
-		log.Printf("%d SMO iteration out of %d\n", iterCounter, svc.MaxIters)
+	for iterCounter < maxIters {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		svc.logger().Debug("simplified SMO iteration", "iteration", iterCounter, "max_iters", maxIters)
 
 		// Количество измененных параметров альфа за текущую итерацию
 		numChangedAlphas := 0
+		// Наибольшее нарушение условий ККТ за текущую итерацию
+		violation := 0.0
 		for i := 0; i < svc.nSamples; i++ {
 			// Ошибка для i-ого экземпляра
-			errI := svc.f(svc.x[i]) - float64(svc.y[i])
+			errI := svc.trainF(i) - float64(svc.y[i])
+			if svc.alphas[i] < svc.boxC[i] {
+				violation = math.Max(violation, -float64(svc.y[i])*errI)
+			}
+			if svc.alphas[i] > 0 {
+				violation = math.Max(violation, float64(svc.y[i])*errI)
+			}
 
 			// Проверяем выполнение условий ККТ
-			if (float64(svc.y[i])*errI < -svc.Tol && svc.alphas[i] < svc.C) ||
+			if (float64(svc.y[i])*errI < -svc.Tol && svc.alphas[i] < svc.boxC[i]) ||
 				(float64(svc.y[i])*errI > svc.Tol && svc.alphas[i] > 0) {
 
 				// Выбираем рандомный индекс j != i.
@@ -222,7 +790,7 @@ func (svc *SVC) smo() {
 				j := svc.getJ(i)
 
 				// Ошибка для j-ого экземпляра
-				errJ := svc.f(svc.x[j]) - float64(svc.y[j])
+				errJ := svc.trainF(j) - float64(svc.y[j])
 
 				// Сохраняем старые значения параметров альфа
 				alphaIOld := svc.alphas[i]
@@ -233,10 +801,10 @@ func (svc *SVC) smo() {
 				H := 0.0
 				if svc.y[i] != svc.y[j] {
 					L = math.Max(0, svc.alphas[j]-svc.alphas[i])
-					H = math.Min(svc.C, svc.C+svc.alphas[j]-svc.alphas[i])
+					H = math.Min(svc.boxC[j], svc.boxC[i]+svc.alphas[j]-svc.alphas[i])
 				} else {
-					L = math.Max(0, svc.alphas[j]+svc.alphas[i]-svc.C)
-					H = math.Min(svc.C, svc.alphas[j]+svc.alphas[i])
+					L = math.Max(0, svc.alphas[j]+svc.alphas[i]-svc.boxC[i])
+					H = math.Min(svc.boxC[j], svc.alphas[j]+svc.alphas[i])
 				}
 
 				// Если границы равны, то переход к след. итерации
@@ -245,7 +813,9 @@ func (svc *SVC) smo() {
 				}
 
 				// Считаем параметр
-				eta := 2*svc.kernelCache[i][j] - svc.kernelCache[i][i] - svc.kernelCache[j][j]
+				// Строка i уже загружена в кэш при вычислении errI.
+				kij := svc.kernelCache.row(i)[j]
+				eta := 2*kij - diag[i] - diag[j]
 
 				// Если значение >=0, то переход к след. итерации
 				if eta >= 0 {
@@ -269,14 +839,14 @@ func (svc *SVC) smo() {
 				svc.alphas[i] += float64(svc.y[i]) * float64(svc.y[j]) * (alphaJOld - svc.alphas[j])
 
 				// Находим значение параметра b
-				b1 := svc.b - errI - float64(svc.y[i])*(svc.alphas[i]-alphaIOld)*svc.kernelCache[i][j] -
-					float64(svc.y[j])*(svc.alphas[j]-alphaJOld)*svc.kernelCache[i][j]
-				b2 := svc.b - errJ - float64(svc.y[i])*(svc.alphas[i]-alphaIOld)*svc.kernelCache[i][j] -
-					float64(svc.y[j])*(svc.alphas[j]-alphaJOld)*svc.kernelCache[j][i]
+				b1 := svc.b - errI - float64(svc.y[i])*(svc.alphas[i]-alphaIOld)*kij -
+					float64(svc.y[j])*(svc.alphas[j]-alphaJOld)*kij
+				b2 := svc.b - errJ - float64(svc.y[i])*(svc.alphas[i]-alphaIOld)*kij -
+					float64(svc.y[j])*(svc.alphas[j]-alphaJOld)*kij
 
-				if 0 < svc.alphas[i] && svc.alphas[i] < svc.C {
+				if 0 < svc.alphas[i] && svc.alphas[i] < svc.boxC[i] {
 					svc.b = b1
-				} else if 0 < svc.alphas[j] && svc.alphas[j] < svc.C {
+				} else if 0 < svc.alphas[j] && svc.alphas[j] < svc.boxC[j] {
 					svc.b = b2
 				} else {
 					svc.b = (b1 + b2) / 2
@@ -287,12 +857,20 @@ func (svc *SVC) smo() {
 			}
 		}
 
+		if callback != nil {
+			callback(svc.simplifiedProgress(iterCounter+1, violation))
+		}
+
 		if numChangedAlphas == 0 {
 			break
 		}
 
 		iterCounter++
 	}
+	if iterCounter == maxIters {
+		svc.logger().Warn("simplified SMO reached the maximum number of iterations without convergence, consider increasing MaxIters",
+			"iterations", iterCounter, "max_iters", maxIters)
+	}
 
 	// Теперь надо сохранить индексы опорных векторов
 	svc.supportVectorsIdx = make([]int, 0)
@@ -301,33 +879,64 @@ func (svc *SVC) smo() {
 			svc.supportVectorsIdx = append(svc.supportVectorsIdx, i)
 		}
 	}
+	return nil
+}
+
+// simplifiedProgress возвращает состояние упрощенного метода SMO после прохода iter по выборке
+// с наибольшим нарушением условий ККТ violation.
+// Целевая функция 0.5 * a^T * Q * a - e^T * a вычисляется через значения решающей функции на обучающей выборке.
+func (svc *SVC) simplifiedProgress(iter int, violation float64) Progress {
+	res := Progress{Iter: iter, KKTViolation: violation}
+	for i := 0; i < svc.nSamples; i++ {
+		if svc.alphas[i] == 0 {
+			continue
+		}
+		res.NSupport++
+		res.Objective += 0.5*svc.alphas[i]*float64(svc.y[i])*(svc.trainF(i)-svc.b) - svc.alphas[i]
+	}
+	return res
 }
 
 // Возвращает случайное значение в диапазоне [0, svc.nSamples - 1], не равное i.
 func (svc *SVC) getJ(i int) int {
-	rand.Seed(time.Now().UnixNano())
-	res := rand.Intn(svc.nSamples)
+	res := svc.rnd.Intn(svc.nSamples)
 	for res == i {
-		res = rand.Intn(svc.nSamples)
+		res = svc.rnd.Intn(svc.nSamples)
 	}
 	return res
 }
 
-// Кэшируем значения скалярных произведений ядра,
-// чтобы брать значения из кэша, а не считать на каждой итерации.
-func (svc *SVC) cacheKernel() {
-	// Инициализация матрицы.
-	svc.kernelCache = make([][]float64, svc.nSamples)
-	for i := 0; i < svc.nSamples; i++ {
-		svc.kernelCache[i] = make([]float64, svc.nSamples)
+// svcQMatrix представляет матрицу Q двойственной задачи C-SVC на основе кэша ядра.
+type svcQMatrix struct {
+	y           []float64
+	kernelCache *kernelRowCache
+}
+
+// getQ возвращает i-ую строку матрицы Q.
+func (q *svcQMatrix) getQ(i int) []float64 {
+	kernelRow := q.kernelCache.row(i)
+	row := make([]float64, len(q.y))
+	for j := range row {
+		row[j] = q.y[i] * q.y[j] * kernelRow[j]
 	}
+	return row
+}
 
-	// Заполнение значениями.
-	for i := 0; i < svc.nSamples; i++ {
-		for j := 0; j < svc.nSamples; j++ {
-			svc.kernelCache[i][j] = svc.Kernel.Calculate(svc.x[i], svc.x[j])
-		}
+// getQD возвращает диагональ матрицы Q.
+func (q *svcQMatrix) getQD() []float64 {
+	return q.kernelCache.diagonal()
+}
+
+// Кэшируем значения скалярных произведений ядра,
+// чтобы брать значения из кэша, а не считать на каждой итерации.
+// cache - кэш, разделяемый с другими классификаторами; если он не задан, то создается собственный.
+// Для предвычисленного ядра кэшем служит сама матрица Грама.
+func (svc *SVC) cacheKernel(cache *kernelRowCache) {
+	if cache != nil {
+		svc.kernelCache = cache
+		return
 	}
+	svc.kernelCache = newKernelRowCache(svc.Kernel, svc.Pairwise(), svc.x, svc.CacheSizeMB)
 }
 
 // Clone возвращает копию SVM.
@@ -336,5 +945,29 @@ func (svc *SVC) Clone() (svm.Classifier, error) {
 	if err := copier.Copy(res, svc); err != nil {
 		return nil, err
 	}
+	res.callbackMu = svc.sharedCallbackMu()
 	return res, nil
 }
+
+// sharedCallbackMu возвращает мьютекс для вызовов Callback, общий для классификатора и его копий.
+func (svc *SVC) sharedCallbackMu() *sync.Mutex {
+	if svc.callbackMu == nil {
+		svc.callbackMu = &sync.Mutex{}
+	}
+	return svc.callbackMu
+}
+
+// progressCallback возвращает функцию, которую вызывают солверы после каждой итерации.
+// Для классификатора, у которого есть копии или который сам является копией, Callback вызывается
+// под общим мьютексом.
+func (svc *SVC) progressCallback() Callback {
+	if svc.Callback == nil || svc.callbackMu == nil {
+		return svc.Callback
+	}
+	callback, mu := svc.Callback, svc.callbackMu
+	return func(progress Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		callback(progress)
+	}
+}
@@ -0,0 +1,509 @@
+package svc
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ziyadovea/svm"
+)
+
+// Линейно разделимая выборка из двух классов.
+var (
+	separableX = [][]float64{
+		{1, 2}, {2, 3}, {2, 1}, {3, 2}, {1, 1}, {2, 2},
+		{6, 5}, {7, 8}, {8, 6}, {7, 7}, {9, 8}, {6, 7},
+	}
+	separableY = []int{-1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, 1}
+)
+
+func TestSVC_smo(t *testing.T) {
+	tests := []struct {
+		name       string
+		kernelName string
+		c          float64
+	}{
+		{
+			name:       "Test linear",
+			kernelName: "linear",
+			c:          1,
+		},
+		{
+			name:       "Test rbf",
+			kernelName: "rbf",
+			c:          10,
+		},
+		{
+			name:       "Test poly",
+			kernelName: "poly",
+			c:          0.1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			svc.C = tt.c
+			svc.Degree = 2
+			svc.Coef0 = 1
+			svc.Gamma = 0.1
+			if err := svc.SetKernelByName(tt.kernelName); err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.Fit(separableX, separableY); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			// Восстановим параметры альфа по двойственным коэффициентам опорных векторов.
+			alphas := make([]float64, len(separableX))
+			for k, i := range svc.supportVectorsIdx {
+				alphas[i] = math.Abs(svc.DualCoef()[k])
+			}
+
+			// Проверим ограничения двойственной задачи.
+			sum := 0.0
+			for i, alpha := range alphas {
+				if alpha < 0 || alpha > svc.C {
+					t.Errorf("alpha[%d] = %v is out of [0, %v]", i, alpha, svc.C)
+				}
+				sum += alpha * float64(separableY[i])
+			}
+			if math.Abs(sum) > 1e-9 {
+				t.Errorf("sum(alpha * y) = %v, want 0", sum)
+			}
+
+			// Проверим условия ККТ с точностью Tol.
+			for i := range separableX {
+				f := svc.b
+				for j := range separableX {
+					f += alphas[j] * float64(separableY[j]) * svc.Kernel.Calculate(separableX[j], separableX[i])
+				}
+				yf := float64(separableY[i]) * f
+				switch {
+				case alphas[i] == 0 && yf < 1-svc.Tol:
+					t.Errorf("KKT violated for alpha[%d] = 0: y*f = %v", i, yf)
+				case alphas[i] == svc.C && yf > 1+svc.Tol:
+					t.Errorf("KKT violated for alpha[%d] = C: y*f = %v", i, yf)
+				case 0 < alphas[i] && alphas[i] < svc.C && math.Abs(yf-1) > svc.Tol:
+					t.Errorf("KKT violated for free alpha[%d]: y*f = %v", i, yf)
+				}
+			}
+		})
+	}
+}
+
+func TestSVC_smoDeterministic(t *testing.T) {
+	fit := func() *SVC {
+		svc := NewSVC()
+		if err := svc.Fit(separableX, separableY); err != nil {
+			t.Fatalf("Fit() error = %v", err)
+		}
+		return svc
+	}
+
+	svc1, svc2 := fit(), fit()
+	if !reflect.DeepEqual(svc1.DualCoef(), svc2.DualCoef()) || svc1.Intercept() != svc2.Intercept() {
+		t.Errorf("SMO results differ from run to run")
+	}
+}
+
+func TestSeedReproducible(t *testing.T) {
+	x, y := loadIris(t)
+	xBin, yBin := loadIrisBinary(t)
+
+	tests := []struct {
+		name string
+		fit  func(seed int64) svm.Classifier
+	}{
+		{
+			name: "Test SVC with simplified SMO",
+			fit: func(seed int64) svm.Classifier {
+				svc := NewSVC()
+				svc.Solver = SimplifiedSMO
+				svc.MaxIters = 50
+				svc.Seed = seed
+				if err := svc.Fit(xBin, yBin); err != nil {
+					t.Fatalf("Fit() error = %v", err)
+				}
+				return svc
+			},
+		},
+		{
+			name: "Test MultiSVC with simplified SMO",
+			fit: func(seed int64) svm.Classifier {
+				m := NewMultiSVC()
+				m.Solver = SimplifiedSMO
+				m.MaxIters = 20
+				m.Seed = seed
+				if err := m.Fit(x, y); err != nil {
+					t.Fatalf("Fit() error = %v", err)
+				}
+				return m
+			},
+		},
+		{
+			name: "Test LinearSVC",
+			fit: func(seed int64) svm.Classifier {
+				l := NewLinearSVC()
+				l.Loss = Hinge
+				l.Seed = seed
+				if err := l.Fit(x, y); err != nil {
+					t.Fatalf("Fit() error = %v", err)
+				}
+				return l
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Модели с одинаковым зерном должны совпадать побитово.
+			if got, want := tt.fit(7), tt.fit(7); !reflect.DeepEqual(got, want) {
+				t.Errorf("models fitted with the same seed differ")
+			}
+			if got, want := tt.fit(7), tt.fit(8); reflect.DeepEqual(got, want) {
+				t.Errorf("models fitted with different seeds are the same")
+			}
+		})
+	}
+}
+
+func TestSVC_smoShrinkingAndCache(t *testing.T) {
+	// Пересекающиеся классы: часть переменных окажется на границах, и сжатие их исключит.
+	rnd := rand.New(rand.NewSource(1))
+	x := make([][]float64, 300)
+	y := make([]int, len(x))
+	for i := range x {
+		y[i] = 2*(i%2) - 1
+		x[i] = []float64{float64(y[i]) + rnd.NormFloat64(), float64(y[i]) + rnd.NormFloat64()}
+	}
+
+	tests := []struct {
+		name        string
+		formulation Formulation
+	}{
+		{
+			name:        "Test C-SVC",
+			formulation: CSVC,
+		},
+		{
+			name:        "Test nu-SVC",
+			formulation: NuSVC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fit := func(shrinking bool, cacheSizeMB float64) *SVC {
+				svc := NewSVC()
+				svc.Formulation = tt.formulation
+				svc.Nu = 0.3
+				svc.C = 10
+				svc.Tol = 1e-6
+				svc.Shrinking = shrinking
+				svc.CacheSizeMB = cacheSizeMB
+				if err := svc.Fit(x, y); err != nil {
+					t.Fatalf("Fit() error = %v", err)
+				}
+				return svc
+			}
+
+			// Сжатие и размер кэша не влияют на решение с точностью до Tol.
+			want := fit(false, defaultCacheSizeMB)
+			for _, svc := range []*SVC{fit(true, defaultCacheSizeMB), fit(true, 0), fit(false, 0)} {
+				for i := range x {
+					if got, wantF := svc.f(x[i]), want.f(x[i]); math.Abs(got-wantF) > 1e-3 {
+						t.Fatalf("f(x[%d]) = %v, want %v", i, got, wantF)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSVC_FitUnknownSolver(t *testing.T) {
+	svc := NewSVC()
+	svc.Solver = "unknown"
+	if err := svc.Fit(separableX, separableY); err == nil {
+		t.Errorf("Fit() error = nil, want error")
+	}
+	// Неизвестный метод отклоняется до построения ядра и кэша.
+	if svc.kernelCache != nil || svc.nSamples != 0 {
+		t.Errorf("Fit() with unknown solver must fail before caching the kernel")
+	}
+
+	m := NewMultiSVC()
+	m.Solver = "unknown"
+	if err := m.Fit(separableX, separableY); err == nil {
+		t.Errorf("MultiSVC.Fit() error = nil, want error")
+	}
+}
+
+func TestSVC_nuSMODegenerate(t *testing.T) {
+	// Линейное ядро нулевых векторов тождественно равно нулю, поэтому r = 0.
+	x := [][]float64{{0, 0}, {0, 0}, {0, 0}, {0, 0}}
+	y := []int{-1, -1, 1, 1}
+
+	svc := NewSVC()
+	svc.Formulation = NuSVC
+	svc.Nu = 0.5
+	if err := svc.SetKernelByName("linear"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Fit(x, y); err == nil {
+		t.Errorf("Fit() error = nil, want error for degenerate problem")
+	}
+}
+
+func TestSVC_nuSMO(t *testing.T) {
+	x, y := loadIrisBinary(t)
+	for _, nu := range []float64{0.1, 0.3, 0.6} {
+		svc := NewSVC()
+		svc.Formulation = NuSVC
+		svc.Nu = nu
+		if err := svc.SetKernelByName("linear"); err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.Fit(x, y); err != nil {
+			t.Fatalf("nu = %v: Fit() error = %v", nu, err)
+		}
+
+		// Доля опорных векторов не меньше nu, доля ошибок на отступе не больше nu.
+		if frac := float64(len(svc.SupportVectors())) / float64(len(x)); frac < nu-0.01 {
+			t.Errorf("nu = %v: fraction of support vectors = %v, want at least nu", nu, frac)
+		}
+		nMarginErrors := 0
+		for i := range x {
+			if float64(y[i])*svc.f(x[i]) < 1-svc.Tol {
+				nMarginErrors++
+			}
+		}
+		if frac := float64(nMarginErrors) / float64(len(x)); frac > nu+0.01 {
+			t.Errorf("nu = %v: fraction of margin errors = %v, want at most nu", nu, frac)
+		}
+
+		sum := 0.0
+		for _, coef := range svc.DualCoef() {
+			sum += coef
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Errorf("nu = %v: sum(DualCoef()) = %v, want 0", nu, sum)
+		}
+	}
+}
+
+func TestSVC_FitNuErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		nu     float64
+		solver SolverName
+		y      []int
+	}{
+		{
+			name:   "Test nu = 0",
+			nu:     0,
+			solver: SMO,
+			y:      separableY,
+		},
+		{
+			name:   "Test infeasible nu",
+			nu:     0.5,
+			solver: SMO,
+			y:      []int{-1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		},
+		{
+			name:   "Test simplified SMO",
+			nu:     0.5,
+			solver: SimplifiedSMO,
+			y:      separableY,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			svc.Formulation = NuSVC
+			svc.Nu = tt.nu
+			svc.Solver = tt.solver
+			if err := svc.Fit(separableX, tt.y); err == nil {
+				t.Errorf("Fit() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSVC_Predict(t *testing.T) {
+	for _, solver := range []SolverName{SMO, SimplifiedSMO} {
+		t.Run(string(solver), func(t *testing.T) {
+			svc := NewSVC()
+			svc.Solver = solver
+			svc.Gamma = 0.1
+			if err := svc.Fit(separableX, separableY); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+			if got := svc.Predict(separableX); !reflect.DeepEqual(got, separableY) {
+				t.Errorf("Predict() = %v, want %v", got, separableY)
+			}
+		})
+	}
+}
+
+func TestSVC_compact(t *testing.T) {
+	svc := NewSVC()
+	svc.Gamma = 0.1
+	if err := svc.Fit(separableX, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	if svc.x != nil || svc.y != nil || svc.alphas != nil || svc.kernelCache != nil {
+		t.Errorf("training data is kept alive after Fit()")
+	}
+
+	sv, coef, nSupport := svc.SupportVectors(), svc.DualCoef(), svc.NSupport()
+	if len(sv) == 0 || len(sv) != len(coef) {
+		t.Fatalf("len(SupportVectors()) = %d, len(DualCoef()) = %d", len(sv), len(coef))
+	}
+	if len(nSupport) != 2 || nSupport[0]+nSupport[1] != len(sv) {
+		t.Errorf("NSupport() = %v, want counts summing to %d", nSupport, len(sv))
+	}
+
+	sum := 0.0
+	for k, i := range svc.supportVectorsIdx {
+		if !reflect.DeepEqual(sv[k], separableX[i]) {
+			t.Errorf("SupportVectors()[%d] = %v, want %v", k, sv[k], separableX[i])
+		}
+		if coef[k]*float64(separableY[i]) <= 0 {
+			t.Errorf("DualCoef()[%d] = %v has a wrong sign", k, coef[k])
+		}
+		sum += coef[k]
+	}
+	if math.Abs(sum) > 1e-9 {
+		t.Errorf("sum(DualCoef()) = %v, want 0", sum)
+	}
+
+	// Решающая функция должна вычисляться только по опорным векторам.
+	for _, x := range separableX {
+		want := svc.Intercept()
+		for k := range sv {
+			want += coef[k] * svc.Kernel.Calculate(sv[k], x)
+		}
+		if got := svc.f(x); math.Abs(got-want) > 1e-12 {
+			t.Errorf("f() = %v, want %v", got, want)
+		}
+	}
+}
+
+// loadIrisBinary возвращает объекты двух пересекающихся классов набора iris (versicolor и virginica)
+// с метками -1 и +1.
+func loadIrisBinary(t *testing.T) ([][]float64, []int) {
+	t.Helper()
+
+	x, y := loadIris(t)
+	xBin := make([][]float64, 0, len(x))
+	yBin := make([]int, 0, len(y))
+	for i := range y {
+		switch y[i] {
+		case 1:
+			xBin = append(xBin, x[i])
+			yBin = append(yBin, -1)
+		case 2:
+			xBin = append(xBin, x[i])
+			yBin = append(yBin, 1)
+		}
+	}
+	return xBin, yBin
+}
+
+func TestSVC_DecisionFunction(t *testing.T) {
+	svc := NewSVC()
+	svc.Gamma = 0.1
+	if err := svc.Fit(separableX, separableY); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	pred := svc.Predict(separableX)
+	for i, row := range svc.DecisionFunction(separableX) {
+		if len(row) != 1 {
+			t.Fatalf("len(DecisionFunction()[%d]) = %d, want 1", i, len(row))
+		}
+		if (row[0] >= 0) != (pred[i] == 1) {
+			t.Errorf("DecisionFunction()[%d] = %v is inconsistent with Predict() = %d", i, row[0], pred[i])
+		}
+	}
+}
+
+func TestSVC_PredictProba(t *testing.T) {
+	x, y := loadIrisBinary(t)
+
+	svc := NewSVC()
+	if got := svc.PredictProba(x); got != nil {
+		t.Errorf("PredictProba() of a model without probability estimates = %v, want nil", got)
+	}
+
+	svc.Probability = true
+	if err := svc.Fit(x, y); err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	// Вероятность положительного класса должна монотонно расти вместе с решающей функцией.
+	if svc.platt.A >= 0 {
+		t.Errorf("sigmoid slope A = %v, want negative", svc.platt.A)
+	}
+
+	dec := svc.DecisionFunction(x)
+	proba := svc.PredictProba(x)
+	for i := range proba {
+		if len(proba[i]) != 2 {
+			t.Fatalf("len(PredictProba()[%d]) = %d, want 2", i, len(proba[i]))
+		}
+		if proba[i][0] < 0 || proba[i][1] < 0 || math.Abs(proba[i][0]+proba[i][1]-1) > 1e-12 {
+			t.Errorf("PredictProba()[%d] = %v is not a probability distribution", i, proba[i])
+		}
+		for j := range proba {
+			if dec[i][0] > dec[j][0] && proba[i][1] < proba[j][1] {
+				t.Fatalf("PredictProba() is not monotonic in DecisionFunction()")
+			}
+		}
+	}
+}
+
+func Test_trainPlattSigmoid(t *testing.T) {
+	decValues := []float64{-3, -2, -1.5, -1, -0.5, 0.2, -0.1, 0.5, 1, 1.5, 2, 3}
+	y := []int{-1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, 1}
+
+	sigmoid := trainPlattSigmoid(decValues, y)
+	if sigmoid.A >= 0 {
+		t.Errorf("A = %v, want negative", sigmoid.A)
+	}
+	if p := sigmoid.predict(0); math.Abs(p-0.5) > 0.1 {
+		t.Errorf("predict(0) = %v, want close to 0.5 for symmetric data", p)
+	}
+	if p := sigmoid.predict(3); p < 0.9 {
+		t.Errorf("predict(3) = %v, want > 0.9", p)
+	}
+	if p := sigmoid.predict(-3); p > 0.1 {
+		t.Errorf("predict(-3) = %v, want < 0.1", p)
+	}
+}
+
+// BenchmarkSVC_FitSimplifiedSMO измеряет обучение упрощенным методом SMO на пересекающихся классах,
+// когда значения решающей функции пересчитываются на каждом проходе по выборке.
+func BenchmarkSVC_FitSimplifiedSMO(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([][]float64, 300)
+	y := make([]int, len(x))
+	for i := range x {
+		y[i] = 1
+		shift := 1.0
+		if i%2 == 0 {
+			y[i], shift = -1, -1.0
+		}
+		x[i] = []float64{rnd.NormFloat64() + shift, rnd.NormFloat64() + shift, rnd.NormFloat64()}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		svc := NewSVC()
+		svc.Solver = SimplifiedSMO
+		if err := svc.Fit(x, y); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
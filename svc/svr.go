@@ -0,0 +1,346 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jinzhu/copier"
+	"github.com/ziyadovea/svm"
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// Проверим, что структура SVR удовлетворяет интерфейсу Regressor.
+var _ svm.Regressor = (*SVR)(nil)
+
+// SVR (англ. Support Vector Regression) - структура для представления
+// регрессора методом опорных векторов с epsilon-нечувствительной функцией потерь.
+type SVR struct {
+	// Для нелинейной зависимости используется kernel trick.
+	// Название ядра.
+	kernelName KernelName
+
+	// Ядро.
+	// Ядро, заданное по имени, строится заново из полей Degree, Coef0, Gamma и KernelAlpha при каждом вызове Fit.
+	// Собственный объект ядра задается методом SetKernel.
+	Kernel Kernel
+
+	// Кэш строк матрицы ядра.
+	// Используется только во время обучения.
+	kernelCache *kernelRowCache
+
+	// Объем памяти под кэш строк матрицы ядра в мегабайтах.
+	// Если строки не помещаются в кэш, то давно не использованные строки вытесняются и при необходимости
+	// вычисляются заново.
+	CacheSizeMB float64
+
+	// Использовать ли эвристику сжатия (shrinking) метода SMO: переменные, которые находятся на границах
+	// и, скорее всего, там и останутся, временно исключаются из оптимизации.
+	Shrinking bool
+
+	// Параметр регуляризации.
+	C float64
+
+	// Ширина epsilon-трубки: отклонения предсказания от целевого значения,
+	// не превышающие Epsilon, не штрафуются.
+	Epsilon float64
+
+	// Степень многочлена для полиномиального ядра.
+	Degree int
+
+	// Свободный член для полиномиального ядра.
+	Coef0 float64
+
+	// Масштаб для ядер 'rbf', 'sigmoid', 'laplacian', 'chi2' и 'rational_quadratic'.
+	// Используется, если GammaMode равен GammaValue.
+	Gamma float64
+
+	// Способ выбора gamma: значение из поля Gamma или вычисление по обучающей выборке
+	// ('scale' или 'auto') при вызове Fit.
+	GammaMode GammaMode
+
+	// Параметр alpha для ядра 'rational_quadratic'.
+	KernelAlpha float64
+
+	// Точность.
+	Tol float64
+
+	// Максимальное количество итераций SMO - оптимизаций одной пары параметров альфа.
+	// Значение 0 (по умолчанию) означает max(10000000, 200 * nSamples), как в LIBSVM.
+	MaxIters int
+
+	// Логгер для сообщений о ходе обучения. Если равен nil, то сообщения не выводятся.
+	Logger svm.Logger
+
+	// Функция, которую солвер вызывает после каждой итерации. Если равна nil, то не вызывается.
+	Callback Callback
+
+	// Опорные вектора обученной модели.
+	supportVectors [][]float64
+
+	// Двойственные коэффициенты опорных векторов: alpha[i] - alpha*[i].
+	dualCoef []float64
+
+	// Свободный член регрессии.
+	b float64
+
+	// Значение gamma, с которым обучена модель.
+	fittedGamma float64
+
+	// Число образцов обучающей выборки
+	nSamples int
+	// Число характеристик обучающей выборки
+	nFeatures int
+}
+
+// NewSVR возвращает экземпляр SVR с параметрами по умолчанию.
+func NewSVR() *SVR {
+	return &SVR{
+		kernelName:     "rbf",
+		Kernel:         &RbfKernel{Gamma: 1.0},
+		kernelCache:    nil,
+		CacheSizeMB:    defaultCacheSizeMB,
+		Shrinking:      true,
+		C:              1.0,
+		Epsilon:        0.1,
+		Degree:         3,
+		Coef0:          0.0,
+		Gamma:          1.0,
+		GammaMode:      GammaValue,
+		KernelAlpha:    1.0,
+		Tol:            0.001,
+		MaxIters:       0,
+		Logger:         nil,
+		Callback:       nil,
+		supportVectors: nil,
+		dualCoef:       nil,
+		b:              0.0,
+		fittedGamma:    0.0,
+		nSamples:       0,
+		nFeatures:      0,
+	}
+}
+
+// SetKernelByName устанавливает ядро по его имени из реестра ядер.
+// Гиперпараметры ядра берутся из полей Degree, Coef0, Gamma и KernelAlpha.
+// Возвращает ошибку в случае неизвестного ядра.
+func (svr *SVR) SetKernelByName(kernelName string) error {
+	name, kernel, err := kernelByName(kernelName, svr.kernelParams())
+	if err != nil {
+		return err
+	}
+	svr.kernelName = name
+	svr.Kernel = kernel
+	return nil
+}
+
+// SetKernel устанавливает собственный объект ядра, который используется при обучении как есть.
+func (svr *SVR) SetKernel(kernel Kernel) {
+	svr.kernelName = ""
+	svr.Kernel = kernel
+}
+
+// kernelParams возвращает гиперпараметры ядра из полей структуры.
+func (svr *SVR) kernelParams() KernelParams {
+	return KernelParams{
+		Degree: svr.Degree,
+		Coef0:  svr.Coef0,
+		Gamma:  svr.Gamma,
+		Alpha:  svr.KernelAlpha,
+	}
+}
+
+// Fit обучает алгоритм на обучающей выборке.
+// x - матрица признаков.
+// y - слайс значений целевой переменной.
+func (svr *SVR) Fit(x [][]float64, y []float64) error {
+	return svr.FitContext(context.Background(), x, y)
+}
+
+// FitContext обучает алгоритм на обучающей выборке.
+// Если контекст отменен или истек его срок, то обучение прерывается и возвращается ошибка контекста.
+// x - матрица признаков.
+// y - слайс значений целевой переменной.
+func (svr *SVR) FitContext(ctx context.Context, x [][]float64, y []float64) error {
+	// Проверим валидность входных данных.
+	if err := svr.validateInput(x, y); err != nil {
+		return fmt.Errorf("invalid input data: %w", err)
+	}
+
+	// Ядро, заданное по имени, построим из текущих значений гиперпараметров.
+	gamma, err := resolveGamma(svr.GammaMode, svr.Gamma, x)
+	if err != nil {
+		return fmt.Errorf("invalid gamma: %w", err)
+	}
+	params := svr.kernelParams()
+	params.Gamma = gamma
+	kernel, err := resolveKernel(svr.kernelName, params, svr.Kernel, len(x[0]))
+	if err != nil {
+		return fmt.Errorf("invalid kernel: %w", err)
+	}
+	svr.Kernel = kernel
+	svr.fittedGamma = gamma
+
+	svr.nSamples = len(x)
+	svr.nFeatures = len(x[0])
+
+	// Закэшируем произведения ядра.
+	svr.kernelCache = newKernelRowCache(svr.Kernel, false, x, svr.CacheSizeMB)
+
+	loggerOrNop(svr.Logger).Info("SVR fitting started", "samples", svr.nSamples, "features", svr.nFeatures)
+	err = svr.smo(ctx, x, y)
+
+	// Кэш ядра нужен только во время обучения.
+	svr.kernelCache = nil
+
+	return err
+}
+
+// validateInput проверяет валидность параметров C и Epsilon и входных данных для обучения -
+// слайса целевых значений и матрицы признаков.
+func (svr *SVR) validateInput(x [][]float64, y []float64) error {
+	if svr.C <= 0 {
+		return fmt.Errorf("C must be positive, actual: %v", svr.C)
+	}
+	if svr.Epsilon < 0 {
+		return fmt.Errorf("epsilon must be non-negative, actual: %v", svr.Epsilon)
+	}
+
+	if len(x) == 0 {
+		return fmt.Errorf("training set is empty")
+	}
+
+	// Проверим, что матрица признаков является прямоугольной.
+	if !vector_operations.IsMatrixRectangular(x) {
+		return fmt.Errorf("feature matrix must be rectangular")
+	}
+
+	// Проверим, что для всех данных заданы целевые значения
+	if len(x) != len(y) {
+		return fmt.Errorf("not all data is labeled")
+	}
+
+	return nil
+}
+
+// smo решает двойственную задачу epsilon-SVR методом SMO.
+// Задача записывается для 2 * nSamples переменных (alpha, alpha*) в виде
+// min 0.5 * a^T * Q * a + p^T * a, z^T * a = 0, 0 <= a[i] <= C,
+// где p = (eps - y, eps + y), z = (+1, -1), Q[i][j] = z[i] * z[j] * K(x[i mod n], x[j mod n]).
+func (svr *SVR) smo(ctx context.Context, x [][]float64, y []float64) error {
+	l := svr.nSamples
+	p := make([]float64, 2*l)
+	z := make([]float64, 2*l)
+	c := make([]float64, 2*l)
+	for i := 0; i < l; i++ {
+		p[i] = svr.Epsilon - y[i]
+		z[i] = +1
+		p[i+l] = svr.Epsilon + y[i]
+		z[i+l] = -1
+		c[i] = svr.C
+		c[i+l] = svr.C
+	}
+
+	q := &svrQMatrix{z: z, kernelCache: svr.kernelCache}
+	solver := newSMOSolver(q, p, z, c, make([]float64, 2*l), svr.Tol, svr.MaxIters)
+	solver.shrinking = svr.Shrinking
+	solver.ctx = ctx
+	solver.callback = svr.Callback
+	res, err := solver.solve()
+	if err != nil {
+		return err
+	}
+	logSMOResult(loggerOrNop(svr.Logger), res, solver.maxIters)
+
+	// Оставим только опорные вектора - объекты с ненулевым коэффициентом alpha[i] - alpha*[i].
+	svr.supportVectors = make([][]float64, 0)
+	svr.dualCoef = make([]float64, 0)
+	for i := 0; i < l; i++ {
+		coef := res.alpha[i] - res.alpha[i+l]
+		if coef == 0 {
+			continue
+		}
+		sv := make([]float64, svr.nFeatures)
+		copy(sv, x[i])
+		svr.supportVectors = append(svr.supportVectors, sv)
+		svr.dualCoef = append(svr.dualCoef, coef)
+	}
+	svr.b = -res.rho
+	return nil
+}
+
+// Predict предсказывает значения целевой переменной для новых входных данных на основе обученной модели.
+// x - матрица признаков.
+func (svr *SVR) Predict(x [][]float64) []float64 {
+	res := make([]float64, len(x))
+	for i := range x {
+		res[i] = svr.f(x[i])
+	}
+	return res
+}
+
+// Вычисления f(x) по опорным векторам обученной модели.
+func (svr *SVR) f(x []float64) float64 {
+	result := 0.0
+	for i := range svr.supportVectors {
+		result += svr.dualCoef[i] * svr.Kernel.Calculate(svr.supportVectors[i], x)
+	}
+	return result + svr.b
+}
+
+// SupportVectors возвращает опорные вектора обученной модели.
+func (svr *SVR) SupportVectors() [][]float64 {
+	return svr.supportVectors
+}
+
+// DualCoef возвращает двойственные коэффициенты опорных векторов - alpha[i] - alpha*[i].
+func (svr *SVR) DualCoef() []float64 {
+	return svr.dualCoef
+}
+
+// Intercept возвращает свободный член регрессии.
+func (svr *SVR) Intercept() float64 {
+	return svr.b
+}
+
+// FittedGamma возвращает значение gamma, с которым обучена модель.
+// Для режимов 'scale' и 'auto' это значение, вычисленное по обучающей выборке.
+func (svr *SVR) FittedGamma() float64 {
+	return svr.fittedGamma
+}
+
+// svrQMatrix представляет матрицу Q двойственной задачи epsilon-SVR на основе кэша ядра.
+type svrQMatrix struct {
+	z           []float64
+	kernelCache *kernelRowCache
+}
+
+// getQ возвращает i-ую строку матрицы Q.
+func (q *svrQMatrix) getQ(i int) []float64 {
+	l := len(q.z) / 2
+	kernelRow := q.kernelCache.row(i % l)
+	row := make([]float64, len(q.z))
+	for j := range row {
+		row[j] = q.z[i] * q.z[j] * kernelRow[j%l]
+	}
+	return row
+}
+
+// getQD возвращает диагональ матрицы Q.
+func (q *svrQMatrix) getQD() []float64 {
+	l := len(q.z) / 2
+	diag := q.kernelCache.diagonal()
+	qd := make([]float64, len(q.z))
+	for i := range qd {
+		qd[i] = diag[i%l]
+	}
+	return qd
+}
+
+// Clone возвращает копию SVR.
+func (svr *SVR) Clone() (svm.Regressor, error) {
+	res := &SVR{}
+	if err := copier.Copy(res, svr); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
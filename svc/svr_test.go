@@ -0,0 +1,142 @@
+package svc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSVR_Fit(t *testing.T) {
+	tests := []struct {
+		name       string
+		kernelName string
+		gamma      float64
+		c          float64
+		epsilon    float64
+		f          func(x float64) float64
+		maxErr     float64
+	}{
+		{
+			name:       "Test linear",
+			kernelName: "linear",
+			c:          100,
+			epsilon:    0.1,
+			f:          func(x float64) float64 { return 2*x + 1 },
+			maxErr:     0.1,
+		},
+		{
+			name:       "Test rbf",
+			kernelName: "rbf",
+			gamma:      0.5,
+			c:          100,
+			epsilon:    0.05,
+			f:          math.Sin,
+			maxErr:     0.1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := make([][]float64, 0)
+			y := make([]float64, 0)
+			for v := -3.0; v <= 3; v += 0.25 {
+				x = append(x, []float64{v})
+				y = append(y, tt.f(v))
+			}
+
+			svr := NewSVR()
+			svr.Gamma = tt.gamma
+			svr.C = tt.c
+			svr.Epsilon = tt.epsilon
+			if err := svr.SetKernelByName(tt.kernelName); err != nil {
+				t.Fatal(err)
+			}
+			if err := svr.Fit(x, y); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			pred := svr.Predict(x)
+			for i := range pred {
+				if math.Abs(pred[i]-y[i]) > tt.maxErr {
+					t.Errorf("Predict()[%d] = %v, want %v", i, pred[i], y[i])
+				}
+			}
+
+			// Объекты строго внутри epsilon-трубки не должны быть опорными.
+			if len(svr.SupportVectors()) == len(x) {
+				t.Errorf("all training objects are support vectors")
+			}
+			sum := 0.0
+			for _, coef := range svr.DualCoef() {
+				if math.Abs(coef) > svr.C+1e-12 {
+					t.Errorf("dual coefficient %v is out of [-C, C]", coef)
+				}
+				sum += coef
+			}
+			if math.Abs(sum) > 1e-9 {
+				t.Errorf("sum(DualCoef()) = %v, want 0", sum)
+			}
+		})
+	}
+}
+
+func TestSVR_FitErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       float64
+		epsilon float64
+		x       [][]float64
+		y       []float64
+	}{
+		{
+			name:    "Test C = 0",
+			c:       0,
+			epsilon: 0.1,
+			x:       [][]float64{{1}, {2}},
+			y:       []float64{1, 2},
+		},
+		{
+			name:    "Test negative C",
+			c:       -1,
+			epsilon: 0.1,
+			x:       [][]float64{{1}, {2}},
+			y:       []float64{1, 2},
+		},
+		{
+			name:    "Test negative epsilon",
+			c:       1,
+			epsilon: -0.1,
+			x:       [][]float64{{1}, {2}},
+			y:       []float64{1, 2},
+		},
+		{
+			name:    "Test empty",
+			c:       1,
+			epsilon: 0.1,
+			x:       [][]float64{},
+			y:       []float64{},
+		},
+		{
+			name:    "Test not rectangular",
+			c:       1,
+			epsilon: 0.1,
+			x:       [][]float64{{1, 2}, {3}},
+			y:       []float64{1, 2},
+		},
+		{
+			name:    "Test not labeled",
+			c:       1,
+			epsilon: 0.1,
+			x:       [][]float64{{1}, {2}, {3}},
+			y:       []float64{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr := NewSVR()
+			svr.C = tt.c
+			svr.Epsilon = tt.epsilon
+			if err := svr.Fit(tt.x, tt.y); err == nil {
+				t.Errorf("Fit() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/ziyadovea/svm/pkg/vector_operations"
+)
+
+// balancedClassWeight возвращает веса классов, обратно пропорциональные их размерам:
+// w[c] = n / (k * n[c]), где n - размер выборки, k - число классов, n[c] - размер класса c.
+func balancedClassWeight(y []int) map[int]float64 {
+	counter := vector_operations.Counter(y)
+	res := make(map[int]float64, len(counter))
+	for label, count := range counter {
+		res[label] = float64(len(y)) / (float64(len(counter)) * float64(count))
+	}
+	return res
+}
+
+// classWeights возвращает веса классов для обучения на слайсе меток y.
+// Для классов, отсутствующих в результате, вес равен 1.
+func (svc *SVC) classWeights(y []int) map[int]float64 {
+	if svc.BalancedClassWeight {
+		return balancedClassWeight(y)
+	}
+	return svc.ClassWeight
+}
+
+// effectiveWeights возвращает итоговые веса объектов: вес класса объекта, умноженный на вес объекта.
+// sampleWeight - веса объектов, nil означает единичные веса.
+func (svc *SVC) effectiveWeights(y []int, sampleWeight []float64) []float64 {
+	classWeight := svc.classWeights(y)
+	res := make([]float64, len(y))
+	for i := range y {
+		res[i] = 1
+		if w, ok := classWeight[y[i]]; ok {
+			res[i] = w
+		}
+		if sampleWeight != nil {
+			res[i] *= sampleWeight[i]
+		}
+	}
+	return res
+}
+
+// validateWeights проверяет веса классов и веса объектов.
+// Суммарный вес объектов каждого класса должен быть положительным: иначе класс не влияет на решение,
+// а порог решающей функции не определен.
+// y - слайс меток обучающей выборки.
+func (svc *SVC) validateWeights(y []int, sampleWeight []float64) error {
+	for label, w := range svc.ClassWeight {
+		if w <= 0 {
+			return fmt.Errorf("class weight must be positive, actual: %v for label %d", w, label)
+		}
+	}
+
+	if sampleWeight == nil {
+		return nil
+	}
+	if len(sampleWeight) != len(y) {
+		return fmt.Errorf("number of sample weights must be equal to the number of samples: expected %d, actual: %d",
+			len(y), len(sampleWeight))
+	}
+	classTotal := make(map[int]float64)
+	for i, w := range sampleWeight {
+		if w < 0 {
+			return fmt.Errorf("sample weight must be non-negative, actual: %v for sample %d", w, i)
+		}
+		classTotal[y[i]] += w
+	}
+	for _, label := range vector_operations.GetUniques(y) {
+		if classTotal[label] <= 0 {
+			return fmt.Errorf("total sample weight of class %d must be positive, actual: %v", label, classTotal[label])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package svc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_balancedClassWeight(t *testing.T) {
+	tests := []struct {
+		name string
+		y    []int
+		want map[int]float64
+	}{
+		{
+			name: "Test balanced classes",
+			y:    []int{-1, 1, -1, 1},
+			want: map[int]float64{-1: 1, 1: 1},
+		},
+		{
+			name: "Test imbalanced classes",
+			y:    []int{0, 0, 0, 1, 2, 2},
+			want: map[int]float64{0: 6.0 / 9, 1: 2, 2: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balancedClassWeight(tt.y); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("balancedClassWeight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSVC_FitWeighted(t *testing.T) {
+	fit := func(setup func(svc *SVC), sampleWeight []float64) *SVC {
+		t.Helper()
+		svc := NewSVC()
+		svc.Gamma = 0.1
+		setup(svc)
+		if err := svc.FitWeighted(separableX, separableY, sampleWeight); err != nil {
+			t.Fatalf("FitWeighted() error = %v", err)
+		}
+		return svc
+	}
+	equal := func(a, b *SVC) bool {
+		if len(a.DualCoef()) != len(b.DualCoef()) || math.Abs(a.Intercept()-b.Intercept()) > 1e-9 {
+			return false
+		}
+		for k := range a.DualCoef() {
+			if math.Abs(a.DualCoef()[k]-b.DualCoef()[k]) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Единичные веса объектов эквивалентны обучению без весов.
+	plain := fit(func(svc *SVC) {}, nil)
+	ones := make([]float64, len(separableY))
+	twos := make([]float64, len(separableY))
+	classWeighted := make([]float64, len(separableY))
+	for i := range separableY {
+		ones[i] = 1
+		twos[i] = 2
+		classWeighted[i] = 1
+		if separableY[i] > 0 {
+			classWeighted[i] = 3
+		}
+	}
+	if got := fit(func(svc *SVC) {}, ones); !equal(got, plain) {
+		t.Errorf("unit sample weights change the model")
+	}
+
+	// Умножение всех весов на 2 эквивалентно умножению C на 2.
+	doubledC := fit(func(svc *SVC) { svc.C = 2 }, nil)
+	if got := fit(func(svc *SVC) {}, twos); !equal(got, doubledC) {
+		t.Errorf("sample weights 2 are not equivalent to C = 2")
+	}
+
+	// Веса классов эквивалентны соответствующим весам объектов.
+	byClass := fit(func(svc *SVC) { svc.ClassWeight = map[int]float64{1: 3} }, nil)
+	if got := fit(func(svc *SVC) {}, classWeighted); !equal(got, byClass) {
+		t.Errorf("class weights are not equivalent to sample weights")
+	}
+}
+
+func TestSVC_FitWeightedErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		classWeight  map[int]float64
+		sampleWeight []float64
+	}{
+		{
+			name:         "Test wrong number of sample weights",
+			sampleWeight: []float64{1, 2, 3},
+		},
+		{
+			name:         "Test negative sample weight",
+			sampleWeight: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1},
+		},
+		{
+			name:         "Test zero sample weights",
+			sampleWeight: make([]float64, 12),
+		},
+		{
+			name:         "Test zero sample weights of one class",
+			sampleWeight: []float64{0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1},
+		},
+		{
+			name:        "Test non-positive class weight",
+			classWeight: map[int]float64{-1: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSVC()
+			svc.ClassWeight = tt.classWeight
+			if err := svc.FitWeighted(separableX, separableY, tt.sampleWeight); err == nil {
+				t.Errorf("FitWeighted() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMultiSVC_FitWeightedZeroClassWeight(t *testing.T) {
+	x, y := loadIris(t)
+	// Все объекты одного класса имеют нулевой вес.
+	sampleWeight := make([]float64, len(y))
+	for i := range y {
+		if y[i] != y[0] {
+			sampleWeight[i] = 1
+		}
+	}
+	if err := NewMultiSVC().FitWeighted(x, y, sampleWeight); err == nil {
+		t.Errorf("FitWeighted() error = nil, want error")
+	}
+}
+
+func TestMultiSVC_FitBalancedClassWeight(t *testing.T) {
+	x, y := loadIris(t)
+	// Оставим только часть объектов первого класса, чтобы классы стали несбалансированными.
+	xImb := make([][]float64, 0, len(x))
+	yImb := make([]int, 0, len(y))
+	for i := range y {
+		if y[i] != 0 || i%5 == 0 {
+			xImb = append(xImb, x[i])
+			yImb = append(yImb, y[i])
+		}
+	}
+
+	for _, strategy := range []Strategy{OvR, OvO} {
+		t.Run(string(strategy), func(t *testing.T) {
+			balanced := NewMultiSVC()
+			balanced.Strategy = strategy
+			balanced.BalancedClassWeight = true
+			if err := balanced.Fit(xImb, yImb); err != nil {
+				t.Fatalf("Fit() error = %v", err)
+			}
+
+			// Веса классов исходной разметки должны быть переданы бинарным классификаторам как веса объектов.
+			classWeight := balancedClassWeight(yImb)
+			sampleWeight := make([]float64, len(yImb))
+			for i := range yImb {
+				sampleWeight[i] = classWeight[yImb[i]]
+			}
+			weighted := NewMultiSVC()
+			weighted.Strategy = strategy
+			if err := weighted.FitWeighted(xImb, yImb, sampleWeight); err != nil {
+				t.Fatalf("FitWeighted() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(balanced.DecisionFunction(x), weighted.DecisionFunction(x)) {
+				t.Errorf("balanced class weights differ from the equivalent sample weights")
+			}
+		})
+	}
+}